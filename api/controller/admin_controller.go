@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"lamda_backend/internal/job_dispatcher"
+	"lamda_backend/pkg/logger"
+	"lamda_backend/pkg/nats"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminController handles HTTP requests for operator tasks
+type AdminController struct {
+	natsClient *nats.NATSClient
+	logger     *logger.Logger
+}
+
+// NewAdminController creates a new admin controller
+func NewAdminController(natsClient *nats.NATSClient, logger *logger.Logger) *AdminController {
+	return &AdminController{
+		natsClient: natsClient,
+		logger:     logger.WithService("admin-controller"),
+	}
+}
+
+// ListDeadLetters handles GET /api/v1/admin/dead-letters
+func (ac *AdminController) ListDeadLetters(c *fiber.Ctx) error {
+	request := job_dispatcher.DeadLetterRequest{
+		Kind:   job_dispatcher.DeadLetterKind(c.Query("kind")),
+		Status: job_dispatcher.DeadLetterStatus(c.Query("status", string(job_dispatcher.DeadLetterStatusPending))),
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil {
+		request.Limit = limit
+	}
+	if offset, err := strconv.Atoi(c.Query("offset")); err == nil {
+		request.Offset = offset
+	}
+
+	// status=all lists entries in every status
+	if request.Status == "all" {
+		request.Status = ""
+	}
+
+	return ac.deadLetterRequest(c, "deadletters.list", request)
+}
+
+// GetDeadLetter handles GET /api/v1/admin/dead-letters/:id
+func (ac *AdminController) GetDeadLetter(c *fiber.Ctx) error {
+	request := job_dispatcher.DeadLetterRequest{ID: c.Params("id")}
+	return ac.deadLetterRequest(c, "deadletters.get", request)
+}
+
+// UpdateDeadLetter handles PUT /api/v1/admin/dead-letters/:id with the corrected payload as its body
+func (ac *AdminController) UpdateDeadLetter(c *fiber.Ctx) error {
+	if !json.Valid(c.Body()) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Payload must be valid JSON",
+		})
+	}
+
+	request := job_dispatcher.DeadLetterRequest{ID: c.Params("id"), Payload: json.RawMessage(c.Body())}
+	return ac.deadLetterRequest(c, "deadletters.update", request)
+}
+
+// ReplayDeadLetter handles POST /api/v1/admin/dead-letters/:id/replay
+func (ac *AdminController) ReplayDeadLetter(c *fiber.Ctx) error {
+	request := job_dispatcher.DeadLetterRequest{ID: c.Params("id")}
+	return ac.deadLetterRequest(c, "deadletters.replay", request)
+}
+
+// DiscardDeadLetter handles DELETE /api/v1/admin/dead-letters/:id
+func (ac *AdminController) DiscardDeadLetter(c *fiber.Ctx) error {
+	request := job_dispatcher.DeadLetterRequest{ID: c.Params("id")}
+	return ac.deadLetterRequest(c, "deadletters.discard", request)
+}
+
+// deadLetterRequest sends a dead letter request to the job dispatcher and writes its response
+func (ac *AdminController) deadLetterRequest(c *fiber.Ctx, subject string, request job_dispatcher.DeadLetterRequest) error {
+	responseData, err := ac.natsClient.PublishWithReply(subject, request, 30*time.Second)
+	if err != nil {
+		ac.logger.Error("Failed to send dead letter request", "subject", subject, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process dead letter request",
+		})
+	}
+
+	var response job_dispatcher.DeadLetterResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		ac.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	if request.ID == "" {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data":    response.Entries,
+			"count":   len(response.Entries),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Entry,
+	})
+}
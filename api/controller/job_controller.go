@@ -1,28 +1,42 @@
 package controller
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"lamda_backend/api/middleware"
 	"lamda_backend/internal/job_dispatcher"
 	"lamda_backend/pkg/logger"
 	"lamda_backend/pkg/nats"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// logKeepAliveInterval is how often an idle follow-mode log stream sends a comment
+	logKeepAliveInterval = 15 * time.Second
+	// logReplayIdleTimeout ends a non-follow log stream when no retained chunks arrive
+	logReplayIdleTimeout = 2 * time.Second
 )
 
 // JobController handles HTTP requests for job operations
 type JobController struct {
-	natsClient *nats.NATSClient
-	logger     *logger.Logger
+	natsClient     *nats.NATSClient
+	logger         *logger.Logger
+	ipfsGatewayURL string
 }
 
 // NewJobController creates a new job controller
-func NewJobController(natsClient *nats.NATSClient, logger *logger.Logger) *JobController {
+func NewJobController(natsClient *nats.NATSClient, logger *logger.Logger, ipfsGatewayURL string) *JobController {
 	return &JobController{
-		natsClient: natsClient,
-		logger:     logger.WithService("job-controller"),
+		natsClient:     natsClient,
+		logger:         logger.WithService("job-controller"),
+		ipfsGatewayURL: ipfsGatewayURL,
 	}
 }
 
@@ -46,6 +60,48 @@ func (jc *JobController) GetJobs(c *fiber.Ctx) error {
 		query.Status = job_dispatcher.JobStatus(status)
 	}
 
+	// Parse label selectors, e.g. ?label=team=vision&label=experiment
+	var selectors []string
+	for _, selector := range c.Context().QueryArgs().PeekMulti("label") {
+		selectors = append(selectors, string(selector))
+	}
+	labels, err := job_dispatcher.ParseLabelSelector(selectors)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if len(labels) > 0 {
+		query.Labels = labels
+	}
+
+	// Parse date ranges as RFC 3339 timestamps
+	for param, target := range map[string]**time.Time{
+		"created_from":   &query.CreatedFrom,
+		"created_to":     &query.CreatedTo,
+		"completed_from": &query.CompletedFrom,
+		"completed_to":   &query.CompletedTo,
+	} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": fmt.Sprintf("%s must be an RFC 3339 timestamp", param),
+				})
+			}
+			*target = &parsed
+		}
+	}
+
+	// Parse the remaining filters and sorting, which the job dispatcher validates
+	query.DockerImage = c.Query("docker_image")
+	query.MinPayment = c.Query("min_payment")
+	query.MaxPayment = c.Query("max_payment")
+	query.ChainID = c.Query("chain_id")
+	query.Search = c.Query("q")
+	query.SortBy = c.Query("sort_by")
+	query.SortOrder = c.Query("sort_order")
+
 	// Parse limit
 	if limitStr := c.Query("limit"); limitStr != "" {
 		if limit, err := strconv.Atoi(limitStr); err == nil {
@@ -90,6 +146,12 @@ func (jc *JobController) GetJobs(c *fiber.Ctx) error {
 		})
 	}
 
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    response,
@@ -107,6 +169,7 @@ func (jc *JobController) GetJobByID(c *fiber.Ctx) error {
 
 	// Create a query for a specific job
 	query := job_dispatcher.JobQuery{
+		JobID: jobID,
 		Limit: 1,
 	}
 
@@ -360,3 +423,765 @@ func calculateJobStats(jobs []job_dispatcher.Job) fiber.Map {
 		"status_breakdown": statusBreakdown,
 	}
 }
+
+// StreamJobLogs handles GET /api/v1/jobs/:id/logs
+func (jc *JobController) StreamJobLogs(c *fiber.Ctx) error {
+	jobID := c.Params("id")
+	if jobID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Job ID is required",
+		})
+	}
+
+	job, err := jc.fetchJob(jobID)
+	if err != nil {
+		jc.logger.Error("Failed to query job", "error", err, "job_id", jobID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to query jobs",
+		})
+	}
+	if job == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Job not found",
+		})
+	}
+
+	// Only the renter who created the job may read its output
+	if !strings.EqualFold(job.RenterAddress, middleware.WalletAddress(c)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the job's renter can access its logs",
+		})
+	}
+
+	follow := c.QueryBool("follow", false)
+
+	type logEvent struct {
+		data    []byte
+		pending uint64
+	}
+	events := make(chan logEvent, 256)
+	done := make(chan struct{})
+
+	// Replay the retained tail from JetStream, then keep receiving live chunks
+	sub, err := jc.natsClient.ReplayJetStream(job_dispatcher.JobLogSubject(jobID), func(data []byte, pending uint64) {
+		select {
+		case events <- logEvent{data: data, pending: pending}:
+		case <-done:
+		}
+	})
+	if err != nil {
+		jc.logger.Error("Failed to subscribe to job logs", "error", err, "job_id", jobID)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to stream job logs",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer sub.Unsubscribe()
+		defer close(done)
+
+		keepAlive := time.NewTicker(logKeepAliveInterval)
+		defer keepAlive.Stop()
+
+		idle := time.NewTimer(logReplayIdleTimeout)
+		defer idle.Stop()
+		if follow {
+			idle.Stop()
+		}
+
+		// Set once a follow stream sees the job finish, after which it ends like a
+		// non-follow stream when no more chunks arrive
+		draining := false
+
+		for {
+			select {
+			case event := <-events:
+				chunk, err := job_dispatcher.VerifyLogChunk(event.data, job)
+				if err != nil {
+					jc.logger.Warn("Skipping unverified log chunk", "error", err, "job_id", jobID)
+					continue
+				}
+
+				// Re-encode rather than echo the published bytes so a chunk cannot break SSE framing
+				data, err := json.Marshal(chunk)
+				if err != nil {
+					jc.logger.Warn("Skipping unencodable log chunk", "error", err, "job_id", jobID)
+					continue
+				}
+
+				fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", chunk.Sequence, data)
+				if err := w.Flush(); err != nil {
+					// Client disconnected
+					return
+				}
+
+				// Non-follow streams end once the retained tail is drained;
+				// follow streams end when the provider marks the last chunk
+				if (!follow && event.pending == 0) || chunk.Final {
+					fmt.Fprint(w, "event: end\ndata: {}\n\n")
+					w.Flush()
+					return
+				}
+
+				if !follow || draining {
+					idle.Reset(logReplayIdleTimeout)
+				}
+			case <-idle.C:
+				fmt.Fprint(w, "event: end\ndata: {}\n\n")
+				w.Flush()
+				return
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+				if err := w.Flush(); err != nil {
+					return
+				}
+
+				// Follow streams also end once the job finishes, in case the provider never
+				// sends a final chunk
+				if follow && !draining {
+					if current, err := jc.fetchJob(jobID); err == nil && current != nil && current.Status.IsTerminal() {
+						draining = true
+						idle.Reset(logReplayIdleTimeout)
+					}
+				}
+			}
+		}
+	}))
+
+	return nil
+}
+
+// GetJobResults handles GET /api/v1/jobs/:id/results
+func (jc *JobController) GetJobResults(c *fiber.Ctx) error {
+	job, err := jc.fetchJob(c.Params("id"))
+	if err != nil {
+		jc.logger.Error("Failed to get job", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get job",
+		})
+	}
+
+	if job == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Job not found",
+		})
+	}
+
+	// Only the renter who created the job may read its output
+	if !strings.EqualFold(job.RenterAddress, middleware.WalletAddress(c)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the job's renter can access its results",
+		})
+	}
+
+	// The manifest is left out of job responses, so it is fetched separately
+	query := job_dispatcher.JobQuery{JobID: job.ID}
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.results", query, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get job results", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get job results",
+		})
+	}
+
+	var response job_dispatcher.JobResultsResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	if response.Manifest == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Job has no result manifest yet",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Manifest.WithDownloadURLs(jc.ipfsGatewayURL),
+	})
+}
+
+// GetJobMetrics handles GET /api/v1/jobs/:id/metrics
+func (jc *JobController) GetJobMetrics(c *fiber.Ctx) error {
+	job, err := jc.fetchJob(c.Params("id"))
+	if err != nil {
+		jc.logger.Error("Failed to get job", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get job",
+		})
+	}
+
+	if job == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Job not found",
+		})
+	}
+
+	// Only the renter who created the job may read its telemetry
+	if !strings.EqualFold(job.RenterAddress, middleware.WalletAddress(c)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the job's renter can access its metrics",
+		})
+	}
+
+	query := job_dispatcher.JobQuery{JobID: job.ID}
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.metrics", query, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get job metrics", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get job metrics",
+		})
+	}
+
+	var response job_dispatcher.JobMetricsResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Metrics,
+	})
+}
+
+// SetJobLabels handles PUT /api/v1/jobs/:id/labels
+func (jc *JobController) SetJobLabels(c *fiber.Ctx) error {
+	var labels map[string]string
+	if err := c.BodyParser(&labels); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Labels must be a JSON object of strings",
+		})
+	}
+
+	// Only the job's renter may label it
+	request := job_dispatcher.JobLabelsRequest{
+		JobID:         c.Params("id"),
+		RenterAddress: middleware.WalletAddress(c),
+		Labels:        labels,
+	}
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.labels.set", request, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to set job labels", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to set job labels",
+		})
+	}
+
+	var response job_dispatcher.JobLabelsResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Job,
+	})
+}
+
+// GetJobAttempts handles GET /api/v1/jobs/:id/attempts
+func (jc *JobController) GetJobAttempts(c *fiber.Ctx) error {
+	query := job_dispatcher.JobQuery{JobID: c.Params("id")}
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.attempts", query, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get job attempts", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get job attempts",
+		})
+	}
+
+	var response job_dispatcher.JobAttemptsResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Attempts,
+	})
+}
+
+// SubmitJobSpec handles POST /api/v1/jobs/specs
+func (jc *JobController) SubmitJobSpec(c *fiber.Ctx) error {
+	var spec job_dispatcher.JobSpec
+	if err := c.BodyParser(&spec); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job spec",
+		})
+	}
+
+	// Specs are always owned by the authenticated wallet
+	spec.RenterAddress = middleware.WalletAddress(c)
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.spec.submit", spec, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to submit job spec", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to submit job spec",
+		})
+	}
+
+	var response job_dispatcher.JobSpecResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CreatePipeline handles POST /api/v1/pipelines
+func (jc *JobController) CreatePipeline(c *fiber.Ctx) error {
+	var pipeline job_dispatcher.Pipeline
+	if err := c.BodyParser(&pipeline); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid pipeline",
+		})
+	}
+
+	// Pipelines are always owned by the authenticated wallet
+	pipeline.RenterAddress = middleware.WalletAddress(c)
+
+	return jc.pipelineRequest(c, "pipelines.create", pipeline, fiber.StatusCreated)
+}
+
+// GetPipeline handles GET /api/v1/pipelines/:id
+func (jc *JobController) GetPipeline(c *fiber.Ctx) error {
+	query := job_dispatcher.PipelineQuery{PipelineID: c.Params("id")}
+
+	responseData, err := jc.natsClient.PublishWithReply("pipelines.get", query, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get pipeline", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get pipeline",
+		})
+	}
+
+	var response job_dispatcher.PipelineResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Pipeline == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Pipeline not found",
+		})
+	}
+
+	if !strings.EqualFold(response.Pipeline.RenterAddress, middleware.WalletAddress(c)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the pipeline's renter can access it",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Pipeline,
+	})
+}
+
+// SubmitPipelineStage handles POST /api/v1/pipelines/:id/stages/:stage/spec
+func (jc *JobController) SubmitPipelineStage(c *fiber.Ctx) error {
+	var submission job_dispatcher.PipelineStageSubmission
+	if err := c.BodyParser(&submission); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid stage submission",
+		})
+	}
+
+	submission.PipelineID = c.Params("id")
+	submission.Stage = c.Params("stage")
+	submission.RenterAddress = middleware.WalletAddress(c)
+
+	return jc.pipelineRequest(c, "pipelines.stage.submit", submission, fiber.StatusOK)
+}
+
+// pipelineRequest sends a pipeline mutation to the job dispatcher and writes its response
+func (jc *JobController) pipelineRequest(c *fiber.Ctx, subject string, request interface{}, status int) error {
+	responseData, err := jc.natsClient.PublishWithReply(subject, request, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to send pipeline request", "subject", subject, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process pipeline request",
+		})
+	}
+
+	var response job_dispatcher.PipelineResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"data":    response.Pipeline,
+	})
+}
+
+// SaveTemplate handles POST /api/v1/templates and PUT /api/v1/templates/:id
+func (jc *JobController) SaveTemplate(c *fiber.Ctx) error {
+	var template job_dispatcher.JobTemplate
+	if err := c.BodyParser(&template); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template",
+		})
+	}
+
+	// Templates are always owned by the authenticated wallet
+	template.ID = c.Params("id")
+	template.RenterAddress = middleware.WalletAddress(c)
+
+	status := fiber.StatusOK
+	if template.ID == "" {
+		status = fiber.StatusCreated
+	}
+
+	return jc.templateRequest(c, "templates.save", template, status)
+}
+
+// GetTemplates handles GET /api/v1/templates
+func (jc *JobController) GetTemplates(c *fiber.Ctx) error {
+	request := job_dispatcher.JobTemplateRequest{RenterAddress: middleware.WalletAddress(c)}
+	return jc.templateRequest(c, "templates.list", request, fiber.StatusOK)
+}
+
+// DeleteTemplate handles DELETE /api/v1/templates/:id
+func (jc *JobController) DeleteTemplate(c *fiber.Ctx) error {
+	request := job_dispatcher.JobTemplateRequest{TemplateID: c.Params("id"), RenterAddress: middleware.WalletAddress(c)}
+	return jc.templateRequest(c, "templates.delete", request, fiber.StatusOK)
+}
+
+// RunTemplate handles POST /api/v1/templates/:id/run
+func (jc *JobController) RunTemplate(c *fiber.Ctx) error {
+	request := job_dispatcher.JobTemplateRequest{TemplateID: c.Params("id"), RenterAddress: middleware.WalletAddress(c)}
+	return jc.templateRequest(c, "templates.run", request, fiber.StatusCreated)
+}
+
+// GetTemplateRuns handles GET /api/v1/templates/:id/runs
+func (jc *JobController) GetTemplateRuns(c *fiber.Ctx) error {
+	request := job_dispatcher.JobTemplateRequest{TemplateID: c.Params("id"), RenterAddress: middleware.WalletAddress(c)}
+	return jc.templateRequest(c, "templates.runs", request, fiber.StatusOK)
+}
+
+// templateRequest sends a template request to the job dispatcher and writes its response
+func (jc *JobController) templateRequest(c *fiber.Ctx, subject string, request interface{}, status int) error {
+	responseData, err := jc.natsClient.PublishWithReply(subject, request, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to send template request", "subject", subject, "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process template request",
+		})
+	}
+
+	var response job_dispatcher.JobTemplateResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// CreateVerificationGroup handles POST /api/v1/verifications
+func (jc *JobController) CreateVerificationGroup(c *fiber.Ctx) error {
+	var request job_dispatcher.VerificationRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid verification request",
+		})
+	}
+
+	// Verification groups are always owned by the authenticated wallet
+	request.RenterAddress = middleware.WalletAddress(c)
+
+	responseData, err := jc.natsClient.PublishWithReply("verifications.create", request, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to create verification group", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create verification group",
+		})
+	}
+
+	var response job_dispatcher.VerificationResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    response.Group,
+	})
+}
+
+// GetVerificationGroup handles GET /api/v1/verifications/:id
+func (jc *JobController) GetVerificationGroup(c *fiber.Ctx) error {
+	query := job_dispatcher.VerificationQuery{GroupID: c.Params("id")}
+
+	responseData, err := jc.natsClient.PublishWithReply("verifications.get", query, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get verification group", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get verification group",
+		})
+	}
+
+	var response job_dispatcher.VerificationResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Group == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Verification group not found",
+		})
+	}
+
+	if !strings.EqualFold(response.Group.RenterAddress, middleware.WalletAddress(c)) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the verification group's renter can access it",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Group,
+	})
+}
+
+// GetProviderVerificationStats handles GET /api/v1/nodes/:address/verifications
+func (jc *JobController) GetProviderVerificationStats(c *fiber.Ctx) error {
+	query := job_dispatcher.VerificationQuery{ProviderAddress: c.Params("address")}
+
+	responseData, err := jc.natsClient.PublishWithReply("verifications.provider.stats", query, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get verification stats", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get verification stats",
+		})
+	}
+
+	var stats job_dispatcher.ProviderVerificationStats
+	if err := json.Unmarshal(responseData, &stats); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// CreateQuote handles POST /api/v1/quotes
+func (jc *JobController) CreateQuote(c *fiber.Ctx) error {
+	var request job_dispatcher.QuoteRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid quote request",
+		})
+	}
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.quote", request, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get quote", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get quote",
+		})
+	}
+
+	var response job_dispatcher.QuoteResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Quotes,
+	})
+}
+
+// MatchProviders handles POST /api/v1/match
+func (jc *JobController) MatchProviders(c *fiber.Ctx) error {
+	var request job_dispatcher.MatchRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid match request",
+		})
+	}
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.match", request, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to match providers", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to match providers",
+		})
+	}
+
+	var response job_dispatcher.MatchResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Matches,
+	})
+}
+
+// GetDispatcherKey handles GET /api/v1/dispatcher/key
+func (jc *JobController) GetDispatcherKey(c *fiber.Ctx) error {
+	responseData, err := jc.natsClient.PublishWithReply("dispatcher.key", nil, 10*time.Second)
+	if err != nil {
+		jc.logger.Error("Failed to get dispatcher key", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get dispatcher key",
+		})
+	}
+
+	var response job_dispatcher.DispatcherKeyResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		jc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// fetchJob queries a single job by ID, returning nil if it does not exist
+func (jc *JobController) fetchJob(jobID string) (*job_dispatcher.Job, error) {
+	query := job_dispatcher.JobQuery{
+		JobID: jobID,
+		Limit: 1,
+	}
+
+	responseData, err := jc.natsClient.PublishWithReply("jobs.query", query, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+
+	var response job_dispatcher.JobsResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	for _, job := range response.Jobs {
+		if job.ID == jobID {
+			return &job, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -3,8 +3,10 @@ package controller
 import (
 	"encoding/json"
 	"strconv"
+	"strings"
 	"time"
 
+	"lamda_backend/internal/auth"
 	"lamda_backend/internal/node_registry"
 	"lamda_backend/pkg/logger"
 	"lamda_backend/pkg/nats"
@@ -50,6 +52,64 @@ func (nc *NodeController) GetActiveNodes(c *fiber.Ctx) error {
 		}
 	}
 
+	// Parse min_gpu_count
+	if minGPUCountStr := c.Query("min_gpu_count"); minGPUCountStr != "" {
+		if minGPUCount, err := strconv.Atoi(minGPUCountStr); err == nil {
+			query.MinGPUCount = &minGPUCount
+		}
+	}
+
+	// Parse min_cpu_cores
+	if minCPUCoresStr := c.Query("min_cpu_cores"); minCPUCoresStr != "" {
+		if minCPUCores, err := strconv.Atoi(minCPUCoresStr); err == nil {
+			query.MinCPUCores = &minCPUCores
+		}
+	}
+
+	// Parse min_ram_gb
+	if minRAMGBStr := c.Query("min_ram_gb"); minRAMGBStr != "" {
+		if minRAMGB, err := strconv.Atoi(minRAMGBStr); err == nil {
+			query.MinRAMGB = &minRAMGB
+		}
+	}
+
+	// Parse min_free_disk_gb
+	if minFreeDiskGBStr := c.Query("min_free_disk_gb"); minFreeDiskGBStr != "" {
+		if minFreeDiskGB, err := strconv.Atoi(minFreeDiskGBStr); err == nil {
+			query.MinFreeDiskGB = &minFreeDiskGB
+		}
+	}
+
+	// Parse min_bandwidth_mbps
+	if minBandwidthMbpsStr := c.Query("min_bandwidth_mbps"); minBandwidthMbpsStr != "" {
+		if minBandwidthMbps, err := strconv.Atoi(minBandwidthMbpsStr); err == nil {
+			query.MinBandwidthMbps = &minBandwidthMbps
+		}
+	}
+
+	// Parse capability version and runtime filters
+	query.MinComputeCapability = c.Query("min_compute_capability")
+	query.MinDriverVersion = c.Query("min_driver_version")
+	query.MinCUDAVersion = c.Query("min_cuda_version")
+	query.ContainerRuntime = c.Query("container_runtime")
+
+	// Parse price filter and sorting
+	query.MaxPricePerGPUHourWei = c.Query("max_price_per_gpu_hour_wei")
+	query.SortBy = c.Query("sort_by")
+	query.SortOrder = c.Query("sort_order")
+
+	// Parse location filters, given as comma-separated lists, and the region to sort nearest to
+	if regions := c.Query("region"); regions != "" {
+		query.Regions = strings.Split(regions, ",")
+	}
+	if countries := c.Query("country"); countries != "" {
+		query.Countries = strings.Split(countries, ",")
+	}
+	query.NearRegion = c.Query("near_region")
+
+	// Parse load filter
+	query.ExcludeSaturated = c.QueryBool("exclude_saturated", false)
+
 	// Parse limit
 	if limitStr := c.Query("limit"); limitStr != "" {
 		if limit, err := strconv.Atoi(limitStr); err == nil {
@@ -94,6 +154,12 @@ func (nc *NodeController) GetActiveNodes(c *fiber.Ctx) error {
 		})
 	}
 
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    response,
@@ -109,29 +175,54 @@ func (nc *NodeController) GetNodeByAddress(c *fiber.Ctx) error {
 		})
 	}
 
-	// Create a query for a specific node
-	query := node_registry.NodeQuery{
-		Limit: 1,
+	// Look up the node regardless of its online state
+	lookup := node_registry.NodeLookup{
+		WalletAddress: address,
 	}
 
-	// Query nodes via NATS
-	queryData, err := json.Marshal(query)
+	responseData, err := nc.natsClient.PublishWithReply("nodes.get", lookup, 10*time.Second)
 	if err != nil {
-		nc.logger.Error("Failed to marshal query", "error", err)
+		nc.logger.Error("Failed to query nodes", "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to process query",
+			"error": "Failed to query nodes",
 		})
 	}
 
-	responseData, err := nc.natsClient.PublishWithReply("nodes.query", queryData, 10*time.Second)
+	var response node_registry.NodeLookupResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if !response.Found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Node not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Node,
+	})
+}
+
+// GetImagePolicy handles GET /api/v1/nodes/:address/image-policy
+func (nc *NodeController) GetImagePolicy(c *fiber.Ctx) error {
+	lookup := node_registry.NodeLookup{
+		WalletAddress: c.Params("address"),
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.policy.get", lookup, 10*time.Second)
 	if err != nil {
-		nc.logger.Error("Failed to query nodes", "error", err)
+		nc.logger.Error("Failed to query image policy", "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to query nodes",
+			"error": "Failed to query image policy",
 		})
 	}
 
-	var response node_registry.ActiveNodesResponse
+	var response node_registry.ImagePolicyResponse
 	if err := json.Unmarshal(responseData, &response); err != nil {
 		nc.logger.Error("Failed to unmarshal response", "error", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -139,18 +230,353 @@ func (nc *NodeController) GetNodeByAddress(c *fiber.Ctx) error {
 		})
 	}
 
-	// Find the specific node
-	for _, node := range response.Nodes {
-		if node.WalletAddress == address {
-			return c.JSON(fiber.Map{
-				"success": true,
-				"data":    node,
-			})
-		}
+	if response.Policy == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Image policy not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Policy,
+	})
+}
+
+// SetImagePolicy handles PUT /api/v1/nodes/:address/image-policy
+func (nc *NodeController) SetImagePolicy(c *fiber.Ctx) error {
+	var request auth.SignedRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signed request",
+		})
+	}
+
+	if !strings.EqualFold(request.Address, c.Params("address")) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Signed request address does not match node address",
+		})
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.policy.set", request, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to update image policy", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update image policy",
+		})
+	}
+
+	var response node_registry.ImagePolicyResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Policy,
+	})
+}
+
+// SetConcurrencyLimit handles PUT /api/v1/nodes/:address/concurrency
+func (nc *NodeController) SetConcurrencyLimit(c *fiber.Ctx) error {
+	var request auth.SignedRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signed request",
+		})
+	}
+
+	if !strings.EqualFold(request.Address, c.Params("address")) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Signed request address does not match node address",
+		})
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.concurrency.set", request, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to update concurrency limit", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update concurrency limit",
+		})
+	}
+
+	var response node_registry.ConcurrencyResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
 	}
 
-	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-		"error": "Node not found",
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Node,
+	})
+}
+
+// GetUptime handles GET /api/v1/nodes/:address/uptime
+func (nc *NodeController) GetUptime(c *fiber.Ctx) error {
+	lookup := node_registry.NodeLookup{
+		WalletAddress: c.Params("address"),
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.uptime", lookup, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to query uptime", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to query uptime",
+		})
+	}
+
+	var response node_registry.UptimeResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	if response.Report == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Node not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Report,
+	})
+}
+
+// GetCapabilityHistory handles GET /api/v1/nodes/:address/capabilities
+func (nc *NodeController) GetCapabilityHistory(c *fiber.Ctx) error {
+	lookup := node_registry.NodeLookup{
+		WalletAddress: c.Params("address"),
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.capabilities.history", lookup, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to query capability history", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to query capability history",
+		})
+	}
+
+	var response node_registry.CapabilityHistoryResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Versions,
+	})
+}
+
+// SetCapabilities handles PUT /api/v1/nodes/:address/capabilities
+func (nc *NodeController) SetCapabilities(c *fiber.Ctx) error {
+	var request auth.SignedRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signed request",
+		})
+	}
+
+	if !strings.EqualFold(request.Address, c.Params("address")) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Signed request address does not match node address",
+		})
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.capabilities.set", request, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to update capability profile", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update capability profile",
+		})
+	}
+
+	var response node_registry.CapabilityResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Node,
+	})
+}
+
+// SetRateCard handles PUT /api/v1/nodes/:address/rate-card
+func (nc *NodeController) SetRateCard(c *fiber.Ctx) error {
+	var request auth.SignedRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signed request",
+		})
+	}
+
+	if !strings.EqualFold(request.Address, c.Params("address")) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Signed request address does not match node address",
+		})
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.ratecard.set", request, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to update rate card", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update rate card",
+		})
+	}
+
+	var response node_registry.RateCardResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Node,
+	})
+}
+
+// SetLocation handles PUT /api/v1/nodes/:address/location
+func (nc *NodeController) SetLocation(c *fiber.Ctx) error {
+	var request auth.SignedRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signed request",
+		})
+	}
+
+	if !strings.EqualFold(request.Address, c.Params("address")) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Signed request address does not match node address",
+		})
+	}
+
+	// The agent's own address, used to locate providers that do not declare a region. Fiber
+	// only reads it from the proxy header for requests from TRUSTED_PROXIES.
+	locationRequest := node_registry.LocationRequest{
+		SignedRequest: request,
+		ConnectionIP:  c.IP(),
+	}
+	responseData, err := nc.natsClient.PublishWithReply("nodes.location.set", locationRequest, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to update location", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update location",
+		})
+	}
+
+	var response node_registry.LocationResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Node,
+	})
+}
+
+// SetAvailability handles PUT /api/v1/nodes/:address/availability
+func (nc *NodeController) SetAvailability(c *fiber.Ctx) error {
+	var request auth.SignedRequest
+	if err := c.BodyParser(&request); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signed request",
+		})
+	}
+
+	if !strings.EqualFold(request.Address, c.Params("address")) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Signed request address does not match node address",
+		})
+	}
+
+	responseData, err := nc.natsClient.PublishWithReply("nodes.availability.set", request, 10*time.Second)
+	if err != nil {
+		nc.logger.Error("Failed to update availability schedule", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update availability schedule",
+		})
+	}
+
+	var response node_registry.AvailabilityResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		nc.logger.Error("Failed to unmarshal response", "error", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process response",
+		})
+	}
+
+	if response.Error != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": response.Error,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    response.Node,
 	})
 }
 
@@ -204,6 +630,7 @@ func calculateNodeStats(nodes []node_registry.Provider) fiber.Map {
 			"avg_reputation": 0,
 			"gpu_models":     []string{},
 			"total_jobs":     0,
+			"utilization":    calculateUtilization(nodes),
 		}
 	}
 
@@ -238,5 +665,52 @@ func calculateNodeStats(nodes []node_registry.Provider) fiber.Map {
 		"gpu_models":       gpuModelList,
 		"total_jobs":       totalJobs,
 		"gpu_model_counts": gpuModels,
+		"utilization":      calculateUtilization(nodes),
+	}
+}
+
+// calculateUtilization summarizes the dispatcher's live job counts across nodes. Slots only
+// count nodes with a concurrency limit.
+func calculateUtilization(nodes []node_registry.Provider) fiber.Map {
+	activeJobs := 0
+	queuedJobs := 0
+	totalSlots := 0
+	usedSlots := 0
+	freeSlots := 0
+	saturatedNodes := 0
+	var updatedAt *time.Time
+
+	for _, node := range nodes {
+		activeJobs += node.CurrentLoad
+		queuedJobs += node.QueuedJobs
+
+		if node.SlotLimit != nil {
+			totalSlots += *node.SlotLimit
+			usedSlots += min(node.CurrentLoad, *node.SlotLimit)
+		}
+		if node.FreeSlots != nil {
+			freeSlots += *node.FreeSlots
+			if *node.FreeSlots == 0 {
+				saturatedNodes++
+			}
+		}
+		if node.LoadUpdatedAt != nil && (updatedAt == nil || node.LoadUpdatedAt.After(*updatedAt)) {
+			updatedAt = node.LoadUpdatedAt
+		}
+	}
+
+	utilization := 0.0
+	if totalSlots > 0 {
+		utilization = float64(usedSlots) / float64(totalSlots) * 100
+	}
+
+	return fiber.Map{
+		"active_jobs":         activeJobs,
+		"queued_jobs":         queuedJobs,
+		"total_slots":         totalSlots,
+		"free_slots":          freeSlots,
+		"saturated_nodes":     saturatedNodes,
+		"utilization_percent": utilization,
+		"updated_at":          updatedAt,
 	}
 }
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"lamda_backend/internal/auth"
+	"lamda_backend/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofiber/fiber/v2"
+)
+
+// walletAddressKey is the fiber locals key holding the authenticated wallet address
+const walletAddressKey = "wallet_address"
+
+// RequireWallet authenticates requests with a base64-encoded SIWE signature sent as a
+// bearer token. Browsers cannot set headers on EventSource requests, so the token is
+// also accepted in the access_token query parameter. The message must be for domain (the
+// request's host when empty) and issued within maxAge, so a sign-in collected by another
+// site or leaked from a URL stops working quickly.
+func RequireWallet(domain string, maxAge time.Duration, log *logger.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer"))
+		if token == "" {
+			token = c.Query("access_token")
+		}
+		if token == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authentication required",
+			})
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			raw, err = base64.RawURLEncoding.DecodeString(token)
+		}
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid authentication token",
+			})
+		}
+
+		var signature auth.SIWESignature
+		if err := json.Unmarshal(raw, &signature); err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid authentication token",
+			})
+		}
+
+		expectedDomain := domain
+		if expectedDomain == "" {
+			expectedDomain = c.Hostname()
+		}
+		if err := auth.VerifySIWELogin(signature, expectedDomain, maxAge, time.Now()); err != nil {
+			log.Warn("Rejected wallet authentication", "error", err, "path", c.Path())
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid signature",
+			})
+		}
+
+		c.Locals(walletAddressKey, common.HexToAddress(signature.Message.Address).Hex())
+		return c.Next()
+	}
+}
+
+// WalletAddress returns the checksummed wallet address authenticated by RequireWallet
+func WalletAddress(c *fiber.Ctx) string {
+	address, _ := c.Locals(walletAddressKey).(string)
+	return address
+}
+
+// RequireAdmin authenticates operator requests with the static ADMIN_API_TOKEN sent as a
+// bearer token. Admin routes are disabled when no token is configured.
+func RequireAdmin(token string, log *logger.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Admin API is disabled",
+			})
+		}
+
+		provided := strings.TrimSpace(strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer"))
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			log.Warn("Rejected admin authentication", "path", c.Path(), "ip", c.IP())
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid admin token",
+			})
+		}
+
+		return c.Next()
+	}
+}
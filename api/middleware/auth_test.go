@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gofiber/fiber/v2"
+
+	"lamda_backend/internal/auth"
+	"lamda_backend/pkg/logger"
+)
+
+const testDomain = "gateway.test"
+
+func newWalletApp(domain string) *fiber.App {
+	app := fiber.New()
+	app.Get("/whoami", RequireWallet(domain, 15*time.Minute, logger.New("error")), func(c *fiber.Ctx) error {
+		return c.SendString(WalletAddress(c))
+	})
+	return app
+}
+
+func siweMessage(t *testing.T, key *ecdsa.PrivateKey, issuedAt time.Time) auth.SIWEMessage {
+	t.Helper()
+	return auth.SIWEMessage{
+		Domain:    testDomain,
+		Address:   crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		Statement: "Sign in to Lamda Network",
+		URI:       "https://" + testDomain,
+		Version:   "1",
+		ChainID:   97,
+		Nonce:     "a1b2c3d4e5f6",
+		IssuedAt:  issuedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func walletToken(t *testing.T, message auth.SIWEMessage, signature string) string {
+	t.Helper()
+	raw, err := json.Marshal(auth.SIWESignature{Message: message, Signature: signature})
+	if err != nil {
+		t.Fatalf("failed to marshal token: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func signedToken(t *testing.T, key *ecdsa.PrivateKey, message auth.SIWEMessage) string {
+	t.Helper()
+	signature, err := auth.SignSIWEMessage(message, key)
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	return walletToken(t, message, signature)
+}
+
+func requestStatus(t *testing.T, app *fiber.App, target, token string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestRequireWallet_AcceptsPersonalSign(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	token := signedToken(t, key, siweMessage(t, key, time.Now()))
+
+	if status := requestStatus(t, newWalletApp(testDomain), "http://"+testDomain+"/whoami", token); status != fiber.StatusOK {
+		t.Errorf("expected bearer token to be accepted, got %d", status)
+	}
+	if status := requestStatus(t, newWalletApp(testDomain), "http://"+testDomain+"/whoami?access_token="+token, ""); status != fiber.StatusOK {
+		t.Errorf("expected access_token query parameter to be accepted, got %d", status)
+	}
+	if status := requestStatus(t, newWalletApp(""), "http://"+testDomain+"/whoami", token); status != fiber.StatusOK {
+		t.Errorf("expected the request host to be used when no domain is configured, got %d", status)
+	}
+}
+
+func TestRequireWallet_Rejects(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	now := time.Now()
+
+	otherSite := siweMessage(t, key, now)
+	otherSite.Domain = "other.example"
+	stale := siweMessage(t, key, now.Add(-time.Hour))
+	notYetValid := siweMessage(t, key, now)
+	notYetValid.NotBefore = now.Add(time.Hour).UTC().Format(time.RFC3339)
+	expired := siweMessage(t, key, now.Add(-5*time.Minute))
+	expired.ExpirationTime = now.Add(-time.Minute).UTC().Format(time.RFC3339)
+	missingNonce := siweMessage(t, key, now)
+	missingNonce.Nonce = ""
+
+	// A signature over the bare keccak hash, without the EIP-191 prefix personal_sign adds
+	rawMessage := siweMessage(t, key, now)
+	rawSignature, err := crypto.Sign(crypto.Keccak256([]byte(auth.FormatSIWEMessage(rawMessage))), key)
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+
+	tokens := map[string]string{
+		"missing":         "",
+		"malformed":       "not-a-token",
+		"other domain":    signedToken(t, key, otherSite),
+		"stale":           signedToken(t, key, stale),
+		"not yet valid":   signedToken(t, key, notYetValid),
+		"expired":         signedToken(t, key, expired),
+		"missing nonce":   signedToken(t, key, missingNonce),
+		"unprefixed hash": walletToken(t, rawMessage, "0x"+hex.EncodeToString(rawSignature)),
+	}
+	app := newWalletApp(testDomain)
+	for name, token := range tokens {
+		if status := requestStatus(t, app, "http://"+testDomain+"/whoami", token); status != fiber.StatusUnauthorized {
+			t.Errorf("expected %s token to be rejected, got %d", name, status)
+		}
+	}
+
+	// A token signed for this gateway is not accepted by a gateway on another domain
+	token := signedToken(t, key, siweMessage(t, key, now))
+	if status := requestStatus(t, newWalletApp("other.example"), "http://"+testDomain+"/whoami", token); status != fiber.StatusUnauthorized {
+		t.Errorf("expected token for another domain to be rejected, got %d", status)
+	}
+}
@@ -1,7 +1,10 @@
 package router
 
 import (
+	"time"
+
 	"lamda_backend/api/controller"
+	"lamda_backend/api/middleware"
 	"lamda_backend/pkg/logger"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,7 +14,7 @@ import (
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(app *fiber.App, nodeController *controller.NodeController, jobController *controller.JobController, log *logger.Logger) {
+func SetupRoutes(app *fiber.App, nodeController *controller.NodeController, jobController *controller.JobController, adminController *controller.AdminController, adminToken, siweDomain string, siweMaxAge time.Duration, log *logger.Logger) {
 	// Middleware
 	app.Use(recover.New())
 	app.Use(fiberlogger.New(fiberlogger.Config{
@@ -33,20 +36,72 @@ func SetupRoutes(app *fiber.App, nodeController *controller.NodeController, jobC
 
 	// API v1 routes
 	api := app.Group("/api/v1")
+	requireWallet := middleware.RequireWallet(siweDomain, siweMaxAge, log)
 
 	// Node routes
 	nodes := api.Group("/nodes")
 	nodes.Get("/", nodeController.GetActiveNodes)
 	nodes.Get("/stats", nodeController.GetNodeStats)
 	nodes.Get("/:address", nodeController.GetNodeByAddress)
+	nodes.Get("/:address/image-policy", nodeController.GetImagePolicy)
+	nodes.Put("/:address/image-policy", nodeController.SetImagePolicy)
+	nodes.Put("/:address/concurrency", nodeController.SetConcurrencyLimit)
+	nodes.Get("/:address/capabilities", nodeController.GetCapabilityHistory)
+	nodes.Put("/:address/capabilities", nodeController.SetCapabilities)
+	nodes.Put("/:address/rate-card", nodeController.SetRateCard)
+	nodes.Put("/:address/availability", nodeController.SetAvailability)
+	nodes.Put("/:address/location", nodeController.SetLocation)
+	nodes.Get("/:address/uptime", nodeController.GetUptime)
+	nodes.Get("/:address/verifications", jobController.GetProviderVerificationStats)
 
 	// Job routes
 	jobs := api.Group("/jobs")
 	jobs.Get("/", jobController.GetJobs)
 	jobs.Get("/stats", jobController.GetJobStats)
+	jobs.Post("/specs", requireWallet, jobController.SubmitJobSpec)
 	jobs.Get("/:id", jobController.GetJobByID)
 	jobs.Get("/renter/:address", jobController.GetJobsByRenter)
 	jobs.Get("/provider/:address", jobController.GetJobsByProvider)
+	jobs.Get("/:id/logs", requireWallet, jobController.StreamJobLogs)
+	jobs.Get("/:id/results", requireWallet, jobController.GetJobResults)
+	jobs.Get("/:id/attempts", jobController.GetJobAttempts)
+	jobs.Put("/:id/labels", requireWallet, jobController.SetJobLabels)
+	jobs.Get("/:id/metrics", requireWallet, jobController.GetJobMetrics)
+
+	// Pipeline routes
+	pipelines := api.Group("/pipelines", requireWallet)
+	pipelines.Post("/", jobController.CreatePipeline)
+	pipelines.Get("/:id", jobController.GetPipeline)
+	pipelines.Post("/:id/stages/:stage/spec", jobController.SubmitPipelineStage)
+
+	// Job template routes
+	templates := api.Group("/templates", requireWallet)
+	templates.Get("/", jobController.GetTemplates)
+	templates.Post("/", jobController.SaveTemplate)
+	templates.Put("/:id", jobController.SaveTemplate)
+	templates.Delete("/:id", jobController.DeleteTemplate)
+	templates.Post("/:id/run", jobController.RunTemplate)
+	templates.Get("/:id/runs", jobController.GetTemplateRuns)
+
+	// Verification routes
+	verifications := api.Group("/verifications", requireWallet)
+	verifications.Post("/", jobController.CreateVerificationGroup)
+	verifications.Get("/:id", jobController.GetVerificationGroup)
+
+	// Quote and matchmaking routes
+	api.Post("/quotes", jobController.CreateQuote)
+	api.Post("/match", jobController.MatchProviders)
+
+	// Dispatcher routes
+	api.Get("/dispatcher/key", jobController.GetDispatcherKey)
+
+	// Admin routes
+	admin := api.Group("/admin", middleware.RequireAdmin(adminToken, log))
+	admin.Get("/dead-letters", adminController.ListDeadLetters)
+	admin.Get("/dead-letters/:id", adminController.GetDeadLetter)
+	admin.Put("/dead-letters/:id", adminController.UpdateDeadLetter)
+	admin.Post("/dead-letters/:id/replay", adminController.ReplayDeadLetter)
+	admin.Delete("/dead-letters/:id", adminController.DiscardDeadLetter)
 
 	// 404 handler
 	app.Use(func(c *fiber.Ctx) error {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"lamda_backend/api/controller"
 	"lamda_backend/api/router"
@@ -46,12 +47,22 @@ func main() {
 
 	// Initialize controllers
 	nodeController := controller.NewNodeController(natsClient, log)
-	jobController := controller.NewJobController(natsClient, log)
+	jobController := controller.NewJobController(natsClient, log, cfg.IPFSGatewayURL)
+	adminController := controller.NewAdminController(natsClient, log)
 
 	// Create Fiber app
+	// Only trust the proxy header for the client IP when it comes from a configured proxy
+	proxyHeader := ""
+	if len(cfg.TrustedProxies) > 0 {
+		proxyHeader = cfg.ProxyHeader
+	}
+
 	app := fiber.New(fiber.Config{
-		AppName:      "Lamda API Gateway",
-		ServerHeader: "Lamda",
+		AppName:                 "Lamda API Gateway",
+		ServerHeader:            "Lamda",
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		ProxyHeader:             proxyHeader,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			log.Error("Request error", "error", err, "path", c.Path())
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -61,7 +72,7 @@ func main() {
 	})
 
 	// Setup routes
-	router.SetupRoutes(app, nodeController, jobController, log)
+	router.SetupRoutes(app, nodeController, jobController, adminController, cfg.AdminAPIToken, cfg.SIWEDomain, time.Duration(cfg.SIWEMaxAgeMinutes)*time.Minute, log)
 
 	// Start server in a goroutine
 	go func() {
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: dlq-admin [flags] <command> [arguments]
+
+Commands:
+  list [-kind kind] [-status status|all] [-limit n]   List dead letters (pending by default)
+  show <id>                                           Show a dead letter with its payload
+  edit <id> <payload.json|->                          Replace a pending dead letter's payload
+  replay <id>                                         Replay a pending dead letter
+  discard <id>                                        Discard a pending dead letter
+
+Flags:
+`
+
+// client calls the API gateway's admin endpoints
+type client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func main() {
+	apiURL := flag.String("api", getEnv("LAMDA_API_URL", "http://localhost:8080"), "API gateway base URL")
+	token := flag.String("token", os.Getenv("ADMIN_API_TOKEN"), "admin API token")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *token == "" {
+		fmt.Fprintln(os.Stderr, "admin token is required (-token or ADMIN_API_TOKEN)")
+		os.Exit(2)
+	}
+
+	c := &client{
+		baseURL: strings.TrimRight(*apiURL, "/") + "/api/v1/admin/dead-letters",
+		token:   *token,
+		http:    &http.Client{Timeout: 60 * time.Second},
+	}
+
+	if err := run(c, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes a single CLI command
+func run(c *client, command string, args []string) error {
+	switch command {
+	case "list":
+		flags := flag.NewFlagSet("list", flag.ExitOnError)
+		kind := flags.String("kind", "", "job_created, dispatch or job_confirmed")
+		status := flags.String("status", "pending", "pending, replayed, resolved, discarded or all")
+		limit := flags.Int("limit", 50, "maximum entries to list")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		query := url.Values{}
+		query.Set("status", *status)
+		query.Set("limit", fmt.Sprint(*limit))
+		if *kind != "" {
+			query.Set("kind", *kind)
+		}
+		return c.do(http.MethodGet, "?"+query.Encode(), nil)
+
+	case "show", "replay", "discard":
+		if len(args) != 1 {
+			return fmt.Errorf("%s takes a dead letter ID", command)
+		}
+		id := url.PathEscape(args[0])
+
+		switch command {
+		case "show":
+			return c.do(http.MethodGet, "/"+id, nil)
+		case "replay":
+			return c.do(http.MethodPost, "/"+id+"/replay", nil)
+		default:
+			return c.do(http.MethodDelete, "/"+id, nil)
+		}
+
+	case "edit":
+		if len(args) != 2 {
+			return fmt.Errorf("edit takes a dead letter ID and a payload file (- for stdin)")
+		}
+
+		var payload []byte
+		var err error
+		if args[1] == "-" {
+			payload, err = io.ReadAll(os.Stdin)
+		} else {
+			payload, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read payload: %w", err)
+		}
+		return c.do(http.MethodPut, "/"+url.PathEscape(args[0]), payload)
+	}
+
+	return fmt.Errorf("unknown command %q", command)
+}
+
+// do sends an admin request and prints the indented JSON response
+func (c *client) do(method, path string, body []byte) error {
+	request, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		out.Reset()
+		out.Write(data)
+	}
+	fmt.Println(out.String())
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s", method, path, response.Status)
+	}
+	return nil
+}
+
+// getEnv gets an environment variable with a fallback default value
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"lamda_backend/config"
+	"lamda_backend/internal/image_policy"
 	"lamda_backend/internal/job_dispatcher"
 	"lamda_backend/pkg/blockchain"
 	"lamda_backend/pkg/database"
 	"lamda_backend/pkg/logger"
 	"lamda_backend/pkg/nats"
+
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func main() {
@@ -36,7 +41,21 @@ func main() {
 	}
 
 	// Auto-migrate database
-	if err := database.AutoMigrate(db, &job_dispatcher.Job{}); err != nil {
+	if err := database.AutoMigrate(db,
+		&job_dispatcher.Job{},
+		&job_dispatcher.JobSpec{},
+		&job_dispatcher.JobSecret{},
+		&job_dispatcher.JobAttempt{},
+		&job_dispatcher.JobMetricBucket{},
+		&job_dispatcher.DeadLetter{},
+		&job_dispatcher.OutboxMessage{},
+		&job_dispatcher.Pipeline{},
+		&job_dispatcher.PipelineStage{},
+		&job_dispatcher.JobTemplate{},
+		&job_dispatcher.JobTemplateRun{},
+		&job_dispatcher.VerificationGroup{},
+		&job_dispatcher.VerificationMember{},
+	); err != nil {
 		log.Error("Failed to migrate database", "error", err)
 		os.Exit(1)
 	}
@@ -71,8 +90,66 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Load the assignment signing key. Agents and the node registry verify against its address,
+	// so an ephemeral key is only generated when explicitly allowed for development.
+	var dispatcherKey *ecdsa.PrivateKey
+	switch {
+	case cfg.DispatcherSigningKey != "":
+		dispatcherKey, err = crypto.HexToECDSA(strings.TrimPrefix(cfg.DispatcherSigningKey, "0x"))
+	case cfg.AllowEphemeralSigningKey:
+		dispatcherKey, err = crypto.GenerateKey()
+		log.Warn("DISPATCHER_SIGNING_KEY not set, signing assignments with an ephemeral key")
+	default:
+		log.Error("DISPATCHER_SIGNING_KEY is required (set ALLOW_EPHEMERAL_SIGNING_KEY=true to use an ephemeral key in development)")
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Error("Failed to load dispatcher signing key", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Dispatcher signing key loaded", "address", crypto.PubkeyToAddress(dispatcherKey.PublicKey).Hex())
+
+	// Parse renter priority tiers for the provider queues
+	renterTiers, err := job_dispatcher.ParseRenterTiers(cfg.RenterPriorityTiers)
+	if err != nil {
+		log.Error("Failed to parse renter priority tiers", "error", err)
+		os.Exit(1)
+	}
+
+	// Parse provider match score weights
+	matchWeights, err := job_dispatcher.ParseMatchWeights(cfg.MatchWeights)
+	if err != nil {
+		log.Error("Failed to parse match weights", "error", err)
+		os.Exit(1)
+	}
+
+	if cfg.TelemetryBucketSeconds <= 0 {
+		log.Error("TELEMETRY_BUCKET_SECONDS must be positive", "value", cfg.TelemetryBucketSeconds)
+		os.Exit(1)
+	}
+
 	// Initialize job dispatcher service
-	jobDispatcherService := job_dispatcher.NewService(db, natsClient, blockchainClient, log, cfg.JobManagerContractAddress)
+	jobDispatcherService := job_dispatcher.NewService(db, natsClient, blockchainClient, log, cfg.JobManagerContractAddress, job_dispatcher.ServiceConfig{
+		LogTailSize:  int64(cfg.JobLogTailSize),
+		LogRetention: time.Duration(cfg.JobLogRetentionHours) * time.Hour,
+		ImagePolicy: image_policy.Rules{
+			AllowedRegistries:   cfg.ImagePolicyAllowedRegistries,
+			DeniedRegistries:    cfg.ImagePolicyDeniedRegistries,
+			AllowedRepositories: cfg.ImagePolicyAllowedRepositories,
+			DeniedRepositories:  cfg.ImagePolicyDeniedRepositories,
+			RequireDigest:       cfg.ImagePolicyRequireDigest,
+			DisallowLatestTag:   cfg.ImagePolicyDisallowLatestTag,
+			MaxSizeBytes:        int64(cfg.ImagePolicyMaxSizeMB) * 1024 * 1024,
+		},
+		RegistryTokenHosts: cfg.ImagePolicyTokenHosts,
+		RequireEncryption:  cfg.RequireAssignmentEncryption,
+		SigningKey:         dispatcherKey,
+		AssignmentTTL:      time.Duration(cfg.AssignmentTTLMinutes) * time.Minute,
+		DefaultConcurrency: cfg.DefaultProviderConcurrency,
+		RenterTiers:        renterTiers,
+		TelemetryBucket:    time.Duration(cfg.TelemetryBucketSeconds) * time.Second,
+		MatchWeights:       matchWeights,
+	})
 
 	// Start the service
 	if err := jobDispatcherService.Start(context.Background()); err != nil {
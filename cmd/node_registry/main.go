@@ -14,6 +14,8 @@ import (
 	"lamda_backend/pkg/database"
 	"lamda_backend/pkg/logger"
 	"lamda_backend/pkg/nats"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func main() {
@@ -36,7 +38,7 @@ func main() {
 	}
 
 	// Auto-migrate database
-	if err := database.AutoMigrate(db, &node_registry.Provider{}); err != nil {
+	if err := database.AutoMigrate(db, &node_registry.Provider{}, &node_registry.ProviderImagePolicy{}, &node_registry.ProviderCapabilityVersion{}, &node_registry.ProviderUptimeInterval{}, &node_registry.ProviderHeartbeatBucket{}); err != nil {
 		log.Error("Failed to migrate database", "error", err)
 		os.Exit(1)
 	}
@@ -71,8 +73,33 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.ProviderOfflineSeconds <= 0 {
+		log.Error("PROVIDER_OFFLINE_SECONDS must be positive", "value", cfg.ProviderOfflineSeconds)
+		os.Exit(1)
+	}
+	offlineAfter := time.Duration(cfg.ProviderOfflineSeconds) * time.Second
+
+	// Open the GeoIP database used to locate providers that do not declare a region
+	var geoip *node_registry.GeoIPDatabase
+	if cfg.GeoIPDatabasePath != "" {
+		geoip, err = node_registry.OpenGeoIPDatabase(cfg.GeoIPDatabasePath)
+		if err != nil {
+			log.Error("Failed to open GeoIP database", "error", err, "path", cfg.GeoIPDatabasePath)
+			os.Exit(1)
+		}
+		defer geoip.Close()
+	}
+
+	// Provider load snapshots are only trusted from the dispatcher's signing key
+	if cfg.DispatcherAddress == "" {
+		log.Warn("DISPATCHER_ADDRESS is not set, provider load snapshots will be ignored")
+	} else if !common.IsHexAddress(cfg.DispatcherAddress) {
+		log.Error("Invalid DISPATCHER_ADDRESS", "address", cfg.DispatcherAddress)
+		os.Exit(1)
+	}
+
 	// Initialize node registry service
-	nodeRegistryService := node_registry.NewService(db, natsClient, blockchainClient, log, cfg.NodeReputationContractAddress)
+	nodeRegistryService := node_registry.NewService(db, natsClient, blockchainClient, log, cfg.NodeReputationContractAddress, offlineAfter, geoip, cfg.DispatcherAddress)
 
 	// Start the service
 	if err := nodeRegistryService.Start(context.Background()); err != nil {
@@ -84,7 +111,7 @@ func main() {
 
 	// Start background task to mark offline providers
 	go func() {
-		ticker := time.NewTicker(5 * time.Minute)
+		ticker := time.NewTicker(offlineAfter)
 		defer ticker.Stop()
 
 		for {
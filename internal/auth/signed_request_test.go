@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSignedRequest_Verify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	payload := `{"rules":{"require_digest":true},"issued_at":1700000000}`
+	signature, err := SignWalletMessage([]byte(payload), key)
+	if err != nil {
+		t.Fatalf("failed to sign payload: %v", err)
+	}
+
+	request := SignedRequest{Address: address, Payload: payload, Signature: signature}
+	if err := request.Verify(); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	tampered := request
+	tampered.Payload = `{"rules":{"require_digest":false},"issued_at":1700000000}`
+	if err := tampered.Verify(); err == nil {
+		t.Error("expected tampered payload to fail verification")
+	}
+
+	other, _ := crypto.GenerateKey()
+	impersonated := request
+	impersonated.Address = crypto.PubkeyToAddress(other.PublicKey).Hex()
+	if err := impersonated.Verify(); err == nil {
+		t.Error("expected signature from another wallet to fail verification")
+	}
+}
+
+func TestSignedRequest_ValidateScope(t *testing.T) {
+	request := SignedRequest{Address: "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"}
+
+	if err := request.ValidateScope(Scope{Action: "set_rate_card", Address: "0x742d35cc6634c0532925a3b844bc454e4438f44e"}, "set_rate_card"); err != nil {
+		t.Errorf("expected matching scope to be valid, got %v", err)
+	}
+	invalid := []Scope{
+		{Address: request.Address},
+		{Action: "set_location", Address: request.Address},
+		{Action: "set_rate_card"},
+		{Action: "set_rate_card", Address: "0x0000000000000000000000000000000000000001"},
+	}
+	for _, scope := range invalid {
+		if err := request.ValidateScope(scope, "set_rate_card"); err == nil {
+			t.Errorf("expected scope %+v to be rejected", scope)
+		}
+	}
+}
+
+func TestValidateIssuedAt(t *testing.T) {
+	now := time.Now()
+
+	if err := ValidateIssuedAt(now.Unix()); err != nil {
+		t.Errorf("expected current timestamp to be valid, got %v", err)
+	}
+	if err := ValidateIssuedAt(now.Add(-SignedRequestMaxAge - time.Minute).Unix()); err == nil {
+		t.Error("expected expired timestamp to be rejected")
+	}
+	if err := ValidateIssuedAt(now.Add(time.Hour).Unix()); err == nil {
+		t.Error("expected future timestamp to be rejected")
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"fmt"
 	"strings"
 	"time"
-
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// minSIWENonceLength is the shortest nonce EIP-4361 allows
+const minSIWENonceLength = 8
+
 // SIWEMessage represents a Sign-In With Ethereum message
 type SIWEMessage struct {
 	Domain         string `json:"domain"`
@@ -104,32 +104,10 @@ func FormatSIWEMessage(message SIWEMessage) string {
 
 // VerifySIWESignature verifies a SIWE signature
 func VerifySIWESignature(signature SIWESignature) (bool, error) {
-	// Format the message
+	// Wallets sign SIWE messages with personal_sign
 	formattedMessage := FormatSIWEMessage(signature.Message)
-
-	// Hash the message
-	messageHash := crypto.Keccak256Hash([]byte(formattedMessage))
-
-	// Decode the signature
-	sigBytes, err := hex.DecodeString(strings.TrimPrefix(signature.Signature, "0x"))
-	if err != nil {
-		return false, fmt.Errorf("failed to decode signature: %w", err)
-	}
-
-	// Recover the public key
-	pubKey, err := crypto.SigToPub(messageHash.Bytes(), sigBytes)
-	if err != nil {
-		return false, fmt.Errorf("failed to recover public key: %w", err)
-	}
-
-	// Get the address from the public key
-	recoveredAddress := crypto.PubkeyToAddress(*pubKey)
-
-	// Compare with the claimed address
-	claimedAddress := common.HexToAddress(signature.Message.Address)
-
-	if recoveredAddress != claimedAddress {
-		return false, fmt.Errorf("signature verification failed: address mismatch")
+	if err := VerifyWalletSignature(signature.Message.Address, []byte(formattedMessage), signature.Signature); err != nil {
+		return false, err
 	}
 
 	// Check if the message has expired
@@ -145,22 +123,53 @@ func VerifySIWESignature(signature SIWESignature) (bool, error) {
 	return true, nil
 }
 
-// SignSIWEMessage signs a SIWE message with a private key
-func SignSIWEMessage(message SIWEMessage, privateKey *ecdsa.PrivateKey) (string, error) {
-	// Format the message
-	formattedMessage := FormatSIWEMessage(message)
-
-	// Hash the message
-	messageHash := crypto.Keccak256Hash([]byte(formattedMessage))
+// VerifySIWELogin checks a SIWE sign-in presented to domain: the message must be for that
+// domain, carry a nonce, have been issued within maxAge and be inside its validity window,
+// and be signed by its address with personal_sign
+func VerifySIWELogin(signature SIWESignature, domain string, maxAge time.Duration, now time.Time) error {
+	message := signature.Message
+	if domain == "" || !strings.EqualFold(message.Domain, domain) {
+		return fmt.Errorf("message domain %q does not match %q", message.Domain, domain)
+	}
+	if len(message.Nonce) < minSIWENonceLength {
+		return fmt.Errorf("nonce must be at least %d characters", minSIWENonceLength)
+	}
 
-	// Sign the hash
-	signature, err := crypto.Sign(messageHash.Bytes(), privateKey)
+	issuedAt, err := time.Parse(time.RFC3339, message.IssuedAt)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign message: %w", err)
+		return fmt.Errorf("failed to parse issued at: %w", err)
+	}
+	if issuedAt.After(now.Add(signedRequestMaxSkew)) {
+		return fmt.Errorf("message is issued in the future")
+	}
+	if now.Sub(issuedAt) > maxAge {
+		return fmt.Errorf("message expired: issued more than %s ago", maxAge)
+	}
+	if message.ExpirationTime != "" {
+		expirationTime, err := time.Parse(time.RFC3339, message.ExpirationTime)
+		if err != nil {
+			return fmt.Errorf("failed to parse expiration time: %w", err)
+		}
+		if now.After(expirationTime) {
+			return fmt.Errorf("message has expired")
+		}
+	}
+	if message.NotBefore != "" {
+		notBefore, err := time.Parse(time.RFC3339, message.NotBefore)
+		if err != nil {
+			return fmt.Errorf("failed to parse not before: %w", err)
+		}
+		if now.Before(notBefore) {
+			return fmt.Errorf("message is not valid yet")
+		}
 	}
 
-	// Convert to hex
-	return hex.EncodeToString(signature), nil
+	return VerifyWalletSignature(message.Address, []byte(FormatSIWEMessage(message)), signature.Signature)
+}
+
+// SignSIWEMessage signs a SIWE message with a private key the way personal_sign does
+func SignSIWEMessage(message SIWEMessage, privateKey *ecdsa.PrivateKey) (string, error) {
+	return SignWalletMessage([]byte(FormatSIWEMessage(message)), privateKey)
 }
 
 // ParseSIWEMessage parses a SIWE message from a formatted string
@@ -0,0 +1,157 @@
+package image_policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// Rules describes which container images may be dispatched.
+//
+// Registry entries match the registry domain (e.g. "docker.io", "*.gcr.io").
+// Repository entries are glob patterns matched against both the familiar name
+// ("nvidia/cuda", "ubuntu") and the full name ("docker.io/library/ubuntu").
+// Empty allow lists allow everything that is not denied.
+type Rules struct {
+	AllowedRegistries   []string `json:"allowed_registries,omitempty"`
+	DeniedRegistries    []string `json:"denied_registries,omitempty"`
+	AllowedRepositories []string `json:"allowed_repositories,omitempty"`
+	DeniedRepositories  []string `json:"denied_repositories,omitempty"`
+	RequireDigest       bool     `json:"require_digest,omitempty"`
+	DisallowLatestTag   bool     `json:"disallow_latest_tag,omitempty"`
+	MaxSizeBytes        int64    `json:"max_size_bytes,omitempty"`
+}
+
+// Policy scopes
+const (
+	ScopeGlobal   = "global"
+	ScopeProvider = "provider"
+)
+
+// Decision is the result of evaluating an image against the policies
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Scope   string `json:"scope,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// SizeResolver resolves the compressed size of an image in bytes
+type SizeResolver interface {
+	ImageSize(ctx context.Context, ref reference.Named) (int64, error)
+}
+
+// Engine evaluates images against the global policy and a provider's own policy
+type Engine struct {
+	global   Rules
+	resolver SizeResolver
+}
+
+// NewEngine creates a new policy engine. The resolver is only consulted when a
+// size limit applies and may be nil if no limits are configured.
+func NewEngine(global Rules, resolver SizeResolver) *Engine {
+	return &Engine{
+		global:   global,
+		resolver: resolver,
+	}
+}
+
+// Evaluate checks an image against the global rules and, if set, the provider's rules
+func (e *Engine) Evaluate(ctx context.Context, image string, provider *Rules) Decision {
+	if strings.TrimSpace(image) == "" {
+		return deny(ScopeGlobal, "job has no docker image")
+	}
+
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return deny(ScopeGlobal, fmt.Sprintf("invalid image reference %q: %v", image, err))
+	}
+	// An untagged reference pulls :latest
+	ref = reference.TagNameOnly(ref)
+
+	if decision := evaluateReference(ref, e.global, ScopeGlobal); !decision.Allowed {
+		return decision
+	}
+
+	if provider != nil {
+		if decision := evaluateReference(ref, *provider, ScopeProvider); !decision.Allowed {
+			return decision
+		}
+	}
+
+	// Size limits need a registry round trip, so they are checked last
+	limit, scope := e.global.MaxSizeBytes, ScopeGlobal
+	if provider != nil && provider.MaxSizeBytes > 0 && (limit == 0 || provider.MaxSizeBytes < limit) {
+		limit, scope = provider.MaxSizeBytes, ScopeProvider
+	}
+
+	if limit > 0 {
+		if e.resolver == nil {
+			return deny(scope, "image size limit is set but image sizes cannot be resolved")
+		}
+
+		size, err := e.resolver.ImageSize(ctx, ref)
+		if err != nil {
+			return deny(scope, fmt.Sprintf("unable to determine size of %s: %v", reference.FamiliarString(ref), err))
+		}
+		if size > limit {
+			return deny(scope, fmt.Sprintf("image %s is %d bytes, limit is %d bytes", reference.FamiliarString(ref), size, limit))
+		}
+	}
+
+	return Decision{Allowed: true}
+}
+
+// evaluateReference applies the registry, repository, tag and digest rules of one scope
+func evaluateReference(ref reference.Named, rules Rules, scope string) Decision {
+	domain := reference.Domain(ref)
+	names := []string{reference.FamiliarName(ref), ref.Name()}
+
+	if matchAny(rules.DeniedRegistries, domain) {
+		return deny(scope, fmt.Sprintf("registry %s is denied", domain))
+	}
+	if len(rules.AllowedRegistries) > 0 && !matchAny(rules.AllowedRegistries, domain) {
+		return deny(scope, fmt.Sprintf("registry %s is not in the allow list", domain))
+	}
+
+	if matchAny(rules.DeniedRepositories, names...) {
+		return deny(scope, fmt.Sprintf("repository %s is denied", reference.FamiliarName(ref)))
+	}
+	if len(rules.AllowedRepositories) > 0 && !matchAny(rules.AllowedRepositories, names...) {
+		return deny(scope, fmt.Sprintf("repository %s is not in the allow list", reference.FamiliarName(ref)))
+	}
+
+	_, digested := ref.(reference.Digested)
+	if rules.RequireDigest && !digested {
+		return deny(scope, fmt.Sprintf("image %s must be pinned by digest", reference.FamiliarString(ref)))
+	}
+
+	if tagged, ok := ref.(reference.Tagged); ok && rules.DisallowLatestTag && !digested && tagged.Tag() == "latest" {
+		return deny(scope, fmt.Sprintf("image %s uses the latest tag", reference.FamiliarString(ref)))
+	}
+
+	return Decision{Allowed: true}
+}
+
+// matchAny reports whether any value matches any of the glob patterns
+func matchAny(patterns []string, values ...string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deny builds a rejecting decision
+func deny(scope, reason string) Decision {
+	return Decision{
+		Allowed: false,
+		Scope:   scope,
+		Reason:  reason,
+	}
+}
@@ -0,0 +1,135 @@
+package image_policy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/distribution/reference"
+)
+
+type fakeResolver struct {
+	sizes map[string]int64
+}
+
+func (f fakeResolver) ImageSize(ctx context.Context, ref reference.Named) (int64, error) {
+	size, ok := f.sizes[reference.FamiliarString(ref)]
+	if !ok {
+		return 0, fmt.Errorf("manifest unknown")
+	}
+	return size, nil
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	resolver := fakeResolver{sizes: map[string]int64{
+		"nvidia/cuda:11.8-base": 2 << 30,
+		"pytorch/pytorch:2.1":   8 << 30,
+	}}
+
+	tests := []struct {
+		name        string
+		global      Rules
+		provider    *Rules
+		image       string
+		wantAllowed bool
+		wantScope   string
+	}{
+		{
+			name:        "no rules allow everything",
+			image:       "ubuntu",
+			wantAllowed: true,
+		},
+		{
+			name:      "empty image",
+			image:     "",
+			wantScope: ScopeGlobal,
+		},
+		{
+			name:      "unparseable reference",
+			image:     "UPPER/Case:tag",
+			wantScope: ScopeGlobal,
+		},
+		{
+			name:      "denied registry",
+			global:    Rules{DeniedRegistries: []string{"*.example.com"}},
+			image:     "registry.example.com/team/model:1",
+			wantScope: ScopeGlobal,
+		},
+		{
+			name:        "allowed registry",
+			global:      Rules{AllowedRegistries: []string{"docker.io", "nvcr.io"}},
+			image:       "nvcr.io/nvidia/pytorch:23.10-py3",
+			wantAllowed: true,
+		},
+		{
+			name:      "repository not in provider allow list",
+			provider:  &Rules{AllowedRepositories: []string{"nvidia/*"}},
+			image:     "pytorch/pytorch:2.1",
+			wantScope: ScopeProvider,
+		},
+		{
+			name:        "official image matches familiar and full names",
+			global:      Rules{AllowedRepositories: []string{"docker.io/library/*"}},
+			image:       "ubuntu:22.04",
+			wantAllowed: true,
+		},
+		{
+			name:      "untagged image counts as latest",
+			global:    Rules{DisallowLatestTag: true},
+			image:     "nvidia/cuda",
+			wantScope: ScopeGlobal,
+		},
+		{
+			name:      "digest required",
+			provider:  &Rules{RequireDigest: true},
+			image:     "nvidia/cuda:11.8-base",
+			wantScope: ScopeProvider,
+		},
+		{
+			name:        "digest satisfies requirement",
+			provider:    &Rules{RequireDigest: true, DisallowLatestTag: true},
+			image:       "nvidia/cuda@" + digest,
+			wantAllowed: true,
+		},
+		{
+			name:        "within size limit",
+			global:      Rules{MaxSizeBytes: 4 << 30},
+			image:       "nvidia/cuda:11.8-base",
+			wantAllowed: true,
+		},
+		{
+			name:      "stricter provider size limit wins",
+			global:    Rules{MaxSizeBytes: 10 << 30},
+			provider:  &Rules{MaxSizeBytes: 4 << 30},
+			image:     "pytorch/pytorch:2.1",
+			wantScope: ScopeProvider,
+		},
+		{
+			name:      "unknown size is rejected when limited",
+			global:    Rules{MaxSizeBytes: 4 << 30},
+			image:     "unknown/image:1",
+			wantScope: ScopeGlobal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(tt.global, resolver)
+			decision := engine.Evaluate(context.Background(), tt.image, tt.provider)
+
+			if decision.Allowed != tt.wantAllowed {
+				t.Fatalf("expected allowed=%v, got %+v", tt.wantAllowed, decision)
+			}
+			if !decision.Allowed {
+				if decision.Scope != tt.wantScope {
+					t.Errorf("expected scope %s, got %s", tt.wantScope, decision.Scope)
+				}
+				if decision.Reason == "" {
+					t.Error("rejections should carry a reason")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package image_policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+// Manifest media types accepted from registries
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// sizeCacheTTL is how long resolved image sizes are reused
+const sizeCacheTTL = 10 * time.Minute
+
+// Response size limits, registries are named by renters and cannot be trusted to be small
+const (
+	maxManifestBytes = 4 << 20
+	maxTokenBytes    = 1 << 20
+)
+
+// dockerHubTokenHost issues the pull tokens registry-1.docker.io challenges for
+const dockerHubTokenHost = "auth.docker.io"
+
+// RegistrySizeResolver resolves image sizes from manifests served by the
+// Docker Registry HTTP API v2, using anonymous bearer tokens where required
+type RegistrySizeResolver struct {
+	client *http.Client
+	os     string
+	arch   string
+	// tokenHosts are token realm hosts trusted in addition to the registry's own host
+	tokenHosts map[string]bool
+
+	mu    sync.Mutex
+	cache map[string]cachedSize
+}
+
+type cachedSize struct {
+	size      int64
+	expiresAt time.Time
+}
+
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    struct {
+		Size int64 `json:"size"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// NewRegistrySizeResolver creates a resolver that sizes images for linux/amd64. Token realms
+// are only fetched over https from the registry's own host, Docker Hub's token service or one
+// of tokenHosts, and redirects are not followed, so a registry cannot point the resolver at
+// internal endpoints.
+func NewRegistrySizeResolver(timeout time.Duration, tokenHosts []string) *RegistrySizeResolver {
+	trusted := map[string]bool{dockerHubTokenHost: true}
+	for _, host := range tokenHosts {
+		trusted[strings.ToLower(host)] = true
+	}
+
+	return &RegistrySizeResolver{
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		os:         "linux",
+		arch:       "amd64",
+		tokenHosts: trusted,
+		cache:      make(map[string]cachedSize),
+	}
+}
+
+// ImageSize returns the compressed size of an image: its config blob plus all layers
+func (r *RegistrySizeResolver) ImageSize(ctx context.Context, ref reference.Named) (int64, error) {
+	key := ref.String()
+
+	r.mu.Lock()
+	if cached, ok := r.cache[key]; ok && time.Now().Before(cached.expiresAt) {
+		r.mu.Unlock()
+		return cached.size, nil
+	}
+	r.mu.Unlock()
+
+	tagOrDigest := "latest"
+	if digested, ok := ref.(reference.Digested); ok {
+		tagOrDigest = digested.Digest().String()
+	} else if tagged, ok := ref.(reference.Tagged); ok {
+		tagOrDigest = tagged.Tag()
+	}
+
+	m, err := r.fetchManifest(ctx, ref, tagOrDigest)
+	if err != nil {
+		return 0, err
+	}
+
+	// Multi-platform images point at one manifest per platform
+	if m.MediaType == mediaTypeDockerManifestList || m.MediaType == mediaTypeOCIIndex || len(m.Manifests) > 0 {
+		platformDigest := ""
+		for _, entry := range m.Manifests {
+			if entry.Platform.OS == r.os && entry.Platform.Architecture == r.arch {
+				platformDigest = entry.Digest
+				break
+			}
+		}
+		if platformDigest == "" {
+			return 0, fmt.Errorf("no %s/%s manifest found", r.os, r.arch)
+		}
+
+		if m, err = r.fetchManifest(ctx, ref, platformDigest); err != nil {
+			return 0, err
+		}
+	}
+
+	size := m.Config.Size
+	for _, layer := range m.Layers {
+		size += layer.Size
+	}
+
+	r.mu.Lock()
+	r.cache[key] = cachedSize{size: size, expiresAt: time.Now().Add(sizeCacheTTL)}
+	r.mu.Unlock()
+
+	return size, nil
+}
+
+// fetchManifest fetches a manifest, retrying once with a bearer token if challenged
+func (r *RegistrySizeResolver) fetchManifest(ctx context.Context, ref reference.Named, tagOrDigest string) (*manifest, error) {
+	host := reference.Domain(ref)
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, reference.Path(ref), tagOrDigest)
+
+	resp, err := r.getManifest(ctx, manifestURL, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		token, err := r.fetchToken(ctx, host, challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp, err = r.getManifest(ctx, manifestURL, token); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry returned %s for %s", resp.Status, manifestURL)
+	}
+
+	var m manifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestBytes)).Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if m.MediaType == "" {
+		m.MediaType = resp.Header.Get("Content-Type")
+	}
+
+	return &m, nil
+}
+
+// getManifest issues a manifest request accepting all supported manifest types
+func (r *RegistrySizeResolver) getManifest(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, manifestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for _, mediaType := range []string{mediaTypeDockerManifest, mediaTypeDockerManifestList, mediaTypeOCIManifest, mediaTypeOCIIndex} {
+		req.Header.Add("Accept", mediaType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	return resp, nil
+}
+
+// challengeParamPattern extracts key="value" pairs from a WWW-Authenticate header
+var challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// fetchToken obtains an anonymous pull token for a bearer challenge from registryHost
+func (r *RegistrySizeResolver) fetchToken(ctx context.Context, registryHost, challenge string) (string, error) {
+	params := make(map[string]string)
+	for _, match := range challengeParamPattern.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry requires authentication: %s", challenge)
+	}
+	realmURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %w", realm, err)
+	}
+	if realmURL.Scheme != "https" {
+		return "", fmt.Errorf("token realm %q must use https", realm)
+	}
+	if realmHost := strings.ToLower(realmURL.Host); realmHost != strings.ToLower(registryHost) && !r.tokenHosts[realmHost] {
+		return "", fmt.Errorf("token realm host %s is not the registry or a trusted token host", realmURL.Host)
+	}
+
+	query := url.Values{}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if scope := params["scope"]; scope != "" {
+		query.Set("scope", scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTokenBytes)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode registry token: %w", err)
+	}
+
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,100 @@
+package image_policy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+// newTestResolver returns a resolver that trusts the test server's certificate
+func newTestResolver(server *httptest.Server, tokenHosts []string) *RegistrySizeResolver {
+	resolver := NewRegistrySizeResolver(5*time.Second, tokenHosts)
+	resolver.client.Transport = server.Client().Transport
+	return resolver
+}
+
+func serverHost(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	return u.Host
+}
+
+func TestFetchToken_RealmRestrictions(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token":"pull-token"}`)
+	}))
+	defer server.Close()
+	host := serverHost(t, server)
+	resolver := newTestResolver(server, nil)
+	ctx := context.Background()
+
+	token, err := resolver.fetchToken(ctx, host, `Bearer realm="`+server.URL+`/token",service="registry"`)
+	if err != nil || token != "pull-token" {
+		t.Fatalf("expected token from the registry's own host, got %q, %v", token, err)
+	}
+
+	rejected := map[string]string{
+		"plain http":     `Bearer realm="http://` + host + `/token"`,
+		"other host":     `Bearer realm="` + server.URL + `/token"`,
+		"metadata host":  `Bearer realm="https://169.254.169.254/latest/meta-data"`,
+		"non-url scheme": `Bearer realm="file:///etc/passwd"`,
+	}
+	for name, challenge := range rejected {
+		registryHost := host
+		if name == "other host" {
+			registryHost = "registry.example"
+		}
+		if _, err := resolver.fetchToken(ctx, registryHost, challenge); err == nil {
+			t.Errorf("expected %s realm to be rejected", name)
+		}
+	}
+
+	trusted := newTestResolver(server, []string{host})
+	if _, err := trusted.fetchToken(ctx, "registry.example", `Bearer realm="`+server.URL+`/token"`); err != nil {
+		t.Errorf("expected realm on a trusted token host to be accepted, got %v", err)
+	}
+}
+
+func TestFetchManifest_Limits(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/manifests/redirect"):
+			http.Redirect(w, r, "https://169.254.169.254/latest/meta-data", http.StatusFound)
+		case strings.HasSuffix(r.URL.Path, "/manifests/huge"):
+			w.Header().Set("Content-Type", mediaTypeDockerManifest)
+			fmt.Fprintf(w, `{"mediaType":%q,"annotations":"%s"}`, mediaTypeDockerManifest, strings.Repeat("a", maxManifestBytes))
+		default:
+			w.Header().Set("Content-Type", mediaTypeDockerManifest)
+			fmt.Fprintf(w, `{"mediaType":%q,"config":{"size":10},"layers":[{"size":20}]}`, mediaTypeDockerManifest)
+		}
+	}))
+	defer server.Close()
+	resolver := newTestResolver(server, nil)
+
+	ref, err := reference.ParseNormalizedNamed(serverHost(t, server) + "/team/model")
+	if err != nil {
+		t.Fatalf("failed to parse reference: %v", err)
+	}
+	ctx := context.Background()
+
+	m, err := resolver.fetchManifest(ctx, ref, "v1")
+	if err != nil || len(m.Layers) != 1 {
+		t.Fatalf("expected manifest to be fetched, got %+v, %v", m, err)
+	}
+	if _, err := resolver.fetchManifest(ctx, ref, "redirect"); err == nil {
+		t.Error("expected a redirected manifest request not to be followed")
+	}
+	if _, err := resolver.fetchManifest(ctx, ref, "huge"); err == nil {
+		t.Error("expected an oversized manifest to be rejected")
+	}
+}
@@ -0,0 +1,48 @@
+package job_dispatcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// deadLetterJobPayload is the payload of dispatch and job_confirmed dead letters
+type deadLetterJobPayload struct {
+	JobID string `json:"job_id"`
+}
+
+// ValidateDeadLetterPayload checks that a payload can be replayed as the given kind of dead
+// letter and returns the job ID it refers to
+func ValidateDeadLetterPayload(kind DeadLetterKind, payload []byte) (string, error) {
+	switch kind {
+	case DeadLetterJobCreated:
+		var event JobCreatedEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return "", fmt.Errorf("payload is not a JobCreated event: %w", err)
+		}
+		if !jobIDPattern.MatchString(event.JobID) {
+			return "", fmt.Errorf("job_id must be a 0x-prefixed 32-byte hex string")
+		}
+		if !common.IsHexAddress(event.RenterAddress) {
+			return "", fmt.Errorf("renter_address is not a valid address")
+		}
+		if !common.IsHexAddress(event.ProviderAddress) {
+			return "", fmt.Errorf("provider_address is not a valid address")
+		}
+		return strings.ToLower(event.JobID), nil
+
+	case DeadLetterDispatch, DeadLetterJobConfirmed:
+		var job deadLetterJobPayload
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return "", fmt.Errorf("payload is not a job reference: %w", err)
+		}
+		if !jobIDPattern.MatchString(job.JobID) {
+			return "", fmt.Errorf("job_id must be a 0x-prefixed 32-byte hex string")
+		}
+		return strings.ToLower(job.JobID), nil
+	}
+
+	return "", fmt.Errorf("unknown dead letter kind %q", kind)
+}
@@ -0,0 +1,37 @@
+package job_dispatcher
+
+import (
+	"testing"
+)
+
+func TestValidateDeadLetterPayload(t *testing.T) {
+	jobID := "0x9A1B000000000000000000000000000000000000000000000000000000000001"
+
+	created := `{"job_id": "` + jobID + `", "renter_address": "` + testProvider + `", "provider_address": "` + testProvider + `", "payment_amount": "1000"}`
+	got, err := ValidateDeadLetterPayload(DeadLetterJobCreated, []byte(created))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "0x9a1b000000000000000000000000000000000000000000000000000000000001" {
+		t.Errorf("expected lowercased job ID, got %s", got)
+	}
+
+	if _, err := ValidateDeadLetterPayload(DeadLetterDispatch, []byte(`{"job_id": "`+jobID+`"}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := []struct {
+		kind    DeadLetterKind
+		payload string
+	}{
+		{DeadLetterJobCreated, `{"job_id": "` + jobID + `", "renter_address": "nope", "provider_address": "` + testProvider + `"}`},
+		{DeadLetterJobCreated, `not json`},
+		{DeadLetterJobConfirmed, `{"job_id": "0x1234"}`},
+		{"retry", `{"job_id": "` + jobID + `"}`},
+	}
+	for i, tc := range invalid {
+		if _, err := ValidateDeadLetterPayload(tc.kind, []byte(tc.payload)); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package job_dispatcher
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// AssignmentEncryptionECIES identifies assignments encrypted with ECIES over secp256k1
+// (AES-128-CTR with HMAC-SHA256, as implemented by go-ethereum's crypto/ecies)
+const AssignmentEncryptionECIES = "ecies-secp256k1"
+
+// EncryptedJobAssignment is a JobAssignment encrypted to the provider's wallet public key.
+// Only the job ID is visible to other subscribers of jobs.dispatch.>.
+type EncryptedJobAssignment struct {
+	JobID      string `json:"jobId"`
+	Encryption string `json:"encryption"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// EncryptAssignment encrypts an assignment to a provider's hex-encoded uncompressed public key
+func EncryptAssignment(assignment JobAssignment, publicKeyHex string) (*EncryptedJobAssignment, error) {
+	publicKey, err := parsePublicKey(publicKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := json.Marshal(assignment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal assignment: %w", err)
+	}
+
+	ciphertext, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(publicKey), plaintext, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt assignment: %w", err)
+	}
+
+	return &EncryptedJobAssignment{
+		JobID:      assignment.JobID,
+		Encryption: AssignmentEncryptionECIES,
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// DecryptAssignment decrypts an assignment with the provider's wallet private key
+func DecryptAssignment(encrypted EncryptedJobAssignment, privateKey *ecdsa.PrivateKey) (*JobAssignment, error) {
+	if encrypted.Encryption != AssignmentEncryptionECIES {
+		return nil, fmt.Errorf("unsupported assignment encryption: %s", encrypted.Encryption)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	plaintext, err := ecies.ImportECDSA(privateKey).Decrypt(ciphertext, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt assignment: %w", err)
+	}
+
+	var assignment JobAssignment
+	if err := json.Unmarshal(plaintext, &assignment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal assignment: %w", err)
+	}
+
+	if assignment.JobID != encrypted.JobID {
+		return nil, fmt.Errorf("encrypted assignment is for job %s, envelope says %s", assignment.JobID, encrypted.JobID)
+	}
+
+	return &assignment, nil
+}
+
+// parsePublicKey decodes a hex-encoded uncompressed secp256k1 public key
+func parsePublicKey(publicKeyHex string) (*ecdsa.PublicKey, error) {
+	raw, err := hexutil.Decode(publicKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key: %w", err)
+	}
+
+	publicKey, err := crypto.UnmarshalPubkey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	return publicKey, nil
+}
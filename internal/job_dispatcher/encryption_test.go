@@ -0,0 +1,45 @@
+package job_dispatcher
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestEncryptAssignment_RoundTrip(t *testing.T) {
+	providerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	publicKeyHex := hexutil.Encode(crypto.FromECDSAPub(&providerKey.PublicKey))
+
+	assignment := JobAssignment{
+		JobID:        "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		DockerImage:  "nvidia/cuda:11.8-base",
+		InputFileCID: "QmXabc123def456ghi789jkl012mno345pqr678stu901vwx234yz",
+		Command:      []string{"python", "train.py"},
+		Env:          map[string]string{"EPOCHS": "10"},
+	}
+
+	encrypted, err := EncryptAssignment(assignment, publicKeyHex)
+	if err != nil {
+		t.Fatalf("failed to encrypt assignment: %v", err)
+	}
+	if encrypted.JobID != assignment.JobID || encrypted.Encryption != AssignmentEncryptionECIES {
+		t.Errorf("unexpected envelope: %+v", encrypted)
+	}
+
+	decrypted, err := DecryptAssignment(*encrypted, providerKey)
+	if err != nil {
+		t.Fatalf("failed to decrypt assignment: %v", err)
+	}
+	if decrypted.DockerImage != assignment.DockerImage || decrypted.Env["EPOCHS"] != "10" || len(decrypted.Command) != 2 {
+		t.Errorf("decrypted assignment does not match: %+v", decrypted)
+	}
+
+	otherKey, _ := crypto.GenerateKey()
+	if _, err := DecryptAssignment(*encrypted, otherKey); err == nil {
+		t.Error("expected decryption with another provider's key to fail")
+	}
+}
@@ -0,0 +1,75 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// sha256Pattern matches a hex-encoded SHA-256 digest
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidateManifest checks a provider's result manifest and normalizes its digests
+func ValidateManifest(manifest *ResultManifest) error {
+	if len(manifest.Files) == 0 {
+		return fmt.Errorf("manifest must list at least one file")
+	}
+	if manifest.RuntimeSeconds < 0 {
+		return fmt.Errorf("runtime_seconds cannot be negative")
+	}
+	if manifest.PeakGPUMemoryMB < 0 {
+		return fmt.Errorf("peak_gpu_memory_mb cannot be negative")
+	}
+
+	paths := make(map[string]bool, len(manifest.Files))
+	for i := range manifest.Files {
+		file := &manifest.Files[i]
+		if strings.TrimSpace(file.Path) == "" {
+			return fmt.Errorf("file %d has no path", i+1)
+		}
+		if paths[file.Path] {
+			return fmt.Errorf("duplicate file path: %s", file.Path)
+		}
+		paths[file.Path] = true
+
+		if strings.TrimSpace(file.CID) == "" {
+			return fmt.Errorf("file %s has no cid", file.Path)
+		}
+		if file.Size < 0 {
+			return fmt.Errorf("file %s has a negative size", file.Path)
+		}
+
+		file.SHA256 = strings.ToLower(strings.TrimPrefix(file.SHA256, "sha256:"))
+		if !sha256Pattern.MatchString(file.SHA256) {
+			return fmt.Errorf("file %s: sha256 must be a hex-encoded SHA-256 digest", file.Path)
+		}
+
+		// Download links are derived from the gateway configuration, never trusted from providers
+		file.DownloadURL = ""
+	}
+
+	return nil
+}
+
+// WithDownloadURLs returns a copy of the manifest with each file linked through an IPFS gateway
+func (m ResultManifest) WithDownloadURLs(gatewayBaseURL string) ResultManifest {
+	files := make([]ResultFile, len(m.Files))
+	for i, file := range m.Files {
+		file.DownloadURL = DownloadURL(gatewayBaseURL, file.CID, file.Path)
+		files[i] = file
+	}
+	m.Files = files
+	return m
+}
+
+// DownloadURL builds a gateway link for a CID, asking the gateway to name the download
+// after the file's path
+func DownloadURL(gatewayBaseURL, cid, filePath string) string {
+	link := strings.TrimSuffix(gatewayBaseURL, "/") + "/" + url.PathEscape(cid)
+	if name := path.Base(filePath); name != "." && name != "/" {
+		link += "?filename=" + url.QueryEscape(name)
+	}
+	return link
+}
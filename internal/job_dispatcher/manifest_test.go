@@ -0,0 +1,57 @@
+package job_dispatcher
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateManifest(t *testing.T) {
+	digest := "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"
+
+	manifest := &ResultManifest{
+		Files: []ResultFile{
+			{Path: "checkpoints/model.pt", CID: "QmModel", Size: 1024, SHA256: "sha256:" + digest, DownloadURL: "https://evil.example/model.pt"},
+			{Path: "metrics.json", CID: "QmMetrics", Size: 12, SHA256: strings.ToLower(digest), MediaType: "application/json"},
+		},
+		ExitCode:       0,
+		RuntimeSeconds: 3600.5,
+	}
+
+	if err := ValidateManifest(manifest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manifest.Files[0].SHA256 != strings.ToLower(digest) {
+		t.Errorf("expected normalized digest, got %s", manifest.Files[0].SHA256)
+	}
+	if manifest.Files[0].DownloadURL != "" {
+		t.Error("expected provider-supplied download URL to be dropped")
+	}
+
+	invalid := []ResultManifest{
+		{},
+		{Files: []ResultFile{{Path: "a", CID: "Qm", SHA256: "abc"}}},
+		{Files: []ResultFile{{Path: "a", SHA256: strings.ToLower(digest)}}},
+		{Files: []ResultFile{{Path: "a", CID: "Qm1", SHA256: strings.ToLower(digest)}, {Path: "a", CID: "Qm2", SHA256: strings.ToLower(digest)}}},
+		{Files: []ResultFile{{Path: "a", CID: "Qm", SHA256: strings.ToLower(digest)}}, RuntimeSeconds: -1},
+	}
+	for i := range invalid {
+		if err := ValidateManifest(&invalid[i]); err == nil {
+			t.Errorf("manifest %d: expected error", i)
+		}
+	}
+}
+
+func TestDownloadURL(t *testing.T) {
+	tests := []struct {
+		base, cid, path, want string
+	}{
+		{"https://ipfs.io/ipfs", "QmModel", "checkpoints/model.pt", "https://ipfs.io/ipfs/QmModel?filename=model.pt"},
+		{"https://gateway.pinata.cloud/ipfs/", "QmLog", "logs/train log.txt", "https://gateway.pinata.cloud/ipfs/QmLog?filename=train+log.txt"},
+	}
+
+	for _, tt := range tests {
+		if got := DownloadURL(tt.base, tt.cid, tt.path); got != tt.want {
+			t.Errorf("DownloadURL(%q, %q, %q) = %q, want %q", tt.base, tt.cid, tt.path, got, tt.want)
+		}
+	}
+}
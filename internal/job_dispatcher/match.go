@@ -0,0 +1,206 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lamda_backend/internal/node_registry"
+)
+
+// Match score components
+const (
+	MatchFit        = "fit"
+	MatchReputation = "reputation"
+	MatchUptime     = "uptime"
+	MatchLoad       = "load"
+	MatchPrice      = "price"
+	MatchRegion     = "region"
+)
+
+// matchComponents lists the score components in the order they are reported
+var matchComponents = []string{MatchFit, MatchReputation, MatchUptime, MatchLoad, MatchPrice, MatchRegion}
+
+// Match tuning
+const (
+	// defaultMatchLimit and maxMatchLimit bound how many providers a match returns
+	defaultMatchLimit = 10
+	maxMatchLimit     = 50
+	// unknownMatchScore is given to providers without uptime history or a known price
+	unknownMatchScore = 0.5
+	// maxRegionDistanceKm is roughly half the Earth's circumference, the farthest two regions can be
+	maxRegionDistanceKm = 20000.0
+)
+
+// MatchWeights is how much each component counts towards a provider's match score
+type MatchWeights map[string]float64
+
+// DefaultMatchWeights weighs every component equally
+func DefaultMatchWeights() MatchWeights {
+	weights := make(MatchWeights, len(matchComponents))
+	for _, component := range matchComponents {
+		weights[component] = 1
+	}
+	return weights
+}
+
+// ParseMatchWeights parses "component:weight" entries over the default weights
+func ParseMatchWeights(entries []string) (MatchWeights, error) {
+	weights := DefaultMatchWeights()
+	for _, entry := range entries {
+		component, value, ok := strings.Cut(entry, ":")
+		if _, known := weights[component]; !ok || !known {
+			return nil, fmt.Errorf("invalid match weight %q, expected component:weight with component one of %s", entry, strings.Join(matchComponents, ", "))
+		}
+
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid match weight %q: weight must be a finite non-negative number", entry)
+		}
+
+		weights[component] = weight
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("at least one match weight must be positive")
+	}
+
+	return weights, nil
+}
+
+// matchCandidate is a provider that meets a match request's requirements, with what the
+// dispatcher knows about its load and price
+type matchCandidate struct {
+	provider    node_registry.Provider
+	issues      []RequirementIssue
+	activeJobs  int64
+	queuedJobs  int64
+	concurrency int
+	// rate is the wei per GPU-hour the provider charges, nil if unknown
+	rate       *big.Int
+	rateSource string
+}
+
+// scoreMatches scores candidates against a request and returns them best first. Reputation
+// and price are relative to the other candidates; the region component only counts when the
+// renter gives a region.
+func scoreMatches(candidates []matchCandidate, request MatchRequest, weights MatchWeights) []ProviderMatch {
+	var maxReputation int
+	var minRate *big.Int
+	for _, candidate := range candidates {
+		maxReputation = max(maxReputation, candidate.provider.ReputationScore)
+		if candidate.rate != nil && (minRate == nil || candidate.rate.Cmp(minRate) < 0) {
+			minRate = candidate.rate
+		}
+	}
+
+	matches := make([]ProviderMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		provider := candidate.provider
+		scores := map[string]float64{
+			MatchFit:        fitScore(provider, request.Requirements),
+			MatchReputation: 0,
+			MatchUptime:     unknownMatchScore,
+			MatchLoad:       loadScore(candidate.activeJobs, candidate.queuedJobs, candidate.concurrency),
+			MatchPrice:      unknownMatchScore,
+		}
+		if maxReputation > 0 {
+			scores[MatchReputation] = float64(provider.ReputationScore) / float64(maxReputation)
+		}
+		if provider.Uptime7d != nil {
+			scores[MatchUptime] = *provider.Uptime7d / 100
+		}
+		if candidate.rate != nil {
+			scores[MatchPrice] = priceScore(candidate.rate, minRate)
+		}
+		if request.Region != "" {
+			scores[MatchRegion] = 0
+			if distance, err := node_registry.RegionDistanceKm(request.Region, provider.Region); err == nil {
+				scores[MatchRegion] = max(0, 1-distance/maxRegionDistanceKm)
+			}
+		}
+
+		totalWeight := 0.0
+		for component := range scores {
+			totalWeight += weights[component]
+		}
+
+		match := ProviderMatch{
+			ProviderAddress: provider.WalletAddress,
+			GPUModel:        provider.GPUModel,
+			VRAM:            provider.VRAM,
+			ReputationScore: provider.ReputationScore,
+			Region:          provider.Region,
+			Country:         provider.Country,
+			RateSource:      candidate.rateSource,
+			Components:      make([]MatchComponent, 0, len(scores)),
+			Issues:          candidate.issues,
+		}
+		if candidate.rate != nil {
+			match.RateWeiPerHour = candidate.rate.String()
+		}
+		for _, component := range matchComponents {
+			score, ok := scores[component]
+			if !ok {
+				continue
+			}
+			contribution := 0.0
+			if totalWeight > 0 {
+				contribution = weights[component] * score / totalWeight
+			}
+			match.Components = append(match.Components, MatchComponent{
+				Name:         component,
+				Score:        score,
+				Weight:       weights[component],
+				Contribution: contribution,
+			})
+			match.Score += contribution
+		}
+
+		matches = append(matches, match)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ReputationScore > matches[j].ReputationScore
+	})
+
+	return matches
+}
+
+// fitScore rewards providers whose VRAM is close to what the job needs, so large GPUs stay
+// free for jobs that need them
+func fitScore(provider node_registry.Provider, requirements ResourceRequirements) float64 {
+	if requirements.MinVRAM <= 0 || provider.VRAM <= 0 {
+		return 1
+	}
+	return min(1, float64(requirements.MinVRAM)/float64(provider.VRAM))
+}
+
+// loadScore is the share of a provider's slots still free after its active and queued jobs.
+// Providers without a limit score lower the more jobs they have.
+func loadScore(active, queued int64, concurrency int) float64 {
+	busy := float64(active + queued)
+	if concurrency <= 0 {
+		return 1 / (1 + busy)
+	}
+	return max(0, 1-busy/float64(concurrency))
+}
+
+// priceScore is the cheapest candidate's rate relative to this one's
+func priceScore(rate, minRate *big.Int) float64 {
+	if rate.Sign() == 0 {
+		return 1
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(minRate), new(big.Float).SetInt(rate)).Float64()
+	return ratio
+}
@@ -0,0 +1,114 @@
+package job_dispatcher
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"lamda_backend/internal/node_registry"
+)
+
+func TestParseMatchWeights(t *testing.T) {
+	weights, err := ParseMatchWeights([]string{"price:2", "region:0"})
+	if err != nil {
+		t.Fatalf("expected weights to parse, got %v", err)
+	}
+	if weights[MatchPrice] != 2 || weights[MatchRegion] != 0 || weights[MatchFit] != 1 {
+		t.Errorf("unexpected weights %v", weights)
+	}
+
+	invalid := [][]string{
+		{"latency:1"},
+		{"price"},
+		{"price:-1"},
+		{"price:NaN"},
+		{"fit:Inf"},
+		{"fit:0", "reputation:0", "uptime:0", "load:0", "price:0", "region:0"},
+	}
+	for _, entries := range invalid {
+		if _, err := ParseMatchWeights(entries); err == nil {
+			t.Errorf("expected %v to be rejected", entries)
+		}
+	}
+}
+
+func TestScoreMatches(t *testing.T) {
+	uptime := 90.0
+	candidates := []matchCandidate{
+		{
+			// Oversized GPU, cheap, idle and nearby
+			provider:    node_registry.Provider{WalletAddress: "0xA", VRAM: 80, ReputationScore: 50, Uptime7d: &uptime, Region: "eu-west"},
+			concurrency: 2,
+			rate:        big.NewInt(100),
+		},
+		{
+			// Snug GPU, twice the price, half busy and far away, without uptime history
+			provider:    node_registry.Provider{WalletAddress: "0xB", VRAM: 24, ReputationScore: 100, Region: "oceania"},
+			activeJobs:  1,
+			concurrency: 2,
+			rate:        big.NewInt(200),
+		},
+	}
+	request := MatchRequest{Requirements: ResourceRequirements{MinVRAM: 24}}
+
+	matches := scoreMatches(candidates, request, DefaultMatchWeights())
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(matches))
+	}
+	components := func(match ProviderMatch) map[string]float64 {
+		scores := make(map[string]float64)
+		for _, component := range match.Components {
+			scores[component.Name] = component.Score
+		}
+		return scores
+	}
+
+	// Without a region, the region component is left out
+	a := components(matches[0])
+	if matches[0].ProviderAddress != "0xA" || len(a) != 5 {
+		t.Fatalf("expected 0xA first with 5 components, got %+v", matches[0])
+	}
+	if a[MatchFit] != 0.3 || a[MatchReputation] != 0.5 || a[MatchUptime] != 0.9 || a[MatchLoad] != 1 || a[MatchPrice] != 1 {
+		t.Errorf("unexpected scores for 0xA: %v", a)
+	}
+	b := components(matches[1])
+	if b[MatchFit] != 1 || b[MatchReputation] != 1 || b[MatchUptime] != 0.5 || b[MatchLoad] != 0.5 || b[MatchPrice] != 0.5 {
+		t.Errorf("unexpected scores for 0xB: %v", b)
+	}
+	if math.Abs(matches[0].Score-0.74) > 1e-9 || math.Abs(matches[1].Score-0.7) > 1e-9 {
+		t.Errorf("expected scores 0.74 and 0.7, got %v and %v", matches[0].Score, matches[1].Score)
+	}
+
+	// Weighting fit and reputation heavily puts the snug, reputable provider first
+	weights := DefaultMatchWeights()
+	weights[MatchFit] = 5
+	weights[MatchReputation] = 5
+	matches = scoreMatches(candidates, request, weights)
+	if matches[0].ProviderAddress != "0xB" {
+		t.Errorf("expected 0xB first with fit and reputation weighted, got %s", matches[0].ProviderAddress)
+	}
+
+	// A renter region adds the region component
+	request.Region = "eu-central"
+	matches = scoreMatches(candidates, request, DefaultMatchWeights())
+	near := components(matches[0])
+	if matches[0].ProviderAddress != "0xA" || near[MatchRegion] < 0.9 || len(near) != 6 {
+		t.Errorf("expected 0xA first and close to eu-central, got %+v", matches[0])
+	}
+	total := 0.0
+	for _, component := range matches[0].Components {
+		total += component.Contribution
+	}
+	if math.Abs(total-matches[0].Score) > 1e-9 {
+		t.Errorf("expected contributions to add up to the score, got %v and %v", total, matches[0].Score)
+	}
+}
+
+func TestLoadScore(t *testing.T) {
+	if got := loadScore(1, 2, 2); got != 0 {
+		t.Errorf("expected an oversubscribed provider to score 0, got %v", got)
+	}
+	if got := loadScore(1, 0, 0); got != 0.5 {
+		t.Errorf("expected an unlimited provider with one job to score 0.5, got %v", got)
+	}
+}
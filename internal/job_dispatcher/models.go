@@ -1,26 +1,97 @@
 package job_dispatcher
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // JobCreatedEvent represents the JobCreated event from the JobManager contract
 type JobCreatedEvent struct {
-	JobID           string `json:"job_id"`
-	RenterAddress   string `json:"renter_address"`
-	ProviderAddress string `json:"provider_address"`
-	DockerImage     string `json:"docker_image"`
-	InputFileCID    string `json:"input_file_cid"`
-	PaymentAmount   string `json:"payment_amount"`
-	BlockNumber     uint64 `json:"block_number"`
-	TransactionHash string `json:"transaction_hash"`
+	JobID           string               `json:"job_id"`
+	RenterAddress   string               `json:"renter_address"`
+	ProviderAddress string               `json:"provider_address"`
+	DockerImage     string               `json:"docker_image"`
+	InputFileCID    string               `json:"input_file_cid"`
+	Command         []string             `json:"command,omitempty"`
+	Env             map[string]string    `json:"env,omitempty"`
+	Secrets         map[string]string    `json:"secrets,omitempty"`
+	Requirements    ResourceRequirements `json:"requirements"`
+	RetryPolicy     *RetryPolicy         `json:"retry_policy,omitempty"`
+	WorkloadClass   WorkloadClass        `json:"workload_class,omitempty"`
+	Labels          map[string]string    `json:"labels,omitempty"`
+	PaymentAmount   string               `json:"payment_amount"`
+	BlockNumber     uint64               `json:"block_number"`
+	TransactionHash string               `json:"transaction_hash"`
 }
 
-// JobAssignment represents a job assignment sent to a provider via NATS
+// JobAssignment represents a job assignment sent to a provider via NATS.
+// The dispatcher signs the job ID, transaction hash, spec hash and expiry.
 type JobAssignment struct {
-	JobID        string `json:"jobId"`
-	DockerImage  string `json:"dockerImage"`
-	InputFileCID string `json:"inputFileCID"`
+	JobID           string            `json:"jobId"`
+	DockerImage     string            `json:"dockerImage"`
+	InputFileCID    string            `json:"inputFileCID"`
+	Command         []string          `json:"command,omitempty"`
+	Env             map[string]string `json:"env,omitempty"`
+	Secrets         map[string]string `json:"secrets,omitempty"`
+	TransactionHash string            `json:"txHash,omitempty"`
+	Attempt         int               `json:"attempt,omitempty"`
+	SpecHash        string            `json:"specHash,omitempty"`
+	ExpiresAt       int64             `json:"expiresAt,omitempty"`
+	Signature       string            `json:"signature,omitempty"`
+}
+
+// ResourceRequirements describes the hardware a job needs from its provider
+type ResourceRequirements struct {
+	MinVRAM        int    `json:"min_vram,omitempty"`
+	GPUModelFamily string `json:"gpu_model_family,omitempty"`
+	CUDAVersion    string `json:"cuda_version,omitempty"`
+}
+
+// JobSpec is the off-chain description of a job, registered by the renter before
+// the job is funded on-chain. The JobCreated event only carries the job ID, renter,
+// provider and payment, so the dispatcher joins the rest from here.
+type JobSpec struct {
+	JobID           string               `json:"job_id" gorm:"primaryKey"`
+	RenterAddress   string               `json:"renter_address" gorm:"index;not null"`
+	ProviderAddress string               `json:"provider_address" gorm:"not null"`
+	DockerImage     string               `json:"docker_image" gorm:"not null"`
+	InputFileCID    string               `json:"input_file_cid"`
+	Command         []string             `json:"command,omitempty" gorm:"serializer:json"`
+	Env             map[string]string    `json:"env,omitempty" gorm:"serializer:json"`
+	Secrets         map[string]string    `json:"secrets,omitempty" gorm:"-"`
+	Requirements    ResourceRequirements `json:"requirements" gorm:"serializer:json"`
+	RetryPolicy     *RetryPolicy         `json:"retry_policy,omitempty" gorm:"serializer:json"`
+	WorkloadClass   WorkloadClass        `json:"workload_class,omitempty"`
+	Labels          map[string]string    `json:"labels,omitempty" gorm:"serializer:json"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// JobSecret holds a job's secrets, each encrypted by the renter to the provider's public key.
+// It is kept apart from the spec so it can be purged once the job finishes.
+type JobSecret struct {
+	JobID           string            `json:"job_id" gorm:"primaryKey"`
+	ProviderAddress string            `json:"provider_address" gorm:"not null"`
+	Ciphertexts     map[string]string `json:"-" gorm:"serializer:json"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// TableName specifies the table name for the JobSecret model
+func (JobSecret) TableName() string {
+	return "job_secrets"
+}
+
+// TableName specifies the table name for the JobSpec model
+func (JobSpec) TableName() string {
+	return "job_specs"
+}
+
+// JobSpecResponse represents the response for a job spec submission
+type JobSpecResponse struct {
+	Spec   *JobSpec           `json:"spec,omitempty"`
+	Issues []RequirementIssue `json:"issues"`
+	Error  string             `json:"error,omitempty"`
 }
 
 // JobStatus represents the status of a job
@@ -28,6 +99,7 @@ type JobStatus string
 
 const (
 	JobStatusCreated   JobStatus = "created"
+	JobStatusQueued    JobStatus = "queued"
 	JobStatusAssigned  JobStatus = "assigned"
 	JobStatusRunning   JobStatus = "running"
 	JobStatusCompleted JobStatus = "completed"
@@ -37,33 +109,685 @@ const (
 
 // Job represents a job in the system
 type Job struct {
-	ID              string     `json:"id"`
-	RenterAddress   string     `json:"renter_address"`
+	ID              string `json:"id"`
+	RenterAddress   string `json:"renter_address"`
+	ProviderAddress string `json:"provider_address"`
+	DockerImage     string `json:"docker_image"`
+	InputFileCID    string `json:"input_file_cid"`
+	OutputFileCID   string `json:"output_file_cid,omitempty"`
+	OutputHash      string `json:"output_hash,omitempty"`
+	// ResultManifest lists the job's output files; it is only served to the renter by the
+	// results endpoint, never with the job itself
+	ResultManifest   *ResultManifest      `json:"-" gorm:"serializer:json"`
+	PaymentAmount    string               `json:"payment_amount"`
+	TransactionHash  string               `json:"transaction_hash"`
+	Status           JobStatus            `json:"status"`
+	Priority         int                  `json:"priority" gorm:"default:0"`
+	QueuePosition    int                  `json:"queue_position,omitempty" gorm:"-"`
+	RetryPolicy      *RetryPolicy         `json:"retry_policy,omitempty" gorm:"serializer:json"`
+	Attempts         int                  `json:"attempts" gorm:"default:0"`
+	NextRetryAt      *time.Time           `json:"next_retry_at,omitempty"`
+	WorkloadClass    WorkloadClass        `json:"workload_class,omitempty" gorm:"index"`
+	Labels           map[string]string    `json:"labels,omitempty" gorm:"type:jsonb;serializer:json;index:,type:gin"`
+	ChainID          string               `json:"chain_id" gorm:"index"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+	AssignedAt       *time.Time           `json:"assigned_at,omitempty"`
+	CompletedAt      *time.Time           `json:"completed_at,omitempty"`
+	FailedAt         *time.Time           `json:"failed_at,omitempty"`
+	ErrorMessage     string               `json:"error_message,omitempty"`
+	Requirements     ResourceRequirements `json:"requirements" gorm:"serializer:json"`
+	ValidationIssues []RequirementIssue   `json:"validation_issues,omitempty" gorm:"serializer:json"`
+}
+
+// IsTerminal reports whether a job has finished and no longer occupies a provider slot
+func (s JobStatus) IsTerminal() bool {
+	return s == JobStatusCompleted || s == JobStatusFailed || s == JobStatusCancelled
+}
+
+// JobStatusUpdate is published by providers on jobs.status.<jobId> as a job progresses, as
+// the payload of a request signed by the job's provider. Attempt is the dispatch attempt the
+// report is for, Timestamp is in unix milliseconds and Nonce is unique per report.
+type JobStatusUpdate struct {
+	JobID         string          `json:"jobId"`
+	Attempt       int             `json:"attempt"`
+	Timestamp     int64           `json:"timestamp"`
+	Nonce         string          `json:"nonce"`
+	Status        JobStatus       `json:"status"`
+	OutputFileCID string          `json:"outputFileCID,omitempty"`
+	OutputHash    string          `json:"outputHash,omitempty"`
+	Manifest      *ResultManifest `json:"manifest,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	ErrorClass    ErrorClass      `json:"errorClass,omitempty"`
+}
+
+// JobAttempt records one dispatch of a job to its provider and how it ended
+type JobAttempt struct {
+	JobID           string     `json:"job_id" gorm:"primaryKey"`
+	Attempt         int        `json:"attempt" gorm:"primaryKey;autoIncrement:false"`
 	ProviderAddress string     `json:"provider_address"`
-	DockerImage     string     `json:"docker_image"`
-	InputFileCID    string     `json:"input_file_cid"`
-	OutputFileCID   string     `json:"output_file_cid,omitempty"`
-	PaymentAmount   string     `json:"payment_amount"`
 	Status          JobStatus  `json:"status"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
-	AssignedAt      *time.Time `json:"assigned_at,omitempty"`
-	CompletedAt     *time.Time `json:"completed_at,omitempty"`
-	FailedAt        *time.Time `json:"failed_at,omitempty"`
+	ErrorClass      ErrorClass `json:"error_class,omitempty"`
 	ErrorMessage    string     `json:"error_message,omitempty"`
+	AssignedAt      time.Time  `json:"assigned_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds,omitempty"`
+}
+
+// TableName specifies the table name for the JobAttempt model
+func (JobAttempt) TableName() string {
+	return "job_attempts"
+}
+
+// JobAttemptsResponse represents the response for a job's attempt history
+type JobAttemptsResponse struct {
+	Attempts []JobAttempt `json:"attempts"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// JobStatusSubject returns the NATS subject providers report status for a job on
+func JobStatusSubject(jobID string) string {
+	return "jobs.status." + jobID
+}
+
+// JobLogStreamName is the JetStream stream that retains the recent tail of job logs
+const JobLogStreamName = "JOB_LOGS"
+
+// JobLogChunk represents a chunk of container output published by a provider, as the
+// payload of a request signed by the job's provider
+type JobLogChunk struct {
+	JobID     string    `json:"jobId"`
+	Stream    string    `json:"stream"`
+	Sequence  uint64    `json:"seq"`
+	Data      string    `json:"data"`
+	Timestamp time.Time `json:"timestamp"`
+	Final     bool      `json:"final,omitempty"`
+}
+
+// JobLogSubject returns the NATS subject providers publish log chunks for a job to
+func JobLogSubject(jobID string) string {
+	return "jobs.logs." + jobID
+}
+
+// TelemetrySample is a periodic hardware reading published by a provider on
+// jobs.telemetry.<jobId> while the job runs, signed like status reports. Utilizations are
+// percentages.
+type TelemetrySample struct {
+	JobID           string    `json:"jobId"`
+	Attempt         int       `json:"attempt"`
+	Timestamp       time.Time `json:"timestamp"`
+	Nonce           string    `json:"nonce"`
+	GPUUtilization  float64   `json:"gpuUtilization"`
+	VRAMUsedMB      float64   `json:"vramUsedMB"`
+	GPUTemperatureC float64   `json:"gpuTemperatureC"`
+	PowerDrawW      float64   `json:"powerDrawW"`
+	CPUUtilization  float64   `json:"cpuUtilization"`
+	RAMUsedMB       float64   `json:"ramUsedMB"`
+}
+
+// JobTelemetrySubject returns the NATS subject providers publish telemetry for a job to
+func JobTelemetrySubject(jobID string) string {
+	return "jobs.telemetry." + jobID
+}
+
+// JobMetricBucket holds the averages and peaks of a job's telemetry samples within one
+// downsampling window
+type JobMetricBucket struct {
+	JobID              string    `json:"-" gorm:"primaryKey"`
+	BucketStart        time.Time `json:"bucket_start" gorm:"primaryKey"`
+	Samples            int       `json:"samples"`
+	AvgGPUUtilization  float64   `json:"avg_gpu_utilization"`
+	MaxGPUUtilization  float64   `json:"max_gpu_utilization"`
+	AvgVRAMUsedMB      float64   `json:"avg_vram_used_mb"`
+	MaxVRAMUsedMB      float64   `json:"max_vram_used_mb"`
+	AvgGPUTemperatureC float64   `json:"avg_gpu_temperature_c"`
+	MaxGPUTemperatureC float64   `json:"max_gpu_temperature_c"`
+	AvgPowerDrawW      float64   `json:"avg_power_draw_w"`
+	MaxPowerDrawW      float64   `json:"max_power_draw_w"`
+	AvgCPUUtilization  float64   `json:"avg_cpu_utilization"`
+	AvgRAMUsedMB       float64   `json:"avg_ram_used_mb"`
+	MaxRAMUsedMB       float64   `json:"max_ram_used_mb"`
+}
+
+// TableName specifies the table name for the JobMetricBucket model
+func (JobMetricBucket) TableName() string {
+	return "job_metric_buckets"
+}
+
+// JobMetricsSummary describes a job's telemetry as a whole
+type JobMetricsSummary struct {
+	Samples               int     `json:"samples"`
+	AvgGPUUtilization     float64 `json:"avg_gpu_utilization"`
+	PeakVRAMUsedMB        float64 `json:"peak_vram_used_mb"`
+	PeakGPUTemperature    float64 `json:"peak_gpu_temperature_c"`
+	AvgPowerDrawW         float64 `json:"avg_power_draw_w"`
+	AvgCPUUtilization     float64 `json:"avg_cpu_utilization"`
+	PeakRAMUsedMB         float64 `json:"peak_ram_used_mb"`
+	GPUBound              bool    `json:"gpu_bound"`
+	RegisteredVRAMMB      int     `json:"registered_vram_mb,omitempty"`
+	VRAMExceedsRegistered bool    `json:"vram_exceeds_registered"`
+}
+
+// JobMetrics is a job's downsampled telemetry
+type JobMetrics struct {
+	JobID         string            `json:"job_id"`
+	BucketSeconds int               `json:"bucket_seconds"`
+	Summary       JobMetricsSummary `json:"summary"`
+	Buckets       []JobMetricBucket `json:"buckets"`
+}
+
+// JobMetricsResponse represents the response for a job metrics query
+type JobMetricsResponse struct {
+	Metrics *JobMetrics `json:"metrics,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// JobResultsResponse represents the response for a job results query. Manifest is nil until
+// the provider has reported one.
+type JobResultsResponse struct {
+	Manifest *ResultManifest `json:"manifest,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// OutboxMessage is a NATS message written in the same transaction as the state change it
+// announces. The outbox relay publishes it at least once and then marks it sent.
+type OutboxMessage struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	Subject   string     `json:"subject" gorm:"not null"`
+	Payload   string     `json:"payload" gorm:"type:text;not null"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty" gorm:"index"`
+}
+
+// TableName specifies the table name for the OutboxMessage model
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+// DeadLetterKind identifies the operation a dead letter failed in
+type DeadLetterKind string
+
+const (
+	// DeadLetterJobCreated entries hold a JobCreatedEvent that could not be processed
+	DeadLetterJobCreated DeadLetterKind = "job_created"
+	// DeadLetterDispatch entries hold a job that could not be dispatched to its provider
+	DeadLetterDispatch DeadLetterKind = "dispatch"
+	// DeadLetterJobConfirmed entries hold a JobConfirmed event that could not be applied
+	DeadLetterJobConfirmed DeadLetterKind = "job_confirmed"
+)
+
+// DeadLetterStatus represents where a dead letter is in its operator workflow
+type DeadLetterStatus string
+
+const (
+	DeadLetterStatusPending   DeadLetterStatus = "pending"
+	DeadLetterStatusReplayed  DeadLetterStatus = "replayed"
+	DeadLetterStatusResolved  DeadLetterStatus = "resolved"
+	DeadLetterStatusDiscarded DeadLetterStatus = "discarded"
+)
+
+// DeadLetter records a failed event or dispatch so operators can inspect, fix and replay it.
+// Repeated failures of the same operation update one pending entry.
+type DeadLetter struct {
+	ID            string           `json:"id" gorm:"primaryKey"`
+	Kind          DeadLetterKind   `json:"kind" gorm:"index;not null"`
+	JobID         string           `json:"job_id" gorm:"index"`
+	Payload       string           `json:"payload" gorm:"type:text"`
+	Error         string           `json:"error"`
+	Attempts      int              `json:"attempts"`
+	Status        DeadLetterStatus `json:"status" gorm:"index;not null"`
+	LastAttemptAt time.Time        `json:"last_attempt_at"`
+	ResolvedAt    *time.Time       `json:"resolved_at,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// TableName specifies the table name for the DeadLetter model
+func (DeadLetter) TableName() string {
+	return "dead_letters"
+}
+
+// DeadLetterRequest lists, updates, replays or discards dead letters. Payload replaces the
+// entry's payload when updating.
+type DeadLetterRequest struct {
+	ID      string           `json:"id,omitempty"`
+	Kind    DeadLetterKind   `json:"kind,omitempty"`
+	Status  DeadLetterStatus `json:"status,omitempty"`
+	Payload json.RawMessage  `json:"payload,omitempty"`
+	Limit   int              `json:"limit,omitempty"`
+	Offset  int              `json:"offset,omitempty"`
+}
+
+// DeadLetterResponse represents the response for dead letter requests
+type DeadLetterResponse struct {
+	Entry   *DeadLetter  `json:"entry,omitempty"`
+	Entries []DeadLetter `json:"entries,omitempty"`
+	Error   string       `json:"error,omitempty"`
 }
 
 // JobQuery represents a query for jobs
 type JobQuery struct {
+	JobID           string    `json:"job_id,omitempty"`
 	RenterAddress   string    `json:"renter_address,omitempty"`
 	ProviderAddress string    `json:"provider_address,omitempty"`
 	Status          JobStatus `json:"status,omitempty"`
-	Limit           int       `json:"limit,omitempty"`
-	Offset          int       `json:"offset,omitempty"`
+	// Labels matches jobs carrying every label; an empty value matches any value of the key
+	Labels map[string]string `json:"labels,omitempty"`
+	// DockerImage matches the exact image, or every tag and digest of a repository
+	DockerImage   string     `json:"docker_image,omitempty"`
+	CreatedFrom   *time.Time `json:"created_from,omitempty"`
+	CreatedTo     *time.Time `json:"created_to,omitempty"`
+	CompletedFrom *time.Time `json:"completed_from,omitempty"`
+	CompletedTo   *time.Time `json:"completed_to,omitempty"`
+	// MinPayment and MaxPayment bound the escrowed payment in wei
+	MinPayment string `json:"min_payment,omitempty"`
+	MaxPayment string `json:"max_payment,omitempty"`
+	ChainID    string `json:"chain_id,omitempty"`
+	// Search matches text in the docker image or error message
+	Search    string `json:"search,omitempty"`
+	SortBy    string `json:"sort_by,omitempty"`
+	SortOrder string `json:"sort_order,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+}
+
+// JobLabelsRequest replaces the labels of a renter's job
+type JobLabelsRequest struct {
+	JobID         string            `json:"job_id"`
+	RenterAddress string            `json:"renter_address"`
+	Labels        map[string]string `json:"labels"`
+}
+
+// JobLabelsResponse represents the response for a job labels update
+type JobLabelsResponse struct {
+	Job   *Job   `json:"job,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// QuoteRequest asks for suggested providers and estimated costs for a job spec
+type QuoteRequest struct {
+	DockerImage   string               `json:"docker_image"`
+	Requirements  ResourceRequirements `json:"requirements"`
+	WorkloadClass WorkloadClass        `json:"workload_class"`
+	Limit         int                  `json:"limit,omitempty"`
+}
+
+// ProviderQuote is one suggested provider with its estimated runtime and cost in wei.
+// The low and high costs price the p10 and p90 runtimes.
+type ProviderQuote struct {
+	ProviderAddress  string             `json:"provider_address"`
+	GPUModel         string             `json:"gpu_model"`
+	VRAM             int                `json:"vram"`
+	ReputationScore  int                `json:"reputation_score"`
+	EstimatedRuntime RuntimeEstimate    `json:"estimated_runtime"`
+	RateWeiPerHour   string             `json:"rate_wei_per_hour"`
+	RateSource       string             `json:"rate_source"`
+	EstimatedCostWei string             `json:"estimated_cost_wei"`
+	CostLowWei       string             `json:"cost_low_wei"`
+	CostHighWei      string             `json:"cost_high_wei"`
+	Confidence       string             `json:"confidence"`
+	Issues           []RequirementIssue `json:"issues"`
+}
+
+// QuoteResponse represents the response for a quote request
+type QuoteResponse struct {
+	Quotes []ProviderQuote `json:"quotes"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// MatchRequest describes a job a renter wants providers recommended for. Region is the
+// renter's own region, used to favour nearby providers.
+type MatchRequest struct {
+	DockerImage   string               `json:"docker_image,omitempty"`
+	Requirements  ResourceRequirements `json:"requirements"`
+	WorkloadClass WorkloadClass        `json:"workload_class,omitempty"`
+	Region        string               `json:"region,omitempty"`
+	Limit         int                  `json:"limit,omitempty"`
+}
+
+// MatchComponent is one part of a provider's match score. Score is between 0 and 1, and the
+// contributions of a match's components add up to its score.
+type MatchComponent struct {
+	Name         string  `json:"name"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// ProviderMatch is one recommended provider with its overall score between 0 and 1
+type ProviderMatch struct {
+	ProviderAddress string             `json:"provider_address"`
+	GPUModel        string             `json:"gpu_model"`
+	VRAM            int                `json:"vram"`
+	ReputationScore int                `json:"reputation_score"`
+	Region          string             `json:"region,omitempty"`
+	Country         string             `json:"country,omitempty"`
+	RateWeiPerHour  string             `json:"rate_wei_per_hour,omitempty"`
+	RateSource      string             `json:"rate_source,omitempty"`
+	Score           float64            `json:"score"`
+	Components      []MatchComponent   `json:"components"`
+	Issues          []RequirementIssue `json:"issues"`
+}
+
+// MatchResponse represents the response for a match request
+type MatchResponse struct {
+	Matches []ProviderMatch `json:"matches"`
+	Error   string          `json:"error,omitempty"`
 }
 
 // JobsResponse represents the response for jobs query
 type JobsResponse struct {
-	Jobs  []Job `json:"jobs"`
-	Count int   `json:"count"`
+	Jobs  []Job  `json:"jobs"`
+	Count int    `json:"count"`
+	Error string `json:"error,omitempty"`
+}
+
+// PipelineStatus represents the aggregate status of a pipeline's stages
+type PipelineStatus string
+
+const (
+	PipelineStatusPending   PipelineStatus = "pending"
+	PipelineStatusRunning   PipelineStatus = "running"
+	PipelineStatusCompleted PipelineStatus = "completed"
+	PipelineStatusFailed    PipelineStatus = "failed"
+)
+
+// StageStatus represents the status of a single pipeline stage
+type StageStatus string
+
+const (
+	// StageStatusPending stages are waiting for the stages they depend on
+	StageStatusPending StageStatus = "pending"
+	// StageStatusReady stages have their input CID and can be funded on-chain
+	StageStatusReady StageStatus = "ready"
+	// StageStatusSubmitted stages have a job spec registered under a job ID
+	StageStatusSubmitted StageStatus = "submitted"
+	StageStatusCompleted StageStatus = "completed"
+	StageStatusFailed    StageStatus = "failed"
+)
+
+// Pipeline is a DAG of job specs registered by a renter, where each stage's output CID
+// becomes the input of the stages that depend on it
+type Pipeline struct {
+	ID            string          `json:"id" gorm:"primaryKey"`
+	RenterAddress string          `json:"renter_address" gorm:"index;not null"`
+	Name          string          `json:"name"`
+	Status        PipelineStatus  `json:"status"`
+	Stages        []PipelineStage `json:"stages" gorm:"foreignKey:PipelineID"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Pipeline model
+func (Pipeline) TableName() string {
+	return "pipelines"
+}
+
+// PipelineStage is one job spec in a pipeline. InputFrom names the dependency whose output
+// feeds this stage and may be omitted when the stage has a single dependency.
+type PipelineStage struct {
+	ID              uint                 `json:"-" gorm:"primaryKey"`
+	PipelineID      string               `json:"-" gorm:"uniqueIndex:idx_pipeline_stage_name;not null"`
+	Name            string               `json:"name" gorm:"uniqueIndex:idx_pipeline_stage_name;not null"`
+	DependsOn       []string             `json:"depends_on,omitempty" gorm:"serializer:json"`
+	InputFrom       string               `json:"input_from,omitempty"`
+	ProviderAddress string               `json:"provider_address" gorm:"not null"`
+	DockerImage     string               `json:"docker_image" gorm:"not null"`
+	InputFileCID    string               `json:"input_file_cid,omitempty"`
+	Command         []string             `json:"command,omitempty" gorm:"serializer:json"`
+	Env             map[string]string    `json:"env,omitempty" gorm:"serializer:json"`
+	Requirements    ResourceRequirements `json:"requirements" gorm:"serializer:json"`
+	Status          StageStatus          `json:"status"`
+	JobID           string               `json:"job_id,omitempty" gorm:"index"`
+	OutputFileCID   string               `json:"output_file_cid,omitempty"`
+	ErrorMessage    string               `json:"error_message,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// TableName specifies the table name for the PipelineStage model
+func (PipelineStage) TableName() string {
+	return "pipeline_stages"
+}
+
+// PipelineStageSubmission registers the job spec for a ready stage under the job ID the
+// renter will fund on-chain
+type PipelineStageSubmission struct {
+	PipelineID    string `json:"pipeline_id"`
+	Stage         string `json:"stage"`
+	JobID         string `json:"job_id"`
+	RenterAddress string `json:"renter_address"`
+}
+
+// PipelineQuery represents a lookup of a single pipeline
+type PipelineQuery struct {
+	PipelineID string `json:"pipeline_id"`
+}
+
+// PipelineResponse represents the response for pipeline requests
+type PipelineResponse struct {
+	Pipeline *Pipeline `json:"pipeline,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// JobTemplate is a saved job a renter reruns, optionally on a cron schedule. Each run
+// registers a fresh job spec and asks the renter, or its delegated signer, to fund it.
+type JobTemplate struct {
+	ID                 string               `json:"id" gorm:"primaryKey"`
+	RenterAddress      string               `json:"renter_address" gorm:"index;not null"`
+	Name               string               `json:"name" gorm:"not null"`
+	DockerImage        string               `json:"docker_image" gorm:"not null"`
+	InputFileCID       string               `json:"input_file_cid"`
+	Command            []string             `json:"command,omitempty" gorm:"serializer:json"`
+	Env                map[string]string    `json:"env,omitempty" gorm:"serializer:json"`
+	Requirements       ResourceRequirements `json:"requirements" gorm:"serializer:json"`
+	PreferredProviders []string             `json:"preferred_providers" gorm:"serializer:json"`
+	PaymentAmount      string               `json:"payment_amount" gorm:"not null"`
+	Schedule           string               `json:"schedule,omitempty"`
+	DelegateAddress    string               `json:"delegate_address,omitempty"`
+	Paused             bool                 `json:"paused"`
+	LastRunAt          *time.Time           `json:"last_run_at,omitempty"`
+	CreatedAt          time.Time            `json:"created_at"`
+	UpdatedAt          time.Time            `json:"updated_at"`
+}
+
+// TableName specifies the table name for the JobTemplate model
+func (JobTemplate) TableName() string {
+	return "job_templates"
+}
+
+// UnsignedTransaction is a transaction the renter's wallet signs and sends as-is
+type UnsignedTransaction struct {
+	ChainID string `json:"chain_id"`
+	To      string `json:"to"`
+	Value   string `json:"value"`
+	Data    string `json:"data"`
+}
+
+// JobTemplateRun is one instantiation of a template, waiting to be funded on-chain
+type JobTemplateRun struct {
+	JobID           string              `json:"job_id" gorm:"primaryKey"`
+	TemplateID      string              `json:"template_id" gorm:"index;not null"`
+	RenterAddress   string              `json:"renter_address" gorm:"index;not null"`
+	SignerAddress   string              `json:"signer_address" gorm:"not null"`
+	ProviderAddress string              `json:"provider_address" gorm:"not null"`
+	Transaction     UnsignedTransaction `json:"transaction" gorm:"serializer:json"`
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+// TableName specifies the table name for the JobTemplateRun model
+func (JobTemplateRun) TableName() string {
+	return "job_template_runs"
+}
+
+// RenterNotificationSubject returns the NATS subject renter-facing notifications are published on
+func RenterNotificationSubject(address string) string {
+	return "renters.notifications." + address
+}
+
+// JobFundingRequest notifies a renter, or its delegated signer, that a template run is
+// ready to be funded by sending Transaction
+type JobFundingRequest struct {
+	Type         string              `json:"type"`
+	TemplateID   string              `json:"template_id"`
+	TemplateName string              `json:"template_name"`
+	JobID        string              `json:"job_id"`
+	Spec         JobSpec             `json:"spec"`
+	Transaction  UnsignedTransaction `json:"transaction"`
+	Issues       []RequirementIssue  `json:"issues,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// JobTemplateRequest identifies a template acted on by its renter
+type JobTemplateRequest struct {
+	TemplateID    string `json:"template_id,omitempty"`
+	RenterAddress string `json:"renter_address"`
+}
+
+// JobTemplateResponse represents the response for template requests
+type JobTemplateResponse struct {
+	Template  *JobTemplate     `json:"template,omitempty"`
+	Templates []JobTemplate    `json:"templates,omitempty"`
+	Run       *JobTemplateRun  `json:"run,omitempty"`
+	Runs      []JobTemplateRun `json:"runs,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// VerificationStatus represents whether the providers in a verification group agreed
+type VerificationStatus string
+
+const (
+	VerificationStatusPending   VerificationStatus = "pending"
+	VerificationStatusAgreed    VerificationStatus = "agreed"
+	VerificationStatusDisagreed VerificationStatus = "disagreed"
+)
+
+// VerificationOutcome is a single provider's result within a verification group
+type VerificationOutcome string
+
+const (
+	VerificationOutcomePending VerificationOutcome = "pending"
+	// VerificationOutcomeMatched providers reported the consensus output hash
+	VerificationOutcomeMatched VerificationOutcome = "matched"
+	// VerificationOutcomeMismatched providers reported a hash other than the consensus
+	VerificationOutcomeMismatched VerificationOutcome = "mismatched"
+	// VerificationOutcomeMissing providers failed the job, reported no hash or did not finish
+	// by the group's deadline
+	VerificationOutcomeMissing VerificationOutcome = "missing"
+	// VerificationOutcomeInconclusive providers reported a hash but no consensus was reached
+	VerificationOutcomeInconclusive VerificationOutcome = "inconclusive"
+)
+
+// VerificationGroup links jobs running the same spec on different providers so their
+// output hashes can be compared. Groups still pending at their deadline are resolved with
+// the members that have finished.
+type VerificationGroup struct {
+	ID            string               `json:"id" gorm:"primaryKey"`
+	RenterAddress string               `json:"renter_address" gorm:"index;not null"`
+	Quorum        int                  `json:"quorum" gorm:"not null"`
+	Status        VerificationStatus   `json:"status"`
+	ConsensusHash string               `json:"consensus_hash,omitempty"`
+	Deadline      *time.Time           `json:"deadline,omitempty" gorm:"index"`
+	Members       []VerificationMember `json:"members" gorm:"foreignKey:GroupID"`
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	ResolvedAt    *time.Time           `json:"resolved_at,omitempty"`
+}
+
+// TableName specifies the table name for the VerificationGroup model
+func (VerificationGroup) TableName() string {
+	return "verification_groups"
+}
+
+// VerificationMember is one provider's job within a verification group
+type VerificationMember struct {
+	ID              uint                `json:"-" gorm:"primaryKey"`
+	GroupID         string              `json:"-" gorm:"index;not null"`
+	JobID           string              `json:"job_id" gorm:"uniqueIndex;not null"`
+	ProviderAddress string              `json:"provider_address" gorm:"index;not null"`
+	OutputHash      string              `json:"output_hash,omitempty"`
+	Outcome         VerificationOutcome `json:"outcome"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+// TableName specifies the table name for the VerificationMember model
+func (VerificationMember) TableName() string {
+	return "verification_members"
+}
+
+// VerificationJob is a job ID and provider the renter will fund as part of a verification group
+type VerificationJob struct {
+	JobID           string `json:"job_id"`
+	ProviderAddress string `json:"provider_address"`
+}
+
+// VerificationRequest registers the same spec under several job IDs, one per provider.
+// Quorum defaults to a simple majority and TimeoutSeconds, how long the jobs have to finish
+// before the group is resolved without them, to a day.
+type VerificationRequest struct {
+	RenterAddress  string            `json:"renter_address"`
+	Spec           JobSpec           `json:"spec"`
+	Jobs           []VerificationJob `json:"jobs"`
+	Quorum         int               `json:"quorum,omitempty"`
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"`
+}
+
+// VerificationQuery represents a lookup of a verification group or a provider's outcomes
+type VerificationQuery struct {
+	GroupID         string `json:"group_id,omitempty"`
+	ProviderAddress string `json:"provider_address,omitempty"`
+}
+
+// VerificationResponse represents the response for verification requests
+type VerificationResponse struct {
+	Group *VerificationGroup `json:"group,omitempty"`
+	Error string             `json:"error,omitempty"`
+}
+
+// VerificationResult is published on verification.results.<provider> for each provider
+// once its group is resolved
+type VerificationResult struct {
+	GroupID         string              `json:"group_id"`
+	JobID           string              `json:"job_id"`
+	ProviderAddress string              `json:"provider_address"`
+	Outcome         VerificationOutcome `json:"outcome"`
+	OutputHash      string              `json:"output_hash,omitempty"`
+	ConsensusHash   string              `json:"consensus_hash,omitempty"`
+	GroupStatus     VerificationStatus  `json:"group_status"`
+}
+
+// VerificationResultSubject returns the NATS subject a provider's verification results are published on
+func VerificationResultSubject(providerAddress string) string {
+	return "verification.results." + providerAddress
+}
+
+// ProviderVerificationStats counts a provider's verification outcomes
+type ProviderVerificationStats struct {
+	ProviderAddress string `json:"provider_address"`
+	Matched         int64  `json:"matched"`
+	Mismatched      int64  `json:"mismatched"`
+	Missing         int64  `json:"missing"`
+	Inconclusive    int64  `json:"inconclusive"`
+}
+
+// ResultFile is one output file of a job, uploaded to IPFS by the provider
+type ResultFile struct {
+	Path        string `json:"path"`
+	CID         string `json:"cid"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	MediaType   string `json:"media_type,omitempty"`
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// ResultManifest describes every output of a job and how its container exited
+type ResultManifest struct {
+	Files           []ResultFile `json:"files"`
+	ExitCode        int          `json:"exit_code"`
+	RuntimeSeconds  float64      `json:"runtime_seconds"`
+	PeakGPUMemoryMB int          `json:"peak_gpu_memory_mb,omitempty"`
 }
@@ -0,0 +1,69 @@
+package job_dispatcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// outboxPublisher is the part of the NATS client the outbox relay publishes through
+type outboxPublisher interface {
+	Publish(subject string, data interface{}) error
+	Flush(timeout time.Duration) error
+}
+
+// outboxUpdate is the change a relay attempt records on an outbox row
+type outboxUpdate struct {
+	id     uint
+	sent   bool
+	fields map[string]interface{}
+}
+
+// publishOutbox publishes a batch of outbox messages in order and returns the row updates to
+// record: sent_at on each message the server confirmed, and last_error on the first message
+// that was not. Every attempted message has its attempts counted.
+func publishOutbox(publisher outboxPublisher, messages []OutboxMessage, now time.Time) []outboxUpdate {
+	// Keep later messages behind a failed one so subscribers see them in order
+	published := 0
+	var publishErr error
+	for _, message := range messages {
+		if publishErr = publisher.Publish(message.Subject, json.RawMessage(message.Payload)); publishErr != nil {
+			break
+		}
+		published++
+	}
+
+	// Publishing only buffers messages in the client, so nothing counts as sent until the
+	// server confirms it has them
+	if published > 0 {
+		if err := publisher.Flush(outboxFlushTimeout); err != nil {
+			publishErr = fmt.Errorf("failed to flush outbox messages: %w", err)
+			published = 0
+		}
+	}
+
+	var updates []outboxUpdate
+	for _, message := range messages[:published] {
+		updates = append(updates, outboxUpdate{
+			id:   message.ID,
+			sent: true,
+			fields: map[string]interface{}{
+				"attempts": message.Attempts + 1,
+				"sent_at":  now,
+			},
+		})
+	}
+
+	if publishErr != nil {
+		failed := messages[published]
+		updates = append(updates, outboxUpdate{
+			id: failed.ID,
+			fields: map[string]interface{}{
+				"attempts":   failed.Attempts + 1,
+				"last_error": publishErr.Error(),
+			},
+		})
+	}
+
+	return updates
+}
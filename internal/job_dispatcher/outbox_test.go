@@ -0,0 +1,116 @@
+package job_dispatcher
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakePublisher records published subjects, failing the publish of failSubject and every
+// flush when flushErr is set
+type fakePublisher struct {
+	published   []string
+	failSubject string
+	flushErr    error
+	flushes     int
+}
+
+func (p *fakePublisher) Publish(subject string, data interface{}) error {
+	if subject == p.failSubject {
+		return errors.New("connection closed")
+	}
+	p.published = append(p.published, subject)
+	return nil
+}
+
+func (p *fakePublisher) Flush(timeout time.Duration) error {
+	p.flushes++
+	return p.flushErr
+}
+
+func outboxBatch() []OutboxMessage {
+	return []OutboxMessage{
+		{ID: 1, Subject: "jobs.assign.a", Payload: `{}`},
+		{ID: 2, Subject: "jobs.assign.b", Payload: `{}`, Attempts: 2},
+		{ID: 3, Subject: "jobs.assign.c", Payload: `{}`},
+	}
+}
+
+func TestPublishOutbox_PublishesInOrder(t *testing.T) {
+	publisher := &fakePublisher{}
+	now := time.Now()
+
+	updates := publishOutbox(publisher, outboxBatch(), now)
+
+	want := []string{"jobs.assign.a", "jobs.assign.b", "jobs.assign.c"}
+	if len(publisher.published) != len(want) {
+		t.Fatalf("expected %d messages published, got %v", len(want), publisher.published)
+	}
+	for i, subject := range want {
+		if publisher.published[i] != subject {
+			t.Errorf("position %d: expected %s, got %s", i+1, subject, publisher.published[i])
+		}
+	}
+	if publisher.flushes != 1 {
+		t.Errorf("expected one flush, got %d", publisher.flushes)
+	}
+
+	if len(updates) != len(want) {
+		t.Fatalf("expected %d updates, got %+v", len(want), updates)
+	}
+	for i, update := range updates {
+		if !update.sent || update.id != uint(i+1) || update.fields["sent_at"] != now {
+			t.Errorf("expected message %d marked sent, got %+v", i+1, update)
+		}
+	}
+	if updates[1].fields["attempts"] != 3 {
+		t.Errorf("expected attempts to be counted, got %v", updates[1].fields["attempts"])
+	}
+}
+
+func TestPublishOutbox_HoldsMessagesBehindFailedPublish(t *testing.T) {
+	publisher := &fakePublisher{failSubject: "jobs.assign.b"}
+
+	updates := publishOutbox(publisher, outboxBatch(), time.Now())
+
+	if len(publisher.published) != 1 || publisher.published[0] != "jobs.assign.a" {
+		t.Fatalf("expected only the message ahead of the failure to be published, got %v", publisher.published)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("expected 2 updates, got %+v", updates)
+	}
+	if !updates[0].sent || updates[0].id != 1 {
+		t.Errorf("expected message 1 marked sent, got %+v", updates[0])
+	}
+
+	failed := updates[1]
+	if failed.sent || failed.id != 2 {
+		t.Fatalf("expected message 2 to be recorded as failed, got %+v", failed)
+	}
+	if _, ok := failed.fields["sent_at"]; ok {
+		t.Error("expected the failed message not to be marked sent")
+	}
+	if failed.fields["attempts"] != 3 || failed.fields["last_error"] != "connection closed" {
+		t.Errorf("expected attempts and last_error on the failed message, got %v", failed.fields)
+	}
+}
+
+func TestPublishOutbox_NothingSentWhenFlushFails(t *testing.T) {
+	publisher := &fakePublisher{flushErr: errors.New("flush timeout")}
+
+	updates := publishOutbox(publisher, outboxBatch(), time.Now())
+
+	if len(updates) != 1 {
+		t.Fatalf("expected only the failure to be recorded, got %+v", updates)
+	}
+	failed := updates[0]
+	if failed.sent || failed.id != 1 {
+		t.Fatalf("expected the first message to be recorded as failed, got %+v", failed)
+	}
+	if _, ok := failed.fields["sent_at"]; ok {
+		t.Error("expected no message to be marked sent")
+	}
+	if failed.fields["attempts"] != 1 || failed.fields["last_error"] != "failed to flush outbox messages: flush timeout" {
+		t.Errorf("expected attempts and last_error on the first message, got %v", failed.fields)
+	}
+}
@@ -0,0 +1,178 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidatePipeline checks that a pipeline's stages form a DAG with resolvable inputs
+func ValidatePipeline(stages []PipelineStage) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("pipeline must have at least one stage")
+	}
+
+	byName := make(map[string]*PipelineStage, len(stages))
+	for i := range stages {
+		stage := &stages[i]
+		if strings.TrimSpace(stage.Name) == "" {
+			return fmt.Errorf("stage %d has no name", i+1)
+		}
+		if _, ok := byName[stage.Name]; ok {
+			return fmt.Errorf("duplicate stage name: %s", stage.Name)
+		}
+		if strings.TrimSpace(stage.DockerImage) == "" {
+			return fmt.Errorf("stage %s: docker_image is required", stage.Name)
+		}
+		if !common.IsHexAddress(stage.ProviderAddress) {
+			return fmt.Errorf("stage %s: provider_address is not a valid address", stage.Name)
+		}
+		byName[stage.Name] = stage
+	}
+
+	for _, stage := range stages {
+		for _, dep := range stage.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("stage %s depends on unknown stage %s", stage.Name, dep)
+			}
+			if dep == stage.Name {
+				return fmt.Errorf("stage %s depends on itself", stage.Name)
+			}
+		}
+
+		if stage.InputFrom != "" && !containsString(stage.DependsOn, stage.InputFrom) {
+			return fmt.Errorf("stage %s takes input from %s, which it does not depend on", stage.Name, stage.InputFrom)
+		}
+		if stage.InputFrom == "" && len(stage.DependsOn) > 1 {
+			return fmt.Errorf("stage %s has several dependencies and must set input_from", stage.Name)
+		}
+	}
+
+	if _, err := topologicalOrder(stages); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// topologicalOrder returns stage names so that every stage follows its dependencies
+func topologicalOrder(stages []PipelineStage) ([]string, error) {
+	remaining := make(map[string]int, len(stages))
+	dependents := make(map[string][]string)
+	for _, stage := range stages {
+		remaining[stage.Name] = len(stage.DependsOn)
+		for _, dep := range stage.DependsOn {
+			dependents[dep] = append(dependents[dep], stage.Name)
+		}
+	}
+
+	var ready, order []string
+	for _, stage := range stages {
+		if remaining[stage.Name] == 0 {
+			ready = append(ready, stage.Name)
+		}
+	}
+
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+
+		for _, dependent := range dependents[name] {
+			remaining[dependent]--
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) != len(stages) {
+		return nil, fmt.Errorf("pipeline stages contain a dependency cycle")
+	}
+
+	return order, nil
+}
+
+// inputSource returns the name of the stage whose output feeds the given stage
+func inputSource(stage PipelineStage) string {
+	if stage.InputFrom != "" {
+		return stage.InputFrom
+	}
+	if len(stage.DependsOn) == 1 {
+		return stage.DependsOn[0]
+	}
+	return ""
+}
+
+// promoteReadyStages moves pending stages whose dependencies have all completed to ready,
+// filling in the output CID of their input stage. It returns the names of promoted stages.
+func promoteReadyStages(stages []PipelineStage) []string {
+	byName := make(map[string]*PipelineStage, len(stages))
+	for i := range stages {
+		byName[stages[i].Name] = &stages[i]
+	}
+
+	var promoted []string
+	for i := range stages {
+		stage := &stages[i]
+		if stage.Status != StageStatusPending {
+			continue
+		}
+
+		ready := true
+		for _, dep := range stage.DependsOn {
+			if byName[dep].Status != StageStatusCompleted {
+				ready = false
+				break
+			}
+		}
+		if !ready {
+			continue
+		}
+
+		if source := inputSource(*stage); source != "" {
+			stage.InputFileCID = byName[source].OutputFileCID
+		}
+		stage.Status = StageStatusReady
+		promoted = append(promoted, stage.Name)
+	}
+
+	return promoted
+}
+
+// AggregatePipelineStatus derives a pipeline's status from its stages
+func AggregatePipelineStatus(stages []PipelineStage) PipelineStatus {
+	completed := 0
+	started := false
+	for _, stage := range stages {
+		switch stage.Status {
+		case StageStatusFailed:
+			return PipelineStatusFailed
+		case StageStatusCompleted:
+			completed++
+			started = true
+		case StageStatusSubmitted:
+			started = true
+		}
+	}
+
+	switch {
+	case len(stages) > 0 && completed == len(stages):
+		return PipelineStatusCompleted
+	case started:
+		return PipelineStatusRunning
+	default:
+		return PipelineStatusPending
+	}
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package job_dispatcher
+
+import (
+	"testing"
+)
+
+const testProvider = "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
+
+func stage(name string, dependsOn ...string) PipelineStage {
+	return PipelineStage{
+		Name:            name,
+		DependsOn:       dependsOn,
+		ProviderAddress: testProvider,
+		DockerImage:     "nvidia/cuda:11.8-base",
+	}
+}
+
+func TestValidatePipeline(t *testing.T) {
+	withInput := stage("report", "train", "evaluate")
+	withInput.InputFrom = "evaluate"
+
+	badInput := stage("report", "train")
+	badInput.InputFrom = "preprocess"
+
+	tests := []struct {
+		name    string
+		stages  []PipelineStage
+		wantErr bool
+	}{
+		{name: "linear", stages: []PipelineStage{stage("preprocess"), stage("train", "preprocess"), stage("evaluate", "train")}},
+		{name: "fan in with input_from", stages: []PipelineStage{stage("train"), stage("evaluate", "train"), withInput}},
+		{name: "empty", stages: nil, wantErr: true},
+		{name: "duplicate name", stages: []PipelineStage{stage("train"), stage("train")}, wantErr: true},
+		{name: "unknown dependency", stages: []PipelineStage{stage("train", "preprocess")}, wantErr: true},
+		{name: "self dependency", stages: []PipelineStage{stage("train", "train")}, wantErr: true},
+		{name: "cycle", stages: []PipelineStage{stage("a", "c"), stage("b", "a"), stage("c", "b")}, wantErr: true},
+		{name: "fan in without input_from", stages: []PipelineStage{stage("a"), stage("b"), stage("c", "a", "b")}, wantErr: true},
+		{name: "input_from not a dependency", stages: []PipelineStage{stage("preprocess"), stage("train"), badInput}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePipeline(tt.stages)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPromoteReadyStages(t *testing.T) {
+	stages := []PipelineStage{stage("preprocess"), stage("train", "preprocess"), stage("evaluate", "train")}
+	for i := range stages {
+		stages[i].Status = StageStatusPending
+	}
+
+	if promoted := promoteReadyStages(stages); len(promoted) != 1 || promoted[0] != "preprocess" {
+		t.Fatalf("expected only preprocess to be ready, got %v", promoted)
+	}
+	if got := AggregatePipelineStatus(stages); got != PipelineStatusPending {
+		t.Errorf("expected pending pipeline, got %s", got)
+	}
+
+	stages[0].Status = StageStatusCompleted
+	stages[0].OutputFileCID = "QmPreprocessed"
+
+	if promoted := promoteReadyStages(stages); len(promoted) != 1 || promoted[0] != "train" {
+		t.Fatalf("expected train to be ready, got %v", promoted)
+	}
+	if stages[1].InputFileCID != "QmPreprocessed" {
+		t.Errorf("expected train input to be the preprocess output, got %q", stages[1].InputFileCID)
+	}
+	if stages[2].Status != StageStatusPending {
+		t.Errorf("expected evaluate to stay pending, got %s", stages[2].Status)
+	}
+	if got := AggregatePipelineStatus(stages); got != PipelineStatusRunning {
+		t.Errorf("expected running pipeline, got %s", got)
+	}
+
+	stages[1].Status = StageStatusFailed
+	if got := AggregatePipelineStatus(stages); got != PipelineStatusFailed {
+		t.Errorf("expected failed pipeline, got %s", got)
+	}
+}
@@ -0,0 +1,63 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ParseRenterTiers parses "address:tier" entries into a map of checksummed renter address to tier
+func ParseRenterTiers(entries []string) (map[string]int, error) {
+	tiers := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		address, tier, ok := strings.Cut(entry, ":")
+		if !ok || !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid renter tier %q, expected address:tier", entry)
+		}
+
+		value, err := strconv.Atoi(tier)
+		if err != nil {
+			return nil, fmt.Errorf("invalid renter tier %q: %w", entry, err)
+		}
+
+		tiers[common.HexToAddress(address).Hex()] = value
+	}
+	return tiers, nil
+}
+
+// SortQueue orders queued jobs for dispatch: renter priority first, then payment, then FIFO
+func SortQueue(jobs []Job) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return queueLess(jobs[i], jobs[j])
+	})
+}
+
+// queueLess reports whether job a should be dispatched before job b
+func queueLess(a, b Job) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+
+	if cmp := paymentAmount(a).Cmp(paymentAmount(b)); cmp != 0 {
+		return cmp > 0
+	}
+
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+
+	return a.ID < b.ID
+}
+
+// paymentAmount parses a job's payment in wei, treating unparseable amounts as zero
+func paymentAmount(job Job) *big.Int {
+	amount, ok := new(big.Int).SetString(job.PaymentAmount, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return amount
+}
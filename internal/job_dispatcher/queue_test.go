@@ -0,0 +1,62 @@
+package job_dispatcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSortQueue(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	jobs := []Job{
+		{ID: "0xfifo-late", PaymentAmount: "1000", CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "0xfifo-early", PaymentAmount: "1000", CreatedAt: base.Add(time.Minute)},
+		{ID: "0xhigh-pay", PaymentAmount: "5000", CreatedAt: base.Add(3 * time.Minute)},
+		{ID: "0xtier", Priority: 1, PaymentAmount: "1", CreatedAt: base.Add(4 * time.Minute)},
+		{ID: "0xbad-pay", PaymentAmount: "not-a-number", CreatedAt: base},
+	}
+
+	SortQueue(jobs)
+
+	want := []string{"0xtier", "0xhigh-pay", "0xfifo-early", "0xfifo-late", "0xbad-pay"}
+	for i, id := range want {
+		if jobs[i].ID != id {
+			t.Errorf("position %d: expected %s, got %s", i+1, id, jobs[i].ID)
+		}
+	}
+}
+
+func TestParseRenterTiers(t *testing.T) {
+	tiers, err := ParseRenterTiers([]string{"0x742d35cc6634c0532925a3b844bc454e4438f44e:2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tiers["0x742d35Cc6634C0532925a3b844Bc454e4438f44e"] != 2 {
+		t.Errorf("expected checksummed address with tier 2, got %v", tiers)
+	}
+
+	for _, entry := range []string{"0x742d35cc6634c0532925a3b844bc454e4438f44e", "not-an-address:1", "0x742d35cc6634c0532925a3b844bc454e4438f44e:gold"} {
+		if _, err := ParseRenterTiers([]string{entry}); err == nil {
+			t.Errorf("expected %q to be rejected", entry)
+		}
+	}
+}
+
+func TestHoldQueue_KeepsOnePendingRelease(t *testing.T) {
+	s := &Service{heldUntil: make(map[string]time.Time)}
+	provider := "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
+	first := time.Now().Add(time.Hour)
+
+	s.holdQueue(provider, first)
+	s.holdQueue(provider, first)
+	s.holdQueue(provider, first.Add(time.Minute))
+	if due := s.heldUntil[provider]; !due.Equal(first) {
+		t.Errorf("expected later releases to be covered by the pending one, due %v", due)
+	}
+
+	earlier := first.Add(-30 * time.Minute)
+	s.holdQueue(provider, earlier)
+	if due := s.heldUntil[provider]; !due.Equal(earlier) {
+		t.Errorf("expected an earlier release to replace the pending one, due %v", due)
+	}
+}
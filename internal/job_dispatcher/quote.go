@@ -0,0 +1,223 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// WorkloadClass is a coarse description of what a job does, used to compare runtimes
+type WorkloadClass string
+
+const (
+	WorkloadInference  WorkloadClass = "inference"
+	WorkloadTraining   WorkloadClass = "training"
+	WorkloadFineTuning WorkloadClass = "fine_tuning"
+	WorkloadRendering  WorkloadClass = "rendering"
+	WorkloadBatch      WorkloadClass = "batch"
+)
+
+// Quote tuning
+const (
+	// minQuoteSamples is how many similar runs are needed before their runtimes are trusted
+	minQuoteSamples = 3
+	// quoteHistoryLimit bounds how many recent completed jobs quotes are built from
+	quoteHistoryLimit = 2000
+	// defaultQuoteLimit and maxQuoteLimit bound how many providers a quote suggests
+	defaultQuoteLimit = 5
+	maxQuoteLimit     = 20
+)
+
+// Basis of a runtime estimate, from most to least specific
+const (
+	QuoteBasisProviderImage = "provider_image"
+	QuoteBasisImage         = "image"
+	QuoteBasisWorkload      = "workload_class"
+	QuoteBasisNone          = "none"
+)
+
+// Source of the rate a quote is priced at
+const (
+	RateSourceRateCard = "rate_card"
+	RateSourceProvider = "provider_history"
+	RateSourceNetwork  = "network_history"
+)
+
+// ValidateWorkloadClass checks that a workload class is known, allowing it to be empty
+func ValidateWorkloadClass(class WorkloadClass) error {
+	switch class {
+	case "", WorkloadInference, WorkloadTraining, WorkloadFineTuning, WorkloadRendering, WorkloadBatch:
+		return nil
+	}
+	return fmt.Errorf("unknown workload_class %q", class)
+}
+
+// RuntimeEstimate is an expected job runtime with a p10-p90 confidence range
+type RuntimeEstimate struct {
+	Seconds     float64 `json:"seconds"`
+	LowSeconds  float64 `json:"low_seconds"`
+	HighSeconds float64 `json:"high_seconds"`
+	Samples     int     `json:"samples"`
+	Basis       string  `json:"basis"`
+}
+
+// EstimateRuntime summarizes runtime samples as their median and p10-p90 range
+func EstimateRuntime(samples []float64, basis string) RuntimeEstimate {
+	if len(samples) == 0 {
+		return RuntimeEstimate{Basis: QuoteBasisNone}
+	}
+
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	return RuntimeEstimate{
+		Seconds:     Percentile(sorted, 50),
+		LowSeconds:  Percentile(sorted, 10),
+		HighSeconds: Percentile(sorted, 90),
+		Samples:     len(sorted),
+		Basis:       basis,
+	}
+}
+
+// Percentile returns the p-th percentile of sorted values, interpolating between ranks
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// QuoteConfidence rates how far a quote can be trusted from how many runs it is based on
+func QuoteConfidence(estimate RuntimeEstimate) string {
+	switch {
+	case estimate.Basis == QuoteBasisNone:
+		return "none"
+	case estimate.Samples >= 20 && estimate.Basis != QuoteBasisWorkload:
+		return "high"
+	case estimate.Samples >= 5:
+		return "medium"
+	}
+	return "low"
+}
+
+// quoteHistory indexes recently completed jobs for estimating runtimes and rates
+type quoteHistory struct {
+	jobs []Job
+}
+
+// runtimeSamples returns runtimes of jobs similar to the quoted spec, preferring the
+// provider's own runs of the same image, then any runs of the image, then the workload class
+func (h quoteHistory) runtimeSamples(image string, class WorkloadClass, providerAddress string) ([]float64, string) {
+	repository := imageRepository(image)
+
+	var providerImage, sameImage, sameClass []float64
+	for _, job := range h.jobs {
+		runtime, ok := jobRuntime(job)
+		if !ok {
+			continue
+		}
+
+		if repository != "" && imageRepository(job.DockerImage) == repository {
+			sameImage = append(sameImage, runtime)
+			if strings.EqualFold(job.ProviderAddress, providerAddress) {
+				providerImage = append(providerImage, runtime)
+			}
+		}
+		if class != "" && job.WorkloadClass == class {
+			sameClass = append(sameClass, runtime)
+		}
+	}
+
+	switch {
+	case len(providerImage) >= minQuoteSamples:
+		return providerImage, QuoteBasisProviderImage
+	case len(sameImage) >= minQuoteSamples:
+		return sameImage, QuoteBasisImage
+	case len(sameClass) > 0:
+		return sameClass, QuoteBasisWorkload
+	case len(sameImage) > 0:
+		return sameImage, QuoteBasisImage
+	}
+	return nil, QuoteBasisNone
+}
+
+// rate returns the median wei per second paid for a provider's completed jobs, falling back
+// to the network-wide median when the provider has too little history
+func (h quoteHistory) rate(providerAddress string) (float64, string) {
+	var providerRates, networkRates []float64
+	for _, job := range h.jobs {
+		rate, ok := jobRate(job)
+		if !ok {
+			continue
+		}
+
+		networkRates = append(networkRates, rate)
+		if strings.EqualFold(job.ProviderAddress, providerAddress) {
+			providerRates = append(providerRates, rate)
+		}
+	}
+
+	if len(providerRates) >= minQuoteSamples {
+		sort.Float64s(providerRates)
+		return Percentile(providerRates, 50), RateSourceProvider
+	}
+
+	sort.Float64s(networkRates)
+	return Percentile(networkRates, 50), RateSourceNetwork
+}
+
+// jobRuntime returns how long a completed job ran, preferring the provider's own report
+func jobRuntime(job Job) (float64, bool) {
+	if job.ResultManifest != nil && job.ResultManifest.RuntimeSeconds > 0 {
+		return job.ResultManifest.RuntimeSeconds, true
+	}
+	if job.AssignedAt == nil || job.CompletedAt == nil {
+		return 0, false
+	}
+
+	runtime := job.CompletedAt.Sub(*job.AssignedAt).Seconds()
+	return runtime, runtime > 0
+}
+
+// jobRate returns the wei per second a completed job was paid
+func jobRate(job Job) (float64, bool) {
+	runtime, ok := jobRuntime(job)
+	if !ok {
+		return 0, false
+	}
+
+	payment := paymentAmount(job)
+	if payment.Sign() <= 0 {
+		return 0, false
+	}
+
+	amount, _ := new(big.Float).SetInt(payment).Float64()
+	return amount / runtime, true
+}
+
+// imageRepository returns an image's normalized repository without its tag or digest
+func imageRepository(image string) string {
+	ref, err := reference.ParseNormalizedNamed(strings.TrimSpace(image))
+	if err != nil {
+		return ""
+	}
+	return ref.Name()
+}
+
+// weiString formats an estimated amount of wei as an integer string
+func weiString(amount float64) string {
+	wei, _ := new(big.Float).SetFloat64(math.Round(amount)).Int(nil)
+	return wei.String()
+}
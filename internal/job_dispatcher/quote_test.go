@@ -0,0 +1,79 @@
+package job_dispatcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40, 50}
+
+	cases := map[float64]float64{0: 10, 10: 14, 50: 30, 90: 46, 100: 50}
+	for p, want := range cases {
+		if got := Percentile(sorted, p); got != want {
+			t.Errorf("p%v: expected %v, got %v", p, want, got)
+		}
+	}
+
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("expected 0 for no samples, got %v", got)
+	}
+
+	estimate := EstimateRuntime([]float64{50, 10, 30, 20, 40}, QuoteBasisImage)
+	if estimate.Seconds != 30 || estimate.LowSeconds != 14 || estimate.HighSeconds != 46 || estimate.Samples != 5 {
+		t.Errorf("unexpected estimate: %+v", estimate)
+	}
+}
+
+func TestQuoteHistory(t *testing.T) {
+	completed := func(provider, image string, class WorkloadClass, runtime time.Duration, payment string) Job {
+		assignedAt := time.Now().Add(-runtime)
+		completedAt := time.Now()
+		return Job{
+			ProviderAddress: provider,
+			DockerImage:     image,
+			WorkloadClass:   class,
+			PaymentAmount:   payment,
+			Status:          JobStatusCompleted,
+			AssignedAt:      &assignedAt,
+			CompletedAt:     &completedAt,
+		}
+	}
+
+	otherProvider := "0x8ba1f109551bD432803012645Ac136ddd64DBA72"
+	history := quoteHistory{jobs: []Job{
+		completed(testProvider, "lamda/train:1.0", WorkloadTraining, 100*time.Second, "1000"),
+		completed(testProvider, "docker.io/lamda/train:2.0", WorkloadTraining, 200*time.Second, "2000"),
+		completed(testProvider, "lamda/train@sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", WorkloadTraining, 300*time.Second, "3000"),
+		completed(otherProvider, "lamda/infer:1.0", WorkloadInference, 10*time.Second, "500"),
+	}}
+
+	samples, basis := history.runtimeSamples("lamda/train:3.0", WorkloadTraining, testProvider)
+	if basis != QuoteBasisProviderImage || len(samples) != 3 {
+		t.Errorf("expected the provider's own runs of the image, got %s with %d samples", basis, len(samples))
+	}
+
+	samples, basis = history.runtimeSamples("lamda/train:3.0", WorkloadTraining, otherProvider)
+	if basis != QuoteBasisImage || len(samples) != 3 {
+		t.Errorf("expected runs of the image, got %s with %d samples", basis, len(samples))
+	}
+
+	samples, basis = history.runtimeSamples("lamda/serve:1.0", WorkloadInference, testProvider)
+	if basis != QuoteBasisWorkload || len(samples) != 1 {
+		t.Errorf("expected runs of the workload class, got %s with %d samples", basis, len(samples))
+	}
+
+	if _, basis = history.runtimeSamples("lamda/render:1.0", WorkloadRendering, testProvider); basis != QuoteBasisNone {
+		t.Errorf("expected no basis, got %s", basis)
+	}
+
+	rate, source := history.rate(testProvider)
+	if source != RateSourceProvider || rate < 9.9 || rate > 10.1 {
+		t.Errorf("expected provider rate of 10 wei/s, got %v from %s", rate, source)
+	}
+
+	rate, source = history.rate(otherProvider)
+	if source != RateSourceNetwork || rate < 9.9 || rate > 30.1 {
+		t.Errorf("expected network rate, got %v from %s", rate, source)
+	}
+}
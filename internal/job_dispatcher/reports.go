@@ -0,0 +1,130 @@
+package job_dispatcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"lamda_backend/internal/auth"
+)
+
+const (
+	// reportMaxAge is how old a signed status report's timestamp may be when it arrives
+	reportMaxAge = 5 * time.Minute
+	// reportMaxSkew tolerates provider clocks running ahead of the dispatcher
+	reportMaxSkew = time.Minute
+	// reportNonceWindow covers every timestamp a status report or telemetry sample is accepted with
+	reportNonceWindow = reportMaxAge + maxTelemetryClockSkew
+
+	minReportNonceLength = 8
+	maxReportNonceLength = 64
+)
+
+// ValidateStatusReport checks a signed status report's timestamp and nonce
+func ValidateStatusReport(update JobStatusUpdate, now time.Time) error {
+	sent := time.UnixMilli(update.Timestamp)
+	if sent.After(now.Add(reportMaxSkew)) {
+		return fmt.Errorf("status report timestamp is in the future")
+	}
+	if now.Sub(sent) > reportMaxAge {
+		return fmt.Errorf("stale status report: timestamp is older than %s", reportMaxAge)
+	}
+	return validateReportNonce(update.Nonce)
+}
+
+func validateReportNonce(nonce string) error {
+	if len(nonce) < minReportNonceLength || len(nonce) > maxReportNonceLength {
+		return fmt.Errorf("nonce must be between %d and %d characters", minReportNonceLength, maxReportNonceLength)
+	}
+	return nil
+}
+
+// verifyProviderReport checks that a report was signed by the job's provider for the job's
+// current attempt and claims its nonce, so reports cannot be forged, carried over from an
+// earlier attempt or replayed
+func (s *Service) verifyProviderReport(request auth.SignedRequest, job *Job, attempt int, nonce string) error {
+	if job.ProviderAddress == "" || common.HexToAddress(request.Address) != common.HexToAddress(job.ProviderAddress) {
+		return fmt.Errorf("report for job %s is not signed by its provider", job.ID)
+	}
+	if attempt != job.Attempts {
+		return fmt.Errorf("report for job %s is for attempt %d, current attempt is %d", job.ID, attempt, job.Attempts)
+	}
+	if !s.reportNonces.Claim(job.ID, nonce, time.Now()) {
+		return fmt.Errorf("nonce already used for job %s", job.ID)
+	}
+	return nil
+}
+
+// VerifyLogChunk decodes a log chunk published on jobs.logs.<jobId> as a request signed by
+// the job's provider. Chunks are retained as published, so readers check them on replay.
+func VerifyLogChunk(data []byte, job *Job) (*JobLogChunk, error) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("log chunk is not a signed request: %w", err)
+	}
+
+	var chunk JobLogChunk
+	if err := request.Decode(&chunk); err != nil {
+		return nil, fmt.Errorf("invalid signed log chunk: %w", err)
+	}
+	if job.ProviderAddress == "" || common.HexToAddress(request.Address) != common.HexToAddress(job.ProviderAddress) {
+		return nil, fmt.Errorf("log chunk for job %s is not signed by its provider", job.ID)
+	}
+	if !strings.EqualFold(chunk.JobID, job.ID) {
+		return nil, fmt.Errorf("log chunk is for job %s, not %s", chunk.JobID, job.ID)
+	}
+	return &chunk, nil
+}
+
+// reportNonces remembers the nonces used for each job within the report window so a
+// captured status report or telemetry sample cannot be replayed while it is still fresh
+type reportNonces struct {
+	mu       sync.Mutex
+	seen     map[string]map[string]time.Time
+	prunedAt time.Time
+}
+
+func newReportNonces() *reportNonces {
+	return &reportNonces{seen: make(map[string]map[string]time.Time)}
+}
+
+// Claim records the nonce for the job, returning false if it was already used
+func (n *reportNonces) Claim(jobID, nonce string, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	// Nonces older than the window are covered by the timestamp checks. Finished jobs stop
+	// reporting, so sweep every job now and then rather than only the one reporting.
+	if now.Sub(n.prunedAt) > time.Minute {
+		n.prune(now)
+	}
+
+	nonces := n.seen[jobID]
+	if nonces == nil {
+		nonces = make(map[string]time.Time)
+		n.seen[jobID] = nonces
+	}
+	if _, ok := nonces[nonce]; ok {
+		return false
+	}
+	nonces[nonce] = now
+	return true
+}
+
+func (n *reportNonces) prune(now time.Time) {
+	for seenJob, nonces := range n.seen {
+		for seenNonce, seenAt := range nonces {
+			if now.Sub(seenAt) > reportNonceWindow {
+				delete(nonces, seenNonce)
+			}
+		}
+		if len(nonces) == 0 {
+			delete(n.seen, seenJob)
+		}
+	}
+	n.prunedAt = now
+}
@@ -0,0 +1,130 @@
+package job_dispatcher
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"lamda_backend/internal/auth"
+)
+
+func TestValidateStatusReport(t *testing.T) {
+	now := time.Now()
+	valid := JobStatusUpdate{JobID: "job-1", Attempt: 1, Timestamp: now.UnixMilli(), Nonce: "a1b2c3d4e5", Status: JobStatusRunning}
+	if err := ValidateStatusReport(valid, now); err != nil {
+		t.Fatalf("expected valid report, got %v", err)
+	}
+
+	stale := valid
+	stale.Timestamp = now.Add(-reportMaxAge - time.Second).UnixMilli()
+	future := valid
+	future.Timestamp = now.Add(2 * reportMaxSkew).UnixMilli()
+	missingNonce := valid
+	missingNonce.Nonce = ""
+	for name, report := range map[string]JobStatusUpdate{"stale": stale, "future": future, "missing nonce": missingNonce} {
+		if err := ValidateStatusReport(report, now); err == nil {
+			t.Errorf("expected %s report to be rejected", name)
+		}
+	}
+}
+
+func TestVerifyProviderReport(t *testing.T) {
+	providerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	job := &Job{ID: "job-1", ProviderAddress: crypto.PubkeyToAddress(providerKey.PublicKey).Hex(), Attempts: 2}
+	s := &Service{reportNonces: newReportNonces()}
+
+	fromProvider := auth.SignedRequest{Address: crypto.PubkeyToAddress(providerKey.PublicKey).Hex()}
+	fromOther := auth.SignedRequest{Address: crypto.PubkeyToAddress(otherKey.PublicKey).Hex()}
+
+	if err := s.verifyProviderReport(fromOther, job, 2, "a1b2c3d4e5"); err == nil {
+		t.Error("expected a report signed by another wallet to be rejected")
+	}
+	if err := s.verifyProviderReport(fromProvider, job, 1, "a1b2c3d4e5"); err == nil {
+		t.Error("expected a report for an earlier attempt to be rejected")
+	}
+	if err := s.verifyProviderReport(fromProvider, job, 2, "a1b2c3d4e5"); err != nil {
+		t.Fatalf("expected the provider's report to be accepted, got %v", err)
+	}
+	if err := s.verifyProviderReport(fromProvider, job, 2, "a1b2c3d4e5"); err == nil {
+		t.Error("expected a replayed nonce to be rejected")
+	}
+}
+
+func TestReportNonces_Claim(t *testing.T) {
+	nonces := newReportNonces()
+	now := time.Now()
+
+	if !nonces.Claim("job-1", "a1b2c3d4e5", now) {
+		t.Fatal("expected first claim to succeed")
+	}
+	if nonces.Claim("job-1", "a1b2c3d4e5", now.Add(time.Second)) {
+		t.Error("expected repeated nonce to be rejected")
+	}
+	if !nonces.Claim("job-2", "a1b2c3d4e5", now) {
+		t.Error("expected nonces to be tracked per job")
+	}
+	if !nonces.Claim("job-1", "a1b2c3d4e5", now.Add(2*reportNonceWindow)) {
+		t.Error("expected nonce to be reusable once outside the window")
+	}
+}
+
+func TestVerifyLogChunk(t *testing.T) {
+	providerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	job := &Job{ID: "0xabc", ProviderAddress: crypto.PubkeyToAddress(providerKey.PublicKey).Hex()}
+
+	publish := func(chunk JobLogChunk, key *ecdsa.PrivateKey) []byte {
+		payload, err := json.Marshal(chunk)
+		if err != nil {
+			t.Fatalf("failed to marshal chunk: %v", err)
+		}
+		signature, err := auth.SignWalletMessage(payload, key)
+		if err != nil {
+			t.Fatalf("failed to sign chunk: %v", err)
+		}
+		data, err := json.Marshal(auth.SignedRequest{Address: crypto.PubkeyToAddress(key.PublicKey).Hex(), Payload: string(payload), Signature: signature})
+		if err != nil {
+			t.Fatalf("failed to marshal request: %v", err)
+		}
+		return data
+	}
+
+	chunk, err := VerifyLogChunk(publish(JobLogChunk{JobID: "0xABC", Sequence: 3, Data: "line\n\nevent: end\n"}, providerKey), job)
+	if err != nil {
+		t.Fatalf("expected the provider's chunk to be accepted, got %v", err)
+	}
+	if chunk.Sequence != 3 {
+		t.Errorf("expected sequence 3, got %d", chunk.Sequence)
+	}
+
+	unsigned, err := json.Marshal(JobLogChunk{JobID: job.ID, Data: "forged"})
+	if err != nil {
+		t.Fatalf("failed to marshal chunk: %v", err)
+	}
+	rejected := map[string][]byte{
+		"unsigned":     unsigned,
+		"other signer": publish(JobLogChunk{JobID: job.ID, Data: "forged"}, otherKey),
+		"other job":    publish(JobLogChunk{JobID: "0xdef", Data: "replayed"}, providerKey),
+	}
+	for name, data := range rejected {
+		if _, err := VerifyLogChunk(data, job); err == nil {
+			t.Errorf("expected %s chunk to be rejected", name)
+		}
+	}
+}
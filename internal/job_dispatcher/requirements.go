@@ -0,0 +1,144 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"strings"
+
+	"lamda_backend/internal/node_registry"
+)
+
+// IssueSeverity indicates whether a requirement issue prevents dispatch
+type IssueSeverity string
+
+const (
+	IssueSeverityWarning  IssueSeverity = "warning"
+	IssueSeverityBlocking IssueSeverity = "blocking"
+)
+
+// Requirement issue codes
+const (
+	IssueProviderUnknown     = "provider_unknown"
+	IssueProviderOffline     = "provider_offline"
+	IssueProviderUnavailable = "provider_unavailable"
+	IssueRegistryUnavailable = "registry_unavailable"
+	IssueInsufficientVRAM    = "insufficient_vram"
+	IssueGPUModelMismatch    = "gpu_model_mismatch"
+	IssueCUDAUnverified      = "cuda_version_unverified"
+	IssueCUDAUnsupported     = "cuda_version_unsupported"
+)
+
+// RequirementIssue describes a mismatch between a job's requirements and its provider
+type RequirementIssue struct {
+	Severity IssueSeverity `json:"severity"`
+	Code     string        `json:"code"`
+	Message  string        `json:"message"`
+}
+
+// ValidateProvider checks a provider against a job's resource requirements.
+// A nil provider means the address is not registered in the node registry.
+func ValidateProvider(provider *node_registry.Provider, requirements ResourceRequirements) []RequirementIssue {
+	issues := []RequirementIssue{}
+
+	if provider == nil {
+		return append(issues, RequirementIssue{
+			Severity: IssueSeverityBlocking,
+			Code:     IssueProviderUnknown,
+			Message:  "provider is not registered in the node registry",
+		})
+	}
+
+	if !provider.IsOnline {
+		issues = append(issues, RequirementIssue{
+			Severity: IssueSeverityWarning,
+			Code:     IssueProviderOffline,
+			Message:  fmt.Sprintf("provider has not sent a heartbeat since %s", provider.LastSeen.UTC().Format("2006-01-02T15:04:05Z")),
+		})
+	}
+
+	// Jobs for providers in maintenance or outside their hours are held, not rejected
+	if provider.Unavailable {
+		message := fmt.Sprintf("provider is not taking jobs (%s); jobs are held until it is available", provider.UnavailableReason)
+		if provider.AvailableAt != nil {
+			message = fmt.Sprintf("provider is not taking jobs (%s); jobs are held until %s", provider.UnavailableReason, provider.AvailableAt.UTC().Format("2006-01-02T15:04:05Z"))
+		}
+		issues = append(issues, RequirementIssue{
+			Severity: IssueSeverityWarning,
+			Code:     IssueProviderUnavailable,
+			Message:  message,
+		})
+	}
+
+	if requirements.MinVRAM > 0 && provider.VRAM < requirements.MinVRAM {
+		issues = append(issues, RequirementIssue{
+			Severity: IssueSeverityBlocking,
+			Code:     IssueInsufficientVRAM,
+			Message:  fmt.Sprintf("provider reports %d VRAM, job requires at least %d", provider.VRAM, requirements.MinVRAM),
+		})
+	}
+
+	if requirements.GPUModelFamily != "" && !matchesGPUFamily(provider.GPUModel, requirements.GPUModelFamily) {
+		issues = append(issues, RequirementIssue{
+			Severity: IssueSeverityBlocking,
+			Code:     IssueGPUModelMismatch,
+			Message:  fmt.Sprintf("provider GPU %q is not in the %q family", provider.GPUModel, requirements.GPUModelFamily),
+		})
+	}
+
+	// CUDA can only be checked for providers whose agent has reported a capability profile
+	if requirements.CUDAVersion != "" {
+		providerCUDA := provider.Capabilities.CUDAVersion
+		switch {
+		case providerCUDA == "":
+			issues = append(issues, RequirementIssue{
+				Severity: IssueSeverityWarning,
+				Code:     IssueCUDAUnverified,
+				Message:  fmt.Sprintf("provider CUDA version is unknown, job requires %s", requirements.CUDAVersion),
+			})
+		case node_registry.ValidateVersion(requirements.CUDAVersion) != nil:
+			issues = append(issues, RequirementIssue{
+				Severity: IssueSeverityWarning,
+				Code:     IssueCUDAUnverified,
+				Message:  fmt.Sprintf("job CUDA requirement %q is not a version number", requirements.CUDAVersion),
+			})
+		case node_registry.CompareVersions(providerCUDA, requirements.CUDAVersion) < 0:
+			issues = append(issues, RequirementIssue{
+				Severity: IssueSeverityBlocking,
+				Code:     IssueCUDAUnsupported,
+				Message:  fmt.Sprintf("provider supports CUDA %s, job requires %s", providerCUDA, requirements.CUDAVersion),
+			})
+		}
+	}
+
+	return issues
+}
+
+// HasBlockingIssues reports whether any issue prevents dispatch
+func HasBlockingIssues(issues []RequirementIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == IssueSeverityBlocking {
+			return true
+		}
+	}
+	return false
+}
+
+// blockingSummary joins the messages of all blocking issues
+func blockingSummary(issues []RequirementIssue) string {
+	var messages []string
+	for _, issue := range issues {
+		if issue.Severity == IssueSeverityBlocking {
+			messages = append(messages, issue.Message)
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// matchesGPUFamily reports whether a GPU model name belongs to a family, ignoring
+// case, spaces and dashes (e.g. "NVIDIA GeForce RTX-4090" is in the "rtx 40" family)
+func matchesGPUFamily(model, family string) bool {
+	normalize := strings.NewReplacer(" ", "", "-", "", "_", "")
+	return strings.Contains(
+		normalize.Replace(strings.ToLower(model)),
+		normalize.Replace(strings.ToLower(family)),
+	)
+}
@@ -0,0 +1,118 @@
+package job_dispatcher
+
+import (
+	"testing"
+	"time"
+
+	"lamda_backend/internal/node_registry"
+)
+
+func TestValidateProvider_UnknownProvider(t *testing.T) {
+	issues := ValidateProvider(nil, ResourceRequirements{})
+
+	if len(issues) != 1 || issues[0].Code != IssueProviderUnknown {
+		t.Fatalf("expected a single provider_unknown issue, got %+v", issues)
+	}
+	if !HasBlockingIssues(issues) {
+		t.Error("unknown provider should block dispatch")
+	}
+}
+
+func TestValidateProvider_Requirements(t *testing.T) {
+	provider := &node_registry.Provider{
+		WalletAddress: "0x742d35Cc6634C0532925a3b8D4C9db96C4b4d8b6",
+		GPUModel:      "NVIDIA GeForce RTX-4090",
+		VRAM:          24,
+		IsOnline:      true,
+		LastSeen:      time.Now(),
+	}
+
+	tests := []struct {
+		name         string
+		requirements ResourceRequirements
+		online       bool
+		unavailable  bool
+		wantCodes    []string
+		wantBlocking bool
+	}{
+		{
+			name:         "satisfied",
+			requirements: ResourceRequirements{MinVRAM: 16, GPUModelFamily: "rtx 40"},
+			online:       true,
+		},
+		{
+			name:         "insufficient vram",
+			requirements: ResourceRequirements{MinVRAM: 48},
+			online:       true,
+			wantCodes:    []string{IssueInsufficientVRAM},
+			wantBlocking: true,
+		},
+		{
+			name:         "wrong gpu family",
+			requirements: ResourceRequirements{GPUModelFamily: "A100"},
+			online:       true,
+			wantCodes:    []string{IssueGPUModelMismatch},
+			wantBlocking: true,
+		},
+		{
+			name:         "offline provider only warns",
+			requirements: ResourceRequirements{},
+			online:       false,
+			wantCodes:    []string{IssueProviderOffline},
+		},
+		{
+			name:         "provider in maintenance only warns",
+			requirements: ResourceRequirements{},
+			online:       true,
+			unavailable:  true,
+			wantCodes:    []string{IssueProviderUnavailable},
+		},
+		{
+			name:         "cuda requirement cannot be verified",
+			requirements: ResourceRequirements{CUDAVersion: "12.1"},
+			online:       true,
+			wantCodes:    []string{IssueCUDAUnverified},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := *provider
+			p.IsOnline = tt.online
+			p.Unavailable = tt.unavailable
+			p.UnavailableReason = node_registry.UnavailableMaintenance
+
+			issues := ValidateProvider(&p, tt.requirements)
+
+			if len(issues) != len(tt.wantCodes) {
+				t.Fatalf("expected issues %v, got %+v", tt.wantCodes, issues)
+			}
+			for i, code := range tt.wantCodes {
+				if issues[i].Code != code {
+					t.Errorf("issue %d: expected %s, got %s", i, code, issues[i].Code)
+				}
+			}
+			if HasBlockingIssues(issues) != tt.wantBlocking {
+				t.Errorf("expected blocking=%v, got %v", tt.wantBlocking, !tt.wantBlocking)
+			}
+		})
+	}
+}
+
+func TestValidateProvider_CUDAVersion(t *testing.T) {
+	provider := &node_registry.Provider{
+		GPUModel:     "NVIDIA GeForce RTX-4090",
+		VRAM:         24,
+		IsOnline:     true,
+		Capabilities: node_registry.CapabilityProfile{CUDAVersion: "12.2"},
+	}
+
+	if issues := ValidateProvider(provider, ResourceRequirements{CUDAVersion: "12.1"}); len(issues) != 0 {
+		t.Errorf("expected newer provider CUDA to satisfy the requirement, got %+v", issues)
+	}
+
+	issues := ValidateProvider(provider, ResourceRequirements{CUDAVersion: "12.10"})
+	if len(issues) != 1 || issues[0].Code != IssueCUDAUnsupported || !HasBlockingIssues(issues) {
+		t.Errorf("expected a blocking cuda_version_unsupported issue, got %+v", issues)
+	}
+}
@@ -0,0 +1,135 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrorClass groups provider failures by cause so renters can choose which ones to retry
+type ErrorClass string
+
+const (
+	// ErrorClassImagePull means the provider could not pull the job's image
+	ErrorClassImagePull ErrorClass = "image_pull"
+	// ErrorClassOOM means the container ran out of host or GPU memory
+	ErrorClassOOM ErrorClass = "oom"
+	// ErrorClassNetwork means the provider lost connectivity, e.g. fetching inputs from IPFS
+	ErrorClassNetwork ErrorClass = "network"
+	// ErrorClassApplication means the job itself failed, e.g. a non-zero exit code
+	ErrorClassApplication ErrorClass = "application"
+)
+
+// Retry policy limits and defaults
+const (
+	maxRetryAttempts              = 10
+	defaultRetryBackoffSeconds    = 30
+	defaultRetryMaxBackoffSeconds = 3600
+	defaultRetryMultiplier        = 2.0
+)
+
+// transientErrorClasses are retried when a policy does not list its own classes
+var transientErrorClasses = []ErrorClass{ErrorClassImagePull, ErrorClassOOM, ErrorClassNetwork}
+
+// RetryPolicy describes how often a failed job is re-sent to its provider
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, so 3 allows two retries
+	MaxAttempts int `json:"max_attempts"`
+	// BackoffSeconds is the delay before the first retry, multiplied by BackoffMultiplier
+	// for each later one and capped at MaxBackoffSeconds
+	BackoffSeconds    int          `json:"backoff_seconds,omitempty"`
+	BackoffMultiplier float64      `json:"backoff_multiplier,omitempty"`
+	MaxBackoffSeconds int          `json:"max_backoff_seconds,omitempty"`
+	RetryOn           []ErrorClass `json:"retry_on,omitempty"`
+}
+
+// ValidateRetryPolicy checks a retry policy and fills in its defaults
+func ValidateRetryPolicy(policy *RetryPolicy) error {
+	if policy.MaxAttempts < 1 || policy.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("max_attempts must be between 1 and %d", maxRetryAttempts)
+	}
+	if policy.BackoffSeconds < 0 || policy.MaxBackoffSeconds < 0 {
+		return fmt.Errorf("backoff must not be negative")
+	}
+	if policy.BackoffMultiplier != 0 && policy.BackoffMultiplier < 1 {
+		return fmt.Errorf("backoff_multiplier must be at least 1")
+	}
+
+	if policy.BackoffSeconds == 0 {
+		policy.BackoffSeconds = defaultRetryBackoffSeconds
+	}
+	if policy.BackoffMultiplier == 0 {
+		policy.BackoffMultiplier = defaultRetryMultiplier
+	}
+	if policy.MaxBackoffSeconds == 0 {
+		policy.MaxBackoffSeconds = defaultRetryMaxBackoffSeconds
+	}
+	if policy.MaxBackoffSeconds < policy.BackoffSeconds {
+		return fmt.Errorf("max_backoff_seconds must not be less than backoff_seconds")
+	}
+
+	if len(policy.RetryOn) == 0 {
+		policy.RetryOn = append([]ErrorClass(nil), transientErrorClasses...)
+	}
+	for _, class := range policy.RetryOn {
+		if !class.valid() {
+			return fmt.Errorf("unknown error class %q", class)
+		}
+	}
+
+	return nil
+}
+
+// ShouldRetry reports whether a job that failed on the given attempt may be retried
+func (p *RetryPolicy) ShouldRetry(attempt int, class ErrorClass) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	for _, retryable := range p.RetryOn {
+		if retryable == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns how long to wait before retrying a job that failed on the given attempt
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	seconds := float64(p.BackoffSeconds) * math.Pow(p.BackoffMultiplier, float64(attempt-1))
+	if seconds > float64(p.MaxBackoffSeconds) {
+		seconds = float64(p.MaxBackoffSeconds)
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// valid reports whether an error class is one the dispatcher knows
+func (c ErrorClass) valid() bool {
+	switch c {
+	case ErrorClassImagePull, ErrorClassOOM, ErrorClassNetwork, ErrorClassApplication:
+		return true
+	}
+	return false
+}
+
+// ClassifyError returns the error class of a failure report. The provider's own class is
+// trusted when it is known, otherwise the class is guessed from the error message.
+func ClassifyError(reported ErrorClass, message string) ErrorClass {
+	if reported.valid() {
+		return reported
+	}
+
+	message = strings.ToLower(message)
+	switch {
+	case strings.Contains(message, "pull") || strings.Contains(message, "manifest unknown") || strings.Contains(message, "image not found"):
+		return ErrorClassImagePull
+	case strings.Contains(message, "oom") || strings.Contains(message, "out of memory") || strings.Contains(message, "exit code 137"):
+		return ErrorClassOOM
+	case strings.Contains(message, "network") || strings.Contains(message, "connection") || strings.Contains(message, "timeout") ||
+		strings.Contains(message, "timed out") || strings.Contains(message, "no such host"):
+		return ErrorClassNetwork
+	}
+	return ErrorClassApplication
+}
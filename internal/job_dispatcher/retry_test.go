@@ -0,0 +1,66 @@
+package job_dispatcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 5, BackoffSeconds: 10, MaxBackoffSeconds: 60}
+	if err := ValidateRetryPolicy(policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 60 * time.Second}
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Errorf("attempt %d: expected backoff %s, got %s", i+1, want, got)
+		}
+	}
+
+	if !policy.ShouldRetry(1, ErrorClassOOM) {
+		t.Error("expected OOM to be retried by default")
+	}
+	if policy.ShouldRetry(1, ErrorClassApplication) {
+		t.Error("expected application errors not to be retried by default")
+	}
+	if policy.ShouldRetry(5, ErrorClassNetwork) {
+		t.Error("expected no retry after the last attempt")
+	}
+
+	var none *RetryPolicy
+	if none.ShouldRetry(1, ErrorClassNetwork) {
+		t.Error("expected jobs without a policy not to be retried")
+	}
+
+	invalid := []RetryPolicy{
+		{MaxAttempts: 0},
+		{MaxAttempts: 11},
+		{MaxAttempts: 3, BackoffMultiplier: 0.5},
+		{MaxAttempts: 3, BackoffSeconds: 120, MaxBackoffSeconds: 60},
+		{MaxAttempts: 3, RetryOn: []ErrorClass{"disk_full"}},
+	}
+	for i := range invalid {
+		if err := ValidateRetryPolicy(&invalid[i]); err == nil {
+			t.Errorf("policy %d: expected error", i)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	cases := map[string]ErrorClass{
+		"failed to pull image lamda/train:1.0":           ErrorClassImagePull,
+		"CUDA out of memory. Tried to allocate 2.00 GiB": ErrorClassOOM,
+		"dial tcp: lookup ipfs.io: no such host":         ErrorClassNetwork,
+		"container exited with code 1":                   ErrorClassApplication,
+	}
+	for message, want := range cases {
+		if got := ClassifyError("", message); got != want {
+			t.Errorf("%q: expected %s, got %s", message, want, got)
+		}
+	}
+
+	if got := ClassifyError(ErrorClassNetwork, "container exited with code 1"); got != ErrorClassNetwork {
+		t.Errorf("expected reported class to be trusted, got %s", got)
+	}
+}
@@ -0,0 +1,125 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Label limits
+const (
+	maxJobLabels        = 16
+	maxLabelValueLength = 128
+)
+
+// labelKeyPattern matches a label key such as "team" or "lamda.io/experiment"
+var labelKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]{0,62}$`)
+
+// jobSortColumns maps sortable JobQuery fields to their SQL expressions
+var jobSortColumns = map[string]string{
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+	"assigned_at":    "assigned_at",
+	"completed_at":   "completed_at",
+	"payment_amount": "CAST(NULLIF(payment_amount, '') AS NUMERIC)",
+	"priority":       "priority",
+	"status":         "status",
+}
+
+// ValidateLabels checks a job's labels. Values must be non-empty so that an empty value in
+// a label filter can mean "has this key".
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > maxJobLabels {
+		return fmt.Errorf("a job can have at most %d labels", maxJobLabels)
+	}
+
+	for key, value := range labels {
+		if !labelKeyPattern.MatchString(key) {
+			return fmt.Errorf("label key %q must be lowercase alphanumerics, '.', '_', '-' or '/' and at most 63 characters", key)
+		}
+		if value == "" || len(value) > maxLabelValueLength {
+			return fmt.Errorf("label %s must have a value of 1 to %d characters", key, maxLabelValueLength)
+		}
+	}
+
+	return nil
+}
+
+// ParseLabelSelector parses "key=value" and bare "key" selectors into a label filter
+func ParseLabelSelector(selectors []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, selector := range selectors {
+		for _, term := range strings.Split(selector, ",") {
+			term = strings.TrimSpace(term)
+			if term == "" {
+				continue
+			}
+
+			key, value, _ := strings.Cut(term, "=")
+			key = strings.TrimSpace(key)
+			if !labelKeyPattern.MatchString(key) {
+				return nil, fmt.Errorf("invalid label key %q", key)
+			}
+			labels[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return labels, nil
+}
+
+// ValidateJobQuery checks a job query's filters and returns its ORDER BY clause
+func ValidateJobQuery(query *JobQuery) (string, error) {
+	for key := range query.Labels {
+		if !labelKeyPattern.MatchString(key) {
+			return "", fmt.Errorf("invalid label key %q", key)
+		}
+	}
+
+	for name, amount := range map[string]string{"min_payment": query.MinPayment, "max_payment": query.MaxPayment} {
+		if amount == "" {
+			continue
+		}
+		if value, ok := new(big.Int).SetString(amount, 10); !ok || value.Sign() < 0 {
+			return "", fmt.Errorf("%s must be a non-negative amount of wei", name)
+		}
+	}
+
+	if query.CreatedFrom != nil && query.CreatedTo != nil && query.CreatedTo.Before(*query.CreatedFrom) {
+		return "", fmt.Errorf("created_to must not be before created_from")
+	}
+	if query.CompletedFrom != nil && query.CompletedTo != nil && query.CompletedTo.Before(*query.CompletedFrom) {
+		return "", fmt.Errorf("completed_to must not be before completed_from")
+	}
+
+	return jobOrderClause(query.SortBy, query.SortOrder)
+}
+
+// jobOrderClause builds an ORDER BY clause from a whitelisted sort field, newest first by default
+func jobOrderClause(sortBy, sortOrder string) (string, error) {
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	column, ok := jobSortColumns[sortBy]
+	if !ok {
+		return "", fmt.Errorf("cannot sort jobs by %q", sortBy)
+	}
+
+	direction := "DESC"
+	switch strings.ToLower(sortOrder) {
+	case "", "desc":
+	case "asc":
+		direction = "ASC"
+	default:
+		return "", fmt.Errorf("sort_order must be asc or desc")
+	}
+
+	// Jobs without the sorted value go last, and the ID keeps pages stable
+	return fmt.Sprintf("%s %s NULLS LAST, id %s", column, direction, direction), nil
+}
+
+// containsPattern escapes a search term for use in a LIKE pattern matching it anywhere
+func containsPattern(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(term) + "%"
+}
@@ -0,0 +1,75 @@
+package job_dispatcher
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLabels(t *testing.T) {
+	if err := ValidateLabels(map[string]string{"team": "vision", "lamda.io/experiment": "lr-sweep-3"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := []map[string]string{
+		{"Team": "vision"},
+		{"team": ""},
+		{"team": strings.Repeat("v", 129)},
+		{"-team": "vision"},
+	}
+	for i, labels := range invalid {
+		if err := ValidateLabels(labels); err == nil {
+			t.Errorf("labels %d: expected error", i)
+		}
+	}
+
+	selector, err := ParseLabelSelector([]string{"team=vision,experiment", " stage = eval "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selector) != 3 || selector["team"] != "vision" || selector["experiment"] != "" || selector["stage"] != "eval" {
+		t.Errorf("unexpected selector: %v", selector)
+	}
+
+	if _, err := ParseLabelSelector([]string{"Team=vision"}); err == nil {
+		t.Error("expected invalid key to be rejected")
+	}
+}
+
+func TestValidateJobQuery(t *testing.T) {
+	order, err := ValidateJobQuery(&JobQuery{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order != "created_at DESC NULLS LAST, id DESC" {
+		t.Errorf("unexpected default order %q", order)
+	}
+
+	order, err = ValidateJobQuery(&JobQuery{SortBy: "payment_amount", SortOrder: "ASC", MinPayment: "1000", MaxPayment: "5000"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(order, "CAST(NULLIF(payment_amount, '') AS NUMERIC) ASC") {
+		t.Errorf("unexpected payment order %q", order)
+	}
+
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	invalid := []JobQuery{
+		{SortBy: "renter_address; DROP TABLE jobs"},
+		{SortOrder: "sideways"},
+		{MinPayment: "-1"},
+		{MaxPayment: "1e18"},
+		{CreatedFrom: &now, CreatedTo: &earlier},
+		{Labels: map[string]string{"Bad Key": ""}},
+	}
+	for i := range invalid {
+		if _, err := ValidateJobQuery(&invalid[i]); err == nil {
+			t.Errorf("query %d: expected error", i)
+		}
+	}
+
+	if pattern := containsPattern("100%_done"); pattern != `%100\%\_done%` {
+		t.Errorf("unexpected search pattern %q", pattern)
+	}
+}
@@ -0,0 +1,79 @@
+package job_dispatcher
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// maxJobSecrets caps how many secrets a single job may carry
+const maxJobSecrets = 32
+
+// minSecretCiphertextLength is the size of an ECIES ciphertext with an empty plaintext:
+// ephemeral public key (65) + IV (16) + HMAC-SHA256 tag (32)
+const minSecretCiphertextLength = 65 + 16 + 32
+
+// envNamePattern matches a valid environment variable name
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateSecrets checks that secrets are named like environment variables, do not shadow
+// plain env entries and are ECIES ciphertexts. Their plaintext is never seen by the backend.
+func ValidateSecrets(secrets map[string]string, env map[string]string) error {
+	if len(secrets) > maxJobSecrets {
+		return fmt.Errorf("a job can carry at most %d secrets", maxJobSecrets)
+	}
+
+	for name, ciphertext := range secrets {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("secret name %q is not a valid environment variable name", name)
+		}
+		if _, ok := env[name]; ok {
+			return fmt.Errorf("secret %s is also set as a plain environment variable", name)
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(ciphertext)
+		if err != nil {
+			return fmt.Errorf("secret %s must be base64-encoded: %w", name, err)
+		}
+		if len(raw) < minSecretCiphertextLength || raw[0] != 0x04 {
+			return fmt.Errorf("secret %s is not an ECIES ciphertext encrypted to the provider's key", name)
+		}
+	}
+
+	return nil
+}
+
+// EncryptSecret encrypts a secret value to a provider's hex-encoded public key, the same
+// way renters' clients must before submitting a spec
+func EncryptSecret(value, publicKeyHex string) (string, error) {
+	publicKey, err := parsePublicKey(publicKeyHex)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(publicKey), []byte(value), nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptSecret decrypts a secret from an assignment with the provider's wallet private key
+func DecryptSecret(ciphertext string, privateKey *ecdsa.PrivateKey) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+
+	plaintext, err := ecies.ImportECDSA(privateKey).Decrypt(raw, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,54 @@
+package job_dispatcher
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSecretRoundTrip(t *testing.T) {
+	providerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	publicKeyHex := hexutil.Encode(crypto.FromECDSAPub(&providerKey.PublicKey))
+
+	ciphertext, err := EncryptSecret("hf_token_value", publicKeyHex)
+	if err != nil {
+		t.Fatalf("failed to encrypt secret: %v", err)
+	}
+
+	secrets := map[string]string{"HF_TOKEN": ciphertext}
+	if err := ValidateSecrets(secrets, map[string]string{"BATCH_SIZE": "32"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	plaintext, err := DecryptSecret(ciphertext, providerKey)
+	if err != nil {
+		t.Fatalf("failed to decrypt secret: %v", err)
+	}
+	if plaintext != "hf_token_value" {
+		t.Errorf("expected hf_token_value, got %s", plaintext)
+	}
+
+	otherKey, _ := crypto.GenerateKey()
+	if _, err := DecryptSecret(ciphertext, otherKey); err == nil {
+		t.Error("expected decryption with another key to fail")
+	}
+
+	invalid := []struct {
+		secrets map[string]string
+		env     map[string]string
+	}{
+		{secrets: map[string]string{"1TOKEN": ciphertext}},
+		{secrets: map[string]string{"HF_TOKEN": ciphertext}, env: map[string]string{"HF_TOKEN": "plain"}},
+		{secrets: map[string]string{"HF_TOKEN": "hf_token_value"}},
+		{secrets: map[string]string{"HF_TOKEN": "aGZfdG9rZW5fdmFsdWU="}},
+	}
+	for i, tc := range invalid {
+		if err := ValidateSecrets(tc.secrets, tc.env); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -2,10 +2,20 @@ package job_dispatcher
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"lamda_backend/internal/auth"
+	"lamda_backend/internal/image_policy"
+	"lamda_backend/internal/node_registry"
 	"lamda_backend/pkg/blockchain"
 	"lamda_backend/pkg/contracts"
 	"lamda_backend/pkg/logger"
@@ -13,9 +23,66 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ServiceConfig holds tunable settings for the job dispatcher
+type ServiceConfig struct {
+	// LogTailSize is the number of log chunks retained per job for late joiners
+	LogTailSize int64
+	// LogRetention is how long retained log chunks are kept
+	LogRetention time.Duration
+	// ImagePolicy is the global image policy applied to every assignment
+	ImagePolicy image_policy.Rules
+	// RegistryTokenHosts are token service hosts trusted besides each registry's own host
+	RegistryTokenHosts []string
+	// RequireEncryption holds jobs queued until their provider's public key is known instead of
+	// sending plaintext assignments
+	RequireEncryption bool
+	// SigningKey signs every assignment so agents can verify it came from the dispatcher
+	SigningKey *ecdsa.PrivateKey
+	// AssignmentTTL is how long a signed assignment remains valid
+	AssignmentTTL time.Duration
+	// DefaultConcurrency limits providers that have not advertised a limit, 0 means unlimited
+	DefaultConcurrency int
+	// RenterTiers maps checksummed renter addresses to their queue priority
+	RenterTiers map[string]int
+	// TelemetryBucket is the window telemetry samples are downsampled into
+	TelemetryBucket time.Duration
+	// MatchWeights is how much each component counts towards provider match scores
+	MatchWeights MatchWeights
+}
+
+// imagePolicyTimeout bounds policy evaluation, including registry size lookups
+const imagePolicyTimeout = 20 * time.Second
+
+// Outbox relay tuning
+const (
+	outboxBatchSize     = 100
+	outboxFlushTimeout  = 5 * time.Second
+	outboxPollInterval  = 2 * time.Second
+	outboxPruneInterval = time.Hour
+	outboxRetention     = 24 * time.Hour
+)
+
+// loadPublishInterval is how often provider load is published when nothing changes
+const loadPublishInterval = 15 * time.Second
+
+// providerKeyRecheckInterval is how often jobs held for a provider without a known public key
+// are retried
+const providerKeyRecheckInterval = time.Minute
+
+// dispatchBackoff spaces out releases of a provider's queue after consecutive failed dispatches
+var dispatchBackoff = RetryPolicy{BackoffSeconds: 5, BackoffMultiplier: 2, MaxBackoffSeconds: 300}
+
+// errProviderKeyUnknown is returned when an assignment cannot be encrypted to its provider
+var errProviderKeyUnknown = errors.New("provider public key is unknown")
+
 // Service handles job dispatching operations
 type Service struct {
 	db                 *gorm.DB
@@ -23,17 +90,54 @@ type Service struct {
 	blockchain         *blockchain.EVMClient
 	logger             *logger.Logger
 	contractAddr       string
+	config             ServiceConfig
+	policyEngine       *image_policy.Engine
 	jobManagerContract *contracts.JobManager
+	chainID            string
+	// providerLocks serialize releasing each provider's queued jobs so its limit is not overrun;
+	// heldUntil records when each provider's pending queue release is due, so only one timer is
+	// armed at a time; dispatchFailures counts each provider's consecutive failed dispatches.
+	// queueMu guards the three maps.
+	queueMu          sync.Mutex
+	providerLocks    map[string]*sync.Mutex
+	heldUntil        map[string]time.Time
+	dispatchFailures map[string]int
+	// pipelineMu serializes pipeline updates so concurrent stage results are not lost
+	pipelineMu sync.Mutex
+	// verificationMu serializes resolving verification groups
+	verificationMu sync.Mutex
+	// telemetryMu serializes folding samples into metric buckets
+	telemetryMu sync.Mutex
+	// scheduler runs template cron schedules; scheduled maps template IDs to their entries
+	scheduler  *cron.Cron
+	scheduleMu sync.Mutex
+	scheduled  map[string]cron.EntryID
+	// outboxWake nudges the outbox relay after messages are committed
+	outboxWake chan struct{}
+	// loadWake nudges the load publisher after provider job counts change
+	loadWake chan struct{}
+	// reportNonces rejects replayed provider status reports and telemetry
+	reportNonces *reportNonces
 }
 
 // NewService creates a new job dispatcher service
-func NewService(db *gorm.DB, natsClient *nats.NATSClient, blockchain *blockchain.EVMClient, logger *logger.Logger, contractAddr string) *Service {
+func NewService(db *gorm.DB, natsClient *nats.NATSClient, blockchain *blockchain.EVMClient, logger *logger.Logger, contractAddr string, config ServiceConfig) *Service {
 	return &Service{
-		db:           db,
-		natsClient:   natsClient,
-		blockchain:   blockchain,
-		logger:       logger.WithService("job-dispatcher"),
-		contractAddr: contractAddr,
+		db:               db,
+		natsClient:       natsClient,
+		blockchain:       blockchain,
+		logger:           logger.WithService("job-dispatcher"),
+		contractAddr:     contractAddr,
+		config:           config,
+		policyEngine:     image_policy.NewEngine(config.ImagePolicy, image_policy.NewRegistrySizeResolver(imagePolicyTimeout, config.RegistryTokenHosts)),
+		scheduler:        cron.New(cron.WithLocation(time.UTC)),
+		scheduled:        make(map[string]cron.EntryID),
+		providerLocks:    make(map[string]*sync.Mutex),
+		heldUntil:        make(map[string]time.Time),
+		dispatchFailures: make(map[string]int),
+		outboxWake:       make(chan struct{}, 1),
+		loadWake:         make(chan struct{}, 1),
+		reportNonces:     newReportNonces(),
 	}
 }
 
@@ -49,14 +153,48 @@ func (s *Service) Start(ctx context.Context) error {
 	}
 	s.jobManagerContract = jobManagerContract
 
+	// Jobs record the chain they were funded on
+	chainID, err := s.blockchain.GetChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	s.chainID = chainID.String()
+
+	// Keep a bounded tail of provider log output for late joiners
+	if err := s.natsClient.CreateBoundedStream(JobLogStreamName, []string{JobLogSubject(">")}, s.config.LogTailSize, s.config.LogRetention); err != nil {
+		return fmt.Errorf("failed to create job log stream: %w", err)
+	}
+
 	// Subscribe to NATS queries
 	if err := s.subscribeToQueries(); err != nil {
 		return fmt.Errorf("failed to subscribe to queries: %w", err)
 	}
 
+	// Publish committed outbox messages, including any left unsent by a previous run
+	go s.runOutboxRelay(ctx)
+	go s.runLoadPublisher(ctx)
+
+	// Resolve verification groups whose jobs did not all finish in time
+	go s.runVerificationDeadlines(ctx)
+
+	// Dispatch jobs that were queued but not yet sent when the dispatcher last stopped
+	if err := s.releaseAllQueues(); err != nil {
+		return fmt.Errorf("failed to release queued jobs: %w", err)
+	}
+
 	// Start blockchain event listener
 	go s.listenToBlockchainEvents(ctx)
 
+	// Pick up retries that were waiting out their backoff
+	if err := s.resumeRetries(); err != nil {
+		return fmt.Errorf("failed to resume job retries: %w", err)
+	}
+
+	// Schedule recurring job templates
+	if err := s.startScheduler(ctx); err != nil {
+		return fmt.Errorf("failed to start template scheduler: %w", err)
+	}
+
 	s.logger.Info("Job dispatcher service started successfully")
 	return nil
 }
@@ -69,194 +207,3129 @@ func (s *Service) subscribeToQueries() error {
 		return fmt.Errorf("failed to subscribe to jobs.query: %w", err)
 	}
 
-	s.logger.Info("Subscribed to jobs.query")
-	return nil
+	// Subscribe to jobs.spec.submit subject
+	_, err = s.natsClient.SubscribeWithReply("jobs.spec.submit", s.handleJobSpecSubmission)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to jobs.spec.submit: %w", err)
+	}
+
+	// Subscribe to jobs.labels.set subject
+	_, err = s.natsClient.SubscribeWithReply("jobs.labels.set", s.handleJobLabels)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to jobs.labels.set: %w", err)
+	}
+
+	// Subscribe to jobs.attempts subject
+	_, err = s.natsClient.SubscribeWithReply("jobs.attempts", s.handleJobAttempts)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to jobs.attempts: %w", err)
+	}
+
+	// Subscribe to jobs.quote subject
+	_, err = s.natsClient.SubscribeWithReply("jobs.quote", s.handleJobQuote)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to jobs.quote: %w", err)
+	}
+
+	// Subscribe to jobs.match subject
+	_, err = s.natsClient.SubscribeWithReply("jobs.match", s.handleJobMatch)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to jobs.match: %w", err)
+	}
+
+	// Subscribe to dead letter subjects for operators
+	_, err = s.natsClient.SubscribeWithReply("deadletters.list", s.handleDeadLetterList)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to deadletters.list: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("deadletters.get", s.handleDeadLetterGet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to deadletters.get: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("deadletters.update", s.handleDeadLetterUpdate)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to deadletters.update: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("deadletters.replay", s.handleDeadLetterReplay)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to deadletters.replay: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("deadletters.discard", s.handleDeadLetterDiscard)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to deadletters.discard: %w", err)
+	}
+
+	// Subscribe to dispatcher.key subject so agents can fetch the assignment signing key
+	_, err = s.natsClient.SubscribeWithReply("dispatcher.key", s.handleDispatcherKey)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to dispatcher.key: %w", err)
+	}
+
+	// Subscribe to provider status reports to free slots as jobs finish
+	_, err = s.natsClient.Subscribe(JobStatusSubject("*"), s.handleJobStatusUpdate)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to job status updates: %w", err)
+	}
+
+	// Subscribe to providers returning from maintenance to release jobs held for them
+	_, err = s.natsClient.Subscribe(node_registry.ProviderAvailableSubject, s.handleProviderAvailable)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to provider availability: %w", err)
+	}
+
+	// Subscribe to provider telemetry samples
+	_, err = s.natsClient.Subscribe(JobTelemetrySubject("*"), s.handleTelemetrySample)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to job telemetry: %w", err)
+	}
+
+	// Subscribe to jobs.metrics subject
+	_, err = s.natsClient.SubscribeWithReply("jobs.metrics", s.handleJobMetrics)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to jobs.metrics: %w", err)
+	}
+
+	// Subscribe to jobs.results subject
+	_, err = s.natsClient.SubscribeWithReply("jobs.results", s.handleJobResults)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to jobs.results: %w", err)
+	}
+
+	// Subscribe to pipeline subjects
+	_, err = s.natsClient.SubscribeWithReply("pipelines.create", s.handlePipelineCreate)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to pipelines.create: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("pipelines.get", s.handlePipelineGet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to pipelines.get: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("pipelines.stage.submit", s.handlePipelineStageSubmit)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to pipelines.stage.submit: %w", err)
+	}
+
+	// Subscribe to job template subjects
+	_, err = s.natsClient.SubscribeWithReply("templates.save", s.handleTemplateSave)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to templates.save: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("templates.list", s.handleTemplateList)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to templates.list: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("templates.delete", s.handleTemplateDelete)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to templates.delete: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("templates.run", s.handleTemplateRun)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to templates.run: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("templates.runs", s.handleTemplateRuns)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to templates.runs: %w", err)
+	}
+
+	// Subscribe to verification subjects
+	_, err = s.natsClient.SubscribeWithReply("verifications.create", s.handleVerificationCreate)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to verifications.create: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("verifications.get", s.handleVerificationGet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to verifications.get: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("verifications.provider.stats", s.handleVerificationStats)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to verifications.provider.stats: %w", err)
+	}
+
+	s.logger.Info("Subscribed to jobs.query, jobs.spec.submit, jobs.labels, jobs.attempts, jobs.quote, jobs.match, deadletters, dispatcher.key, jobs.status, nodes.available, jobs.telemetry, jobs.metrics, jobs.results, pipelines, templates and verifications")
+	return nil
+}
+
+// handleVerificationCreate handles verification group registrations from renters
+func (s *Service) handleVerificationCreate(data []byte) ([]byte, error) {
+	var request VerificationRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal verification request: %w", err)
+	}
+
+	response := VerificationResponse{}
+	group, err := s.CreateVerificationGroup(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Group = group
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleVerificationGet handles lookups of a single verification group
+func (s *Service) handleVerificationGet(data []byte) ([]byte, error) {
+	var query VerificationQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+
+	response := VerificationResponse{}
+	group, err := s.GetVerificationGroup(query.GroupID)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Group = group
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleVerificationStats handles queries for a provider's verification outcomes
+func (s *Service) handleVerificationStats(data []byte) ([]byte, error) {
+	var query VerificationQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+
+	stats, err := s.GetProviderVerificationStats(query.ProviderAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification stats: %w", err)
+	}
+
+	responseData, err := json.Marshal(stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleTemplateSave handles template creation and updates from renters
+func (s *Service) handleTemplateSave(data []byte) ([]byte, error) {
+	var template JobTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template: %w", err)
+	}
+
+	response := JobTemplateResponse{}
+	if err := s.SaveTemplate(&template); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Template = &template
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleTemplateList handles listing a renter's templates
+func (s *Service) handleTemplateList(data []byte) ([]byte, error) {
+	var request JobTemplateRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := JobTemplateResponse{}
+	templates, err := s.ListTemplates(request.RenterAddress)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Templates = templates
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleTemplateDelete handles template deletion
+func (s *Service) handleTemplateDelete(data []byte) ([]byte, error) {
+	var request JobTemplateRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := JobTemplateResponse{}
+	if err := s.DeleteTemplate(request); err != nil {
+		response.Error = err.Error()
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleTemplateRun handles on-demand template runs
+func (s *Service) handleTemplateRun(data []byte) ([]byte, error) {
+	var request JobTemplateRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := JobTemplateResponse{}
+	run, err := s.RunTemplate(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Run = run
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleTemplateRuns handles listing a template's runs
+func (s *Service) handleTemplateRuns(data []byte) ([]byte, error) {
+	var request JobTemplateRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := JobTemplateResponse{}
+	runs, err := s.ListTemplateRuns(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Runs = runs
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handlePipelineCreate handles pipeline registrations from renters
+func (s *Service) handlePipelineCreate(data []byte) ([]byte, error) {
+	var pipeline Pipeline
+	if err := json.Unmarshal(data, &pipeline); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pipeline: %w", err)
+	}
+
+	response := PipelineResponse{}
+	if err := s.CreatePipeline(&pipeline); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Pipeline = &pipeline
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handlePipelineGet handles lookups of a single pipeline
+func (s *Service) handlePipelineGet(data []byte) ([]byte, error) {
+	var query PipelineQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+
+	response := PipelineResponse{}
+	pipeline, err := s.GetPipeline(query.PipelineID)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Pipeline = pipeline
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handlePipelineStageSubmit handles job spec registration for ready pipeline stages
+func (s *Service) handlePipelineStageSubmit(data []byte) ([]byte, error) {
+	var submission PipelineStageSubmission
+	if err := json.Unmarshal(data, &submission); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stage submission: %w", err)
+	}
+
+	response := PipelineResponse{}
+	pipeline, err := s.SubmitPipelineStage(submission)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Pipeline = pipeline
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobStatusUpdate handles status reports published by providers
+func (s *Service) handleJobStatusUpdate(data []byte) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		s.logger.Error("Failed to unmarshal job status update", "error", err)
+		return
+	}
+
+	if err := s.ProcessJobStatusUpdate(request); err != nil {
+		s.logger.Error("Failed to process job status update", "error", err, "provider", request.Address)
+	}
+}
+
+// handleProviderAvailable releases the queued jobs of a provider that is taking jobs again
+func (s *Service) handleProviderAvailable(data []byte) {
+	var lookup node_registry.NodeLookup
+	if err := json.Unmarshal(data, &lookup); err != nil {
+		s.logger.Error("Failed to unmarshal provider availability", "error", err)
+		return
+	}
+
+	if err := s.releaseQueuedJobs(common.HexToAddress(lookup.WalletAddress).Hex()); err != nil {
+		s.logger.Error("Failed to release queued jobs for available provider", "error", err, "provider", lookup.WalletAddress)
+	}
+}
+
+// handleTelemetrySample handles telemetry samples published by providers
+func (s *Service) handleTelemetrySample(data []byte) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		s.logger.Error("Failed to unmarshal telemetry sample", "error", err)
+		return
+	}
+
+	if err := s.IngestTelemetry(request); err != nil {
+		s.logger.Warn("Failed to ingest telemetry sample", "error", err, "provider", request.Address)
+	}
+}
+
+// handleJobResults handles queries for a job's result manifest
+func (s *Service) handleJobResults(data []byte) ([]byte, error) {
+	var query JobQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+
+	response := JobResultsResponse{}
+	job, err := s.GetJobByID(query.JobID)
+	if err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Manifest = job.ResultManifest
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobMetrics handles queries for a job's downsampled telemetry
+func (s *Service) handleJobMetrics(data []byte) ([]byte, error) {
+	var query JobQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+
+	response := JobMetricsResponse{}
+	metrics, err := s.GetJobMetrics(query.JobID)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Metrics = metrics
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobLabels handles renters replacing their jobs' labels
+func (s *Service) handleJobLabels(data []byte) ([]byte, error) {
+	var request JobLabelsRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := JobLabelsResponse{}
+	job, err := s.SetJobLabels(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Job = job
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobAttempts handles queries for a job's attempt history
+func (s *Service) handleJobAttempts(data []byte) ([]byte, error) {
+	var query JobQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+
+	response := JobAttemptsResponse{}
+	attempts, err := s.GetJobAttempts(query.JobID)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Attempts = attempts
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobQuote handles requests for provider suggestions and cost estimates
+func (s *Service) handleJobQuote(data []byte) ([]byte, error) {
+	var request QuoteRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := QuoteResponse{}
+	quotes, err := s.QuoteJob(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Quotes = quotes
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobMatch handles provider recommendation requests
+func (s *Service) handleJobMatch(data []byte) ([]byte, error) {
+	var request MatchRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := MatchResponse{}
+	matches, err := s.MatchProviders(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Matches = matches
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleDeadLetterList handles listings of dead letters
+func (s *Service) handleDeadLetterList(data []byte) ([]byte, error) {
+	var request DeadLetterRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := DeadLetterResponse{}
+	entries, err := s.ListDeadLetters(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Entries = entries
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleDeadLetterGet handles lookups of a single dead letter
+func (s *Service) handleDeadLetterGet(data []byte) ([]byte, error) {
+	var request DeadLetterRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := DeadLetterResponse{}
+	entry, err := s.GetDeadLetter(request.ID)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Entry = entry
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleDeadLetterUpdate handles operator fixes to a dead letter's payload
+func (s *Service) handleDeadLetterUpdate(data []byte) ([]byte, error) {
+	var request DeadLetterRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := DeadLetterResponse{}
+	entry, err := s.UpdateDeadLetter(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Entry = entry
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleDeadLetterReplay handles operator replays of dead letters
+func (s *Service) handleDeadLetterReplay(data []byte) ([]byte, error) {
+	var request DeadLetterRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := DeadLetterResponse{}
+	entry, err := s.ReplayDeadLetter(request.ID)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Entry = entry
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleDeadLetterDiscard handles operators discarding dead letters
+func (s *Service) handleDeadLetterDiscard(data []byte) ([]byte, error) {
+	var request DeadLetterRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := DeadLetterResponse{}
+	entry, err := s.DiscardDeadLetter(request.ID)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Entry = entry
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleDispatcherKey handles requests for the dispatcher's assignment signing key
+func (s *Service) handleDispatcherKey(data []byte) ([]byte, error) {
+	responseData, err := json.Marshal(s.DispatcherKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobQuery handles queries for jobs
+func (s *Service) handleJobQuery(data []byte) ([]byte, error) {
+	var query JobQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+	}
+
+	response := JobsResponse{}
+	jobs, err := s.GetJobs(query)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Jobs = jobs
+	response.Count = len(jobs)
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleJobSpecSubmission handles off-chain job specs submitted by renters
+func (s *Service) handleJobSpecSubmission(data []byte) ([]byte, error) {
+	var spec JobSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job spec: %w", err)
+	}
+
+	response := JobSpecResponse{Issues: []RequirementIssue{}}
+	if err := s.SubmitJobSpec(&spec); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Spec = &spec
+		response.Issues = s.checkProviderRequirements(spec.ProviderAddress, spec.Requirements)
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// listenToBlockchainEvents listens for blockchain events
+func (s *Service) listenToBlockchainEvents(ctx context.Context) {
+	s.logger.Info("Starting blockchain event listener (polling mode)")
+
+	// Get the latest block number to start listening from
+	latestBlock, err := s.blockchain.GetLatestBlockNumber(ctx)
+	if err != nil {
+		s.logger.Error("Failed to get latest block number", "error", err)
+		return
+	}
+
+	// Start from the latest block
+	fromBlock := uint64(latestBlock)
+	s.logger.Info("Starting event listener from block", "block", fromBlock)
+
+	// Poll for events every 10 seconds
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping blockchain event listener")
+			return
+		case <-ticker.C:
+			// Poll for new events
+			if err := s.pollForEvents(ctx, fromBlock); err != nil {
+				s.logger.Error("Failed to poll for events", "error", err)
+			} else {
+				// Update fromBlock to current block
+				if currentBlock, err := s.blockchain.GetLatestBlockNumber(ctx); err == nil {
+					fromBlock = uint64(currentBlock)
+				}
+			}
+		}
+	}
+}
+
+// pollForEvents polls for blockchain events
+func (s *Service) pollForEvents(ctx context.Context, fromBlock uint64) error {
+	// Get current block
+	currentBlock, err := s.blockchain.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current block: %w", err)
+	}
+
+	// Only process if there are new blocks
+	if uint64(currentBlock) <= fromBlock {
+		return nil
+	}
+
+	// Query for JobCreated events
+	jobCreatedEvents, err := s.jobManagerContract.FilterJobCreated(&bind.FilterOpts{
+		Start:   fromBlock,
+		End:     &currentBlock,
+		Context: ctx,
+	}, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter JobCreated events: %w", err)
+	}
+
+	// Process JobCreated events
+	for jobCreatedEvents.Next() {
+		event := jobCreatedEvents.Event
+		s.logger.Info("Received JobCreated event", "job_id", fmt.Sprintf("0x%x", event.JobId))
+		if err := s.processJobCreatedEvent(event); err != nil {
+			s.logger.Error("Failed to process JobCreated event", "error", err, "job_id", fmt.Sprintf("0x%x", event.JobId))
+		}
+	}
+
+	// Query for JobConfirmed events so confirmed jobs free their provider's slot
+	jobConfirmedEvents, err := s.jobManagerContract.FilterJobConfirmed(&bind.FilterOpts{
+		Start:   fromBlock,
+		End:     &currentBlock,
+		Context: ctx,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to filter JobConfirmed events: %w", err)
+	}
+
+	// Process JobConfirmed events
+	for jobConfirmedEvents.Next() {
+		jobID := fmt.Sprintf("0x%x", jobConfirmedEvents.Event.JobId)
+		s.logger.Info("Received JobConfirmed event", "job_id", jobID)
+		if err := s.completeJob(jobID); err != nil {
+			s.logger.Error("Failed to process JobConfirmed event", "error", err, "job_id", jobID)
+			s.recordDeadLetter(DeadLetterJobConfirmed, jobID, deadLetterJobPayload{JobID: jobID}, err)
+		}
+	}
+
+	return nil
+}
+
+// processJobCreatedEvent processes a JobCreated event from the blockchain
+func (s *Service) processJobCreatedEvent(event *contracts.JobManagerJobCreated) error {
+	s.logger.Info("Processing JobCreated event", "job_id", fmt.Sprintf("0x%x", event.JobId))
+
+	// Convert the event to our internal format
+	jobEvent := JobCreatedEvent{
+		JobID:           fmt.Sprintf("0x%x", event.JobId),
+		RenterAddress:   event.Renter.Hex(),
+		ProviderAddress: event.Provider.Hex(),
+		PaymentAmount:   event.Payment.String(),
+		BlockNumber:     event.Raw.BlockNumber,
+		TransactionHash: event.Raw.TxHash.Hex(),
+	}
+
+	// Process the event using existing logic, keeping failures for operators to replay
+	if err := s.ProcessJobCreatedEvent(jobEvent); err != nil {
+		s.recordDeadLetter(DeadLetterJobCreated, jobEvent.JobID, jobEvent, err)
+		return err
+	}
+
+	return nil
+}
+
+// ProcessJobCreatedEvent processes a JobCreated event
+func (s *Service) ProcessJobCreatedEvent(event JobCreatedEvent) error {
+	s.logger.Info("Processing JobCreated event", "job_id", event.JobID)
+
+	// Replayed events for jobs that were already created have nothing left to do
+	var existingJobs int64
+	if err := s.db.Model(&Job{}).Where("id = ?", event.JobID).Count(&existingJobs).Error; err != nil {
+		return fmt.Errorf("failed to check job: %w", err)
+	}
+	if existingJobs > 0 {
+		s.logger.Info("Job already created, skipping JobCreated event", "job_id", event.JobID)
+		return nil
+	}
+
+	// Fill in the off-chain details the renter registered for this job
+	if err := s.applyJobSpec(&event); err != nil {
+		return fmt.Errorf("failed to load job spec: %w", err)
+	}
+
+	// Create job record
+	job := &Job{
+		ID:              event.JobID,
+		RenterAddress:   event.RenterAddress,
+		ProviderAddress: event.ProviderAddress,
+		DockerImage:     event.DockerImage,
+		InputFileCID:    event.InputFileCID,
+		PaymentAmount:   event.PaymentAmount,
+		TransactionHash: event.TransactionHash,
+		Status:          JobStatusQueued,
+		Priority:        s.config.RenterTiers[common.HexToAddress(event.RenterAddress).Hex()],
+		Requirements:    event.Requirements,
+		RetryPolicy:     event.RetryPolicy,
+		WorkloadClass:   event.WorkloadClass,
+		Labels:          event.Labels,
+		ChainID:         s.chainID,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	// Check the provider can actually run the job before sending it anything
+	job.ValidationIssues = s.checkProviderRequirements(event.ProviderAddress, event.Requirements)
+	if HasBlockingIssues(job.ValidationIssues) {
+		now := time.Now()
+		job.Status = JobStatusFailed
+		job.FailedAt = &now
+		job.ErrorMessage = "dispatch blocked: " + blockingSummary(job.ValidationIssues)
+
+		if err := s.db.Create(job).Error; err != nil {
+			return fmt.Errorf("failed to create job: %w", err)
+		}
+
+		s.logger.Warn("Job not dispatched due to provider requirement mismatch", "job_id", event.JobID, "provider", event.ProviderAddress, "reason", job.ErrorMessage)
+		return s.handleJobFinished(job.ID)
+	}
+
+	// Save job to database
+	if err := s.db.Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	// Queue the job and dispatch whatever fits within the provider's concurrency limit
+	if err := s.releaseQueuedJobs(event.ProviderAddress); err != nil {
+		return fmt.Errorf("failed to dispatch job: %w", err)
+	}
+
+	s.logger.Info("Job created and queued successfully", "job_id", event.JobID)
+	return nil
+}
+
+// releaseQueuedJobs dispatches a provider's queued jobs in priority order until its
+// concurrency limit is reached
+func (s *Service) releaseQueuedJobs(providerAddress string) error {
+	// Look the provider up before locking so a slow registry only delays this provider
+	provider, err := s.lookupProvider(providerAddress)
+	if err != nil {
+		s.logger.Warn("Node registry unavailable, using default concurrency limit", "error", err, "provider", providerAddress)
+	}
+
+	lock := s.providerLock(providerAddress)
+	lock.Lock()
+	defer lock.Unlock()
+	defer s.wakeLoadPublisher()
+
+	// Providers in maintenance or outside their availability windows keep their jobs queued
+	// until the node registry announces them back or they are expected back
+	if provider != nil && provider.Unavailable {
+		s.logger.Info("Provider unavailable, holding queued jobs", "provider", providerAddress, "reason", provider.UnavailableReason, "available_at", provider.AvailableAt)
+		if provider.AvailableAt != nil {
+			s.holdQueue(providerAddress, *provider.AvailableAt)
+		}
+		return nil
+	}
+
+	// Assignments are only sent encrypted, so jobs wait until the registry has recovered the
+	// provider's public key from one of its transactions
+	if s.config.RequireEncryption && provider != nil && provider.PublicKey == "" {
+		s.logger.Info("Provider public key unknown, holding queued jobs", "provider", providerAddress)
+		s.holdQueue(providerAddress, time.Now().Add(providerKeyRecheckInterval))
+		return nil
+	}
+
+	limit := s.providerConcurrency(provider)
+
+	// Jobs waiting out a retry backoff stay queued but are not released yet; the queue is
+	// released again when the earliest backoff ends
+	now := time.Now()
+	var queued []Job
+	if err := s.db.Where("provider_address = ? AND status = ? AND (next_retry_at IS NULL OR next_retry_at <= ?)", providerAddress, JobStatusQueued, now).
+		Find(&queued).Error; err != nil {
+		return fmt.Errorf("failed to load queued jobs: %w", err)
+	}
+	SortQueue(queued)
+
+	var nextRetry Job
+	err = s.db.Select("next_retry_at").
+		Where("provider_address = ? AND status = ? AND next_retry_at > ?", providerAddress, JobStatusQueued, now).
+		Order("next_retry_at").
+		First(&nextRetry).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return fmt.Errorf("failed to load next job retry: %w", err)
+	}
+	if err == nil && nextRetry.NextRetryAt != nil {
+		s.holdQueue(providerAddress, *nextRetry.NextRetryAt)
+	}
+
+	for _, job := range queued {
+		active, err := s.countActiveJobs(providerAddress)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && active >= int64(limit) {
+			s.logger.Info("Provider at concurrency limit, holding queued jobs", "provider", providerAddress, "active", active, "queued", len(queued))
+			break
+		}
+
+		event, err := s.eventFromJob(job)
+		if err != nil {
+			s.logger.Error("Failed to rebuild queued job", "error", err, "job_id", job.ID)
+			s.recordDeadLetter(DeadLetterDispatch, job.ID, deadLetterJobPayload{JobID: job.ID}, err)
+			continue
+		}
+
+		// A failed dispatch leaves the job queued at the head of the queue, so jobs behind it are
+		// not dispatched out of order; the queue is released again after a backoff
+		if err := s.dispatchJobToProvider(event); err != nil {
+			if errors.Is(err, errProviderKeyUnknown) {
+				s.logger.Info("Provider public key unknown, holding queued jobs", "provider", providerAddress)
+				s.holdQueue(providerAddress, time.Now().Add(providerKeyRecheckInterval))
+				break
+			}
+			retryAt := time.Now().Add(dispatchBackoff.Backoff(s.recordDispatchFailure(providerAddress)))
+			s.logger.Error("Failed to dispatch queued job, holding queue", "error", err, "job_id", job.ID, "retry_at", retryAt)
+			s.recordDeadLetter(DeadLetterDispatch, job.ID, deadLetterJobPayload{JobID: job.ID}, err)
+			s.holdQueue(providerAddress, retryAt)
+			break
+		}
+		s.resetDispatchFailures(providerAddress)
+		s.resolveDeadLetters(DeadLetterDispatch, job.ID)
+	}
+
+	return nil
+}
+
+// providerConcurrency returns how many jobs a provider may run at once, 0 meaning unlimited.
+// A nil provider, unknown to or not found in the registry, gets the default limit.
+func (s *Service) providerConcurrency(provider *node_registry.Provider) int {
+	if provider != nil && provider.MaxConcurrentJobs > 0 {
+		return provider.MaxConcurrentJobs
+	}
+
+	return s.config.DefaultConcurrency
+}
+
+// countActiveJobs counts a provider's jobs that have been dispatched but not finished
+func (s *Service) countActiveJobs(providerAddress string) (int64, error) {
+	var count int64
+	if err := s.db.Model(&Job{}).
+		Where("provider_address = ? AND status IN ?", providerAddress, []JobStatus{JobStatusAssigned, JobStatusRunning}).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count active jobs: %w", err)
+	}
+
+	return count, nil
+}
+
+// eventFromJob rebuilds the dispatch details of a queued job from its record and spec
+func (s *Service) eventFromJob(job Job) (JobCreatedEvent, error) {
+	event := JobCreatedEvent{
+		JobID:           job.ID,
+		RenterAddress:   job.RenterAddress,
+		ProviderAddress: job.ProviderAddress,
+		DockerImage:     job.DockerImage,
+		InputFileCID:    job.InputFileCID,
+		Requirements:    job.Requirements,
+		PaymentAmount:   job.PaymentAmount,
+		TransactionHash: job.TransactionHash,
+	}
+
+	if err := s.applyJobSpec(&event); err != nil {
+		return event, err
+	}
+
+	return event, nil
+}
+
+// ProcessJobStatusUpdate applies a status report signed by a job's provider to the job and
+// releases queued jobs once it finishes
+func (s *Service) ProcessJobStatusUpdate(request auth.SignedRequest) error {
+	var update JobStatusUpdate
+	if err := request.Decode(&update); err != nil {
+		return fmt.Errorf("invalid signed status report: %w", err)
+	}
+	if err := ValidateStatusReport(update, time.Now()); err != nil {
+		return err
+	}
+
+	switch update.Status {
+	case JobStatusRunning, JobStatusCompleted, JobStatusFailed:
+	default:
+		return fmt.Errorf("unsupported status in provider report: %s", update.Status)
+	}
+
+	job, err := s.GetJobByID(update.JobID)
+	if err != nil {
+		return err
+	}
+
+	// Only jobs the provider is working on can be moved forward by its reports
+	if job.Status != JobStatusAssigned && job.Status != JobStatusRunning {
+		return fmt.Errorf("job %s is %s, ignoring %s report", job.ID, job.Status, update.Status)
+	}
+	if err := s.verifyProviderReport(request, job, update.Attempt, update.Nonce); err != nil {
+		return err
+	}
+
+	outputs := map[string]interface{}{}
+	if update.Manifest != nil {
+		if update.Status == JobStatusRunning {
+			return fmt.Errorf("result manifest for job %s sent before it finished", job.ID)
+		}
+		if err := ValidateManifest(update.Manifest); err != nil {
+			return fmt.Errorf("invalid result manifest: %w", err)
+		}
+
+		// Map updates bypass the field's JSON serializer, so store the encoded manifest
+		manifest, err := json.Marshal(update.Manifest)
+		if err != nil {
+			return fmt.Errorf("failed to marshal result manifest: %w", err)
+		}
+		outputs["result_manifest"] = string(manifest)
+	}
+	if update.OutputFileCID != "" {
+		outputs["output_file_cid"] = update.OutputFileCID
+	}
+	if update.OutputHash != "" {
+		outputs["output_hash"] = normalizeOutputHash(update.OutputHash)
+	}
+	if len(outputs) > 0 {
+		if err := s.db.Model(&Job{}).Where("id = ?", job.ID).Updates(outputs).Error; err != nil {
+			return fmt.Errorf("failed to update job outputs: %w", err)
+		}
+	}
+
+	class := ErrorClass("")
+	if update.Status == JobStatusFailed {
+		class = ClassifyError(update.ErrorClass, update.Error)
+	}
+	if err := s.recordAttempt(job, update.Status, class, update.Error); err != nil {
+		return err
+	}
+
+	// Transient failures go back into the provider's queue under the same job ID
+	if update.Status == JobStatusFailed && job.RetryPolicy.ShouldRetry(job.Attempts, class) {
+		if err := s.scheduleRetry(job, class, update.Error); err != nil {
+			return err
+		}
+		return s.releaseQueuedJobs(job.ProviderAddress)
+	}
+
+	if err := s.UpdateJobStatus(job.ID, update.Status, update.Error); err != nil {
+		return err
+	}
+
+	if update.Status.IsTerminal() {
+		return s.releaseQueuedJobs(job.ProviderAddress)
+	}
+
+	// Assigned jobs that start running change the provider's load without freeing a slot
+	s.wakeLoadPublisher()
+	return nil
+}
+
+// recordAttempt updates a job's current attempt with a provider status report
+func (s *Service) recordAttempt(job *Job, status JobStatus, class ErrorClass, errorMessage string) error {
+	var attempt JobAttempt
+	if err := s.db.Where("job_id = ? AND attempt = ?", job.ID, job.Attempts).First(&attempt).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// Jobs dispatched before attempts were recorded have no history
+			return nil
+		}
+		return fmt.Errorf("failed to get job attempt: %w", err)
+	}
+
+	now := time.Now()
+	attempt.Status = status
+	if status == JobStatusRunning {
+		attempt.StartedAt = &now
+	}
+	if status.IsTerminal() {
+		attempt.FinishedAt = &now
+		attempt.DurationSeconds = now.Sub(attempt.AssignedAt).Seconds()
+		attempt.ErrorClass = class
+		attempt.ErrorMessage = errorMessage
+	}
+
+	if err := s.db.Save(&attempt).Error; err != nil {
+		return fmt.Errorf("failed to save job attempt: %w", err)
+	}
+
+	return nil
+}
+
+// scheduleRetry puts a failed job back in its provider's queue once its backoff has passed
+func (s *Service) scheduleRetry(job *Job, class ErrorClass, errorMessage string) error {
+	nextRetryAt := time.Now().Add(job.RetryPolicy.Backoff(job.Attempts))
+
+	if err := s.db.Model(&Job{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":        JobStatusQueued,
+			"next_retry_at": nextRetryAt,
+			"error_message": fmt.Sprintf("attempt %d failed (%s): %s", job.Attempts, class, errorMessage),
+			"updated_at":    time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to schedule job retry: %w", err)
+	}
+
+	s.holdQueue(job.ProviderAddress, nextRetryAt)
+
+	s.logger.Warn("Job attempt failed, retry scheduled", "job_id", job.ID, "attempt", job.Attempts, "error_class", class, "next_retry_at", nextRetryAt)
+	return nil
+}
+
+// providerLock returns the lock serializing queue releases for a provider
+func (s *Service) providerLock(providerAddress string) *sync.Mutex {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	lock, ok := s.providerLocks[providerAddress]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.providerLocks[providerAddress] = lock
+	}
+	return lock
+}
+
+// recordDispatchFailure counts a failed dispatch to a provider and returns its consecutive failures
+func (s *Service) recordDispatchFailure(providerAddress string) int {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	s.dispatchFailures[providerAddress]++
+	return s.dispatchFailures[providerAddress]
+}
+
+// resetDispatchFailures clears a provider's failed dispatch count after a successful dispatch
+func (s *Service) resetDispatchFailures(providerAddress string) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	delete(s.dispatchFailures, providerAddress)
+}
+
+// holdQueue releases a provider's queued jobs at a retry backoff's end or once it is expected
+// to be available, unless a release is already due by then. A release superseded by an
+// earlier one does nothing, so each provider has at most one pending release.
+func (s *Service) holdQueue(providerAddress string, at time.Time) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	if due, ok := s.heldUntil[providerAddress]; ok && !due.After(at) {
+		return
+	}
+	s.heldUntil[providerAddress] = at
+
+	time.AfterFunc(time.Until(at), func() {
+		s.queueMu.Lock()
+		due, ok := s.heldUntil[providerAddress]
+		current := ok && due.Equal(at)
+		if current {
+			delete(s.heldUntil, providerAddress)
+		}
+		s.queueMu.Unlock()
+		if !current {
+			return
+		}
+
+		if err := s.releaseQueuedJobs(providerAddress); err != nil {
+			s.logger.Error("Failed to release queued jobs", "error", err, "provider", providerAddress)
+		}
+	})
+}
+
+// resumeRetries re-arms the backoff timers of jobs that were waiting to be retried when the
+// dispatcher last stopped
+func (s *Service) resumeRetries() error {
+	var jobs []Job
+	if err := s.db.Where("status = ? AND next_retry_at IS NOT NULL", JobStatusQueued).Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to load jobs awaiting retry: %w", err)
+	}
+
+	for _, job := range jobs {
+		s.holdQueue(job.ProviderAddress, *job.NextRetryAt)
+	}
+
+	return nil
+}
+
+// IngestTelemetry folds a telemetry sample signed by a job's provider into the job's metric bucket for
+// the sample's window. Samples are only accepted while the job is on the provider.
+func (s *Service) IngestTelemetry(request auth.SignedRequest) error {
+	var sample TelemetrySample
+	if err := request.Decode(&sample); err != nil {
+		return fmt.Errorf("invalid signed telemetry sample: %w", err)
+	}
+	if err := ValidateTelemetrySample(&sample, time.Now()); err != nil {
+		return err
+	}
+
+	job, err := s.GetJobByID(sample.JobID)
+	if err != nil {
+		return err
+	}
+	if job.Status != JobStatusAssigned && job.Status != JobStatusRunning {
+		return fmt.Errorf("job %s is %s, ignoring telemetry", job.ID, job.Status)
+	}
+	if err := s.verifyProviderReport(request, job, sample.Attempt, sample.Nonce); err != nil {
+		return err
+	}
+
+	s.telemetryMu.Lock()
+	defer s.telemetryMu.Unlock()
+
+	bucketStart := TelemetryBucketStart(sample.Timestamp, s.config.TelemetryBucket)
+
+	var bucket JobMetricBucket
+	if err := s.db.Where("job_id = ? AND bucket_start = ?", job.ID, bucketStart).First(&bucket).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to get metric bucket: %w", err)
+		}
+		bucket = JobMetricBucket{JobID: job.ID, BucketStart: bucketStart}
+	}
+
+	bucket.Add(sample)
+	if err := s.db.Save(&bucket).Error; err != nil {
+		return fmt.Errorf("failed to save metric bucket: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobMetrics returns a job's downsampled telemetry with a summary
+func (s *Service) GetJobMetrics(jobID string) (*JobMetrics, error) {
+	job, err := s.GetJobByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buckets []JobMetricBucket
+	if err := s.db.Where("job_id = ?", job.ID).Order("bucket_start ASC").Find(&buckets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get metric buckets: %w", err)
+	}
+
+	// Compare reported VRAM use against what the provider registered
+	registeredVRAM := 0
+	provider, err := s.lookupProvider(job.ProviderAddress)
+	if err != nil {
+		s.logger.Warn("Node registry unavailable, skipping VRAM check", "error", err, "provider", job.ProviderAddress)
+	}
+	if provider != nil {
+		registeredVRAM = provider.VRAM
+	}
+
+	return &JobMetrics{
+		JobID:         job.ID,
+		BucketSeconds: int(s.config.TelemetryBucket / time.Second),
+		Summary:       SummarizeMetrics(buckets, registeredVRAM),
+		Buckets:       buckets,
+	}, nil
+}
+
+// SetJobLabels replaces the labels of a job owned by the requesting renter
+func (s *Service) SetJobLabels(request JobLabelsRequest) (*Job, error) {
+	if err := ValidateLabels(request.Labels); err != nil {
+		return nil, err
+	}
+
+	job, err := s.GetJobByID(request.JobID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(job.RenterAddress, request.RenterAddress) {
+		return nil, fmt.Errorf("job %s belongs to another renter", job.ID)
+	}
+
+	job.Labels = request.Labels
+	if err := s.db.Model(job).Select("labels", "updated_at").Updates(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to update job labels: %w", err)
+	}
+
+	s.logger.Info("Job labels updated", "job_id", job.ID, "labels", len(job.Labels))
+	return job, nil
+}
+
+// GetJobAttempts returns every dispatch attempt of a job, oldest first
+func (s *Service) GetJobAttempts(jobID string) ([]JobAttempt, error) {
+	var attempts []JobAttempt
+	if err := s.db.Where("job_id = ?", jobID).Order("attempt ASC").Find(&attempts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get job attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// recordDeadLetter stores a failed operation for operators, or bumps the attempt count of
+// its pending entry if it has failed before
+func (s *Service) recordDeadLetter(kind DeadLetterKind, jobID string, payload interface{}, cause error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		s.logger.Error("Failed to marshal dead letter payload", "error", err, "kind", kind, "job_id", jobID)
+		return
+	}
+
+	now := time.Now()
+	var entry DeadLetter
+	err = s.db.Where("kind = ? AND job_id = ? AND status = ?", kind, jobID, DeadLetterStatusPending).First(&entry).Error
+	switch {
+	case err == gorm.ErrRecordNotFound:
+		entry = DeadLetter{
+			ID:     uuid.NewString(),
+			Kind:   kind,
+			JobID:  jobID,
+			Status: DeadLetterStatusPending,
+		}
+	case err != nil:
+		s.logger.Error("Failed to get dead letter", "error", err, "kind", kind, "job_id", jobID)
+		return
+	}
+
+	entry.Payload = string(data)
+	entry.Error = cause.Error()
+	entry.Attempts++
+	entry.LastAttemptAt = now
+
+	if err := s.db.Save(&entry).Error; err != nil {
+		s.logger.Error("Failed to save dead letter", "error", err, "kind", kind, "job_id", jobID)
+		return
+	}
+
+	s.logger.Warn("Recorded dead letter", "id", entry.ID, "kind", kind, "job_id", jobID, "attempts", entry.Attempts)
+}
+
+// resolveDeadLetters closes pending entries for an operation that has since succeeded
+func (s *Service) resolveDeadLetters(kind DeadLetterKind, jobID string) {
+	now := time.Now()
+	if err := s.db.Model(&DeadLetter{}).
+		Where("kind = ? AND job_id = ? AND status = ?", kind, jobID, DeadLetterStatusPending).
+		Updates(map[string]interface{}{
+			"status":      DeadLetterStatusResolved,
+			"resolved_at": now,
+			"updated_at":  now,
+		}).Error; err != nil {
+		s.logger.Error("Failed to resolve dead letters", "error", err, "kind", kind, "job_id", jobID)
+	}
+}
+
+// ListDeadLetters lists dead letters, newest first, optionally filtered by kind and status
+func (s *Service) ListDeadLetters(request DeadLetterRequest) ([]DeadLetter, error) {
+	var entries []DeadLetter
+
+	db := s.db
+	if request.Kind != "" {
+		db = db.Where("kind = ?", request.Kind)
+	}
+	if request.Status != "" {
+		db = db.Where("status = ?", request.Status)
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	if err := db.Order("last_attempt_at DESC").Offset(request.Offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetDeadLetter retrieves a dead letter by ID
+func (s *Service) GetDeadLetter(id string) (*DeadLetter, error) {
+	var entry DeadLetter
+	if err := s.db.Where("id = ?", id).First(&entry).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("dead letter not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// UpdateDeadLetter replaces a pending dead letter's payload so it can be replayed with a fix
+func (s *Service) UpdateDeadLetter(request DeadLetterRequest) (*DeadLetter, error) {
+	entry, err := s.GetDeadLetter(request.ID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != DeadLetterStatusPending {
+		return nil, fmt.Errorf("dead letter %s is %s and can no longer be changed", entry.ID, entry.Status)
+	}
+
+	jobID, err := ValidateDeadLetterPayload(entry.Kind, request.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Payload = string(request.Payload)
+	entry.JobID = jobID
+	if err := s.db.Save(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	s.logger.Info("Dead letter payload updated", "id", entry.ID, "kind", entry.Kind, "job_id", jobID)
+	return entry, nil
+}
+
+// ReplayDeadLetter re-runs a pending dead letter's operation with its current payload. A
+// failed replay keeps the entry pending with the new error.
+func (s *Service) ReplayDeadLetter(id string) (*DeadLetter, error) {
+	entry, err := s.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != DeadLetterStatusPending {
+		return nil, fmt.Errorf("dead letter %s is %s and cannot be replayed", entry.ID, entry.Status)
+	}
+
+	jobID, err := ValidateDeadLetterPayload(entry.Kind, []byte(entry.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	var replayErr error
+	switch entry.Kind {
+	case DeadLetterJobCreated:
+		var event JobCreatedEvent
+		if err := json.Unmarshal([]byte(entry.Payload), &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+		replayErr = s.ProcessJobCreatedEvent(event)
+	case DeadLetterDispatch:
+		replayErr = s.replayDispatch(jobID)
+	case DeadLetterJobConfirmed:
+		replayErr = s.completeJob(jobID)
+	}
+
+	now := time.Now()
+	entry.Attempts++
+	entry.LastAttemptAt = now
+	if replayErr != nil {
+		entry.Error = replayErr.Error()
+	} else {
+		entry.Status = DeadLetterStatusReplayed
+		entry.ResolvedAt = &now
+	}
+
+	if err := s.db.Save(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	if replayErr != nil {
+		return entry, fmt.Errorf("replay failed: %w", replayErr)
+	}
+
+	s.logger.Info("Dead letter replayed", "id", entry.ID, "kind", entry.Kind, "job_id", jobID)
+	return entry, nil
+}
+
+// DiscardDeadLetter closes a pending dead letter without replaying it
+func (s *Service) DiscardDeadLetter(id string) (*DeadLetter, error) {
+	entry, err := s.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != DeadLetterStatusPending {
+		return nil, fmt.Errorf("dead letter %s is already %s", entry.ID, entry.Status)
+	}
+
+	now := time.Now()
+	entry.Status = DeadLetterStatusDiscarded
+	entry.ResolvedAt = &now
+	if err := s.db.Save(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to save dead letter: %w", err)
+	}
+
+	s.logger.Info("Dead letter discarded", "id", entry.ID, "kind", entry.Kind, "job_id", entry.JobID)
+	return entry, nil
+}
+
+// replayDispatch dispatches a queued job on an operator's request, ahead of the queue order
+// but still within its provider's concurrency limit
+func (s *Service) replayDispatch(jobID string) error {
+	job, err := s.GetJobByID(jobID)
+	if err != nil {
+		return err
+	}
+
+	provider, err := s.lookupProvider(job.ProviderAddress)
+	if err != nil {
+		s.logger.Warn("Node registry unavailable, using default concurrency limit", "error", err, "provider", job.ProviderAddress)
+	}
+
+	lock := s.providerLock(job.ProviderAddress)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// The job may have been released while the provider was looked up
+	if job, err = s.GetJobByID(jobID); err != nil {
+		return err
+	}
+	if job.Status != JobStatusQueued {
+		return fmt.Errorf("job %s is %s, not queued", job.ID, job.Status)
+	}
+	if provider != nil && provider.Unavailable {
+		return fmt.Errorf("provider %s is unavailable: %s", job.ProviderAddress, provider.UnavailableReason)
+	}
+
+	limit := s.providerConcurrency(provider)
+	active, err := s.countActiveJobs(job.ProviderAddress)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && active >= int64(limit) {
+		return fmt.Errorf("provider %s is at its concurrency limit of %d", job.ProviderAddress, limit)
+	}
+
+	event, err := s.eventFromJob(*job)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild job: %w", err)
+	}
+
+	return s.dispatchJobToProvider(event)
+}
+
+// enqueueOutbox writes a message to the outbox as part of the caller's transaction
+func (s *Service) enqueueOutbox(tx *gorm.DB, subject string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+
+	if err := tx.Create(&OutboxMessage{Subject: subject, Payload: string(payload)}).Error; err != nil {
+		return fmt.Errorf("failed to write outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// wakeOutbox asks the relay to publish newly committed messages without waiting for its poll
+func (s *Service) wakeOutbox() {
+	select {
+	case s.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// wakeLoadPublisher asks the load publisher to publish provider load without waiting for its tick
+func (s *Service) wakeLoadPublisher() {
+	select {
+	case s.loadWake <- struct{}{}:
+	default:
+	}
+}
+
+// runLoadPublisher keeps the node registry's view of provider load current, publishing it
+// when job counts change and periodically so missed snapshots are corrected
+func (s *Service) runLoadPublisher(ctx context.Context) {
+	ticker := time.NewTicker(loadPublishInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.publishProviderLoad(); err != nil {
+			s.logger.Error("Failed to publish provider load", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.loadWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishProviderLoad publishes a snapshot of every provider's unfinished jobs
+func (s *Service) publishProviderLoad() error {
+	counts, err := s.providerJobCounts()
+	if err != nil {
+		return err
+	}
+
+	dispatcherAddress := crypto.PubkeyToAddress(s.config.SigningKey.PublicKey).Hex()
+	snapshot := node_registry.ProviderLoadSnapshot{
+		Scope:              auth.Scope{Action: node_registry.ActionPublishLoad, Address: dispatcherAddress},
+		Loads:              make([]node_registry.ProviderLoad, 0, len(counts)),
+		DefaultConcurrency: s.config.DefaultConcurrency,
+		PublishedAt:        time.Now(),
+	}
+	for address, jobs := range counts {
+		snapshot.Loads = append(snapshot.Loads, node_registry.ProviderLoad{
+			WalletAddress: address,
+			AssignedJobs:  int(jobs.assigned),
+			RunningJobs:   int(jobs.running),
+			QueuedJobs:    int(jobs.queued),
+		})
+	}
+
+	// The registry only trusts snapshots signed with the dispatcher's key
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal load snapshot: %w", err)
+	}
+	signature, err := auth.SignWalletMessage(payload, s.config.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	return s.natsClient.Publish(node_registry.ProviderLoadSubject, auth.SignedRequest{
+		Address:   dispatcherAddress,
+		Payload:   string(payload),
+		Signature: signature,
+	})
+}
+
+// runOutboxRelay publishes outbox messages as they are committed and prunes old sent ones
+func (s *Service) runOutboxRelay(ctx context.Context) {
+	pollTicker := time.NewTicker(outboxPollInterval)
+	defer pollTicker.Stop()
+	pruneTicker := time.NewTicker(outboxPruneInterval)
+	defer pruneTicker.Stop()
+
+	for {
+		for {
+			sent, err := s.relayOutbox()
+			if err != nil {
+				s.logger.Error("Failed to relay outbox messages", "error", err)
+				break
+			}
+			if sent < outboxBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.outboxWake:
+		case <-pollTicker.C:
+		case <-pruneTicker.C:
+			if err := s.db.Where("sent_at < ?", time.Now().Add(-outboxRetention)).Delete(&OutboxMessage{}).Error; err != nil {
+				s.logger.Error("Failed to prune outbox messages", "error", err)
+			}
+		}
+	}
+}
+
+// relayOutbox publishes a batch of unsent outbox messages in order and marks them sent once
+// the NATS server has acknowledged them with a flush. Rows are locked so several relays never
+// publish the same batch; a crash after publishing but before marking a message sent only
+// means it is published again.
+func (s *Service) relayOutbox() (int, error) {
+	sent := 0
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var messages []OutboxMessage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("sent_at IS NULL").
+			Order("id ASC").
+			Limit(outboxBatchSize).
+			Find(&messages).Error; err != nil {
+			return fmt.Errorf("failed to load outbox messages: %w", err)
+		}
+
+		for _, update := range publishOutbox(s.natsClient, messages, time.Now()) {
+			if err := tx.Model(&OutboxMessage{}).Where("id = ?", update.id).Updates(update.fields).Error; err != nil {
+				return fmt.Errorf("failed to update outbox message: %w", err)
+			}
+			if update.sent {
+				sent++
+			}
+		}
+
+		return nil
+	})
+
+	return sent, err
+}
+
+// releaseAllQueues releases the queued jobs of every provider that has any
+func (s *Service) releaseAllQueues() error {
+	var providers []string
+	if err := s.db.Model(&Job{}).Where("status = ?", JobStatusQueued).Distinct().Pluck("provider_address", &providers).Error; err != nil {
+		return fmt.Errorf("failed to load providers with queued jobs: %w", err)
+	}
+
+	for _, provider := range providers {
+		if err := s.releaseQueuedJobs(provider); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// completeJob marks a job confirmed on-chain as completed and frees its provider slot
+func (s *Service) completeJob(jobID string) error {
+	job, err := s.GetJobByID(jobID)
+	if err != nil {
+		return err
+	}
+
+	if !job.Status.IsTerminal() {
+		if err := s.recordAttempt(job, JobStatusCompleted, "", ""); err != nil {
+			return err
+		}
+		if err := s.UpdateJobStatus(job.ID, JobStatusCompleted, ""); err != nil {
+			return err
+		}
+	}
+
+	return s.releaseQueuedJobs(job.ProviderAddress)
+}
+
+// dispatchJobToProvider dispatches a job to a specific provider
+func (s *Service) dispatchJobToProvider(event JobCreatedEvent) error {
+	// Enforce the global and provider image policies before anything reaches the provider
+	decision, err := s.evaluateImagePolicy(event.ProviderAddress, event.DockerImage)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate image policy: %w", err)
+	}
+	if !decision.Allowed {
+		reason := fmt.Sprintf("rejected by %s image policy: %s", decision.Scope, decision.Reason)
+		if err := s.UpdateJobStatus(event.JobID, JobStatusFailed, reason); err != nil {
+			return err
+		}
+
+		s.logger.Warn("Job assignment rejected by image policy", "job_id", event.JobID, "provider", event.ProviderAddress, "reason", reason)
+		return nil
+	}
+
+	// Retries are re-sent under the same job ID with the next attempt number
+	var job Job
+	if err := s.db.Select("attempts").Where("id = ?", event.JobID).First(&job).Error; err != nil {
+		return fmt.Errorf("failed to get job: %w", err)
+	}
+	attempt := job.Attempts + 1
+
+	// Create job assignment
+	assignment := JobAssignment{
+		JobID:        event.JobID,
+		DockerImage:  event.DockerImage,
+		InputFileCID: event.InputFileCID,
+		Command:      event.Command,
+		Env:          event.Env,
+		Secrets:      event.Secrets,
+
+		TransactionHash: event.TransactionHash,
+		Attempt:         attempt,
+	}
+
+	// Sign the assignment so the provider can verify it came from the dispatcher
+	if err := SignAssignment(&assignment, s.config.SigningKey, s.config.AssignmentTTL); err != nil {
+		return fmt.Errorf("failed to sign job assignment: %w", err)
+	}
+
+	// Encrypt the assignment so only the provider can read the spec
+	message, err := s.sealAssignment(assignment, event.ProviderAddress)
+	if err != nil {
+		return err
+	}
+
+	// Mark the job assigned, start a new attempt record and queue the assignment for the
+	// provider-specific subject in one transaction, so the two can never diverge
+	subject := fmt.Sprintf("jobs.dispatch.%s", event.ProviderAddress)
+	now := time.Now()
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Job{}).
+			Where("id = ?", event.JobID).
+			Updates(map[string]interface{}{
+				"status":        JobStatusAssigned,
+				"attempts":      attempt,
+				"next_retry_at": nil,
+				"assigned_at":   now,
+				"updated_at":    now,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to update job status: %w", err)
+		}
+
+		record := &JobAttempt{
+			JobID:           event.JobID,
+			Attempt:         attempt,
+			ProviderAddress: event.ProviderAddress,
+			Status:          JobStatusAssigned,
+			AssignedAt:      now,
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("failed to record job attempt: %w", err)
+		}
+
+		return s.enqueueOutbox(tx, subject, message)
+	}); err != nil {
+		return err
+	}
+	s.wakeOutbox()
+
+	s.logger.Info("Job dispatched to provider", "job_id", event.JobID, "provider", event.ProviderAddress, "attempt", attempt)
+	return nil
+}
+
+// DispatcherKey returns the public half of the assignment signing key
+func (s *Service) DispatcherKey() DispatcherKeyResponse {
+	return DispatcherKeyResponse{
+		Address:   crypto.PubkeyToAddress(s.config.SigningKey.PublicKey).Hex(),
+		PublicKey: hexutil.Encode(crypto.FromECDSAPub(&s.config.SigningKey.PublicKey)),
+		Scheme:    "secp256k1-keccak256",
+	}
+}
+
+// sealAssignment encrypts an assignment to the provider's public key. Without a known key it
+// returns errProviderKeyUnknown, or the plaintext assignment if encryption has been turned off.
+func (s *Service) sealAssignment(assignment JobAssignment, providerAddress string) (interface{}, error) {
+	provider, err := s.lookupProvider(providerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up provider public key: %w", err)
+	}
+
+	if provider == nil || provider.PublicKey == "" {
+		if s.config.RequireEncryption {
+			return nil, fmt.Errorf("%w: %s", errProviderKeyUnknown, providerAddress)
+		}
+
+		s.logger.Warn("Provider public key unknown, sending plaintext assignment", "job_id", assignment.JobID, "provider", providerAddress)
+		return assignment, nil
+	}
+
+	encrypted, err := EncryptAssignment(assignment, provider.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return encrypted, nil
+}
+
+// evaluateImagePolicy checks an image against the global policy and the provider's own policy
+func (s *Service) evaluateImagePolicy(providerAddress, image string) (image_policy.Decision, error) {
+	lookup := node_registry.NodeLookup{WalletAddress: providerAddress}
+
+	responseData, err := s.natsClient.PublishWithReply("nodes.policy.get", lookup, 5*time.Second)
+	if err != nil {
+		return image_policy.Decision{}, fmt.Errorf("failed to fetch provider image policy: %w", err)
+	}
+
+	var response node_registry.ImagePolicyResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		return image_policy.Decision{}, fmt.Errorf("failed to unmarshal image policy: %w", err)
+	}
+	if response.Error != "" {
+		return image_policy.Decision{}, fmt.Errorf("failed to fetch provider image policy: %s", response.Error)
+	}
+
+	var providerRules *image_policy.Rules
+	if response.Policy != nil {
+		providerRules = &response.Policy.Rules
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), imagePolicyTimeout)
+	defer cancel()
+
+	return s.policyEngine.Evaluate(ctx, image, providerRules), nil
+}
+
+// jobIDPattern matches a bytes32 job ID as emitted in JobCreated events
+var jobIDPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+
+// SubmitJobSpec validates and stores a renter's off-chain job spec. Specs can be
+// replaced by their renter until the job is funded on-chain.
+func (s *Service) SubmitJobSpec(spec *JobSpec) error {
+	if err := validateJobSpec(spec); err != nil {
+		return err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return saveJobSpec(tx, spec)
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info("Job spec submitted", "job_id", spec.JobID, "renter", spec.RenterAddress, "provider", spec.ProviderAddress)
+	return nil
+}
+
+// validateJobSpec checks a submitted spec and normalizes its job ID and addresses
+func validateJobSpec(spec *JobSpec) error {
+	if !jobIDPattern.MatchString(spec.JobID) {
+		return fmt.Errorf("job_id must be a 0x-prefixed 32-byte hex string")
+	}
+	if !common.IsHexAddress(spec.RenterAddress) {
+		return fmt.Errorf("renter_address is not a valid address")
+	}
+	if !common.IsHexAddress(spec.ProviderAddress) {
+		return fmt.Errorf("provider_address is not a valid address")
+	}
+	if strings.TrimSpace(spec.DockerImage) == "" {
+		return fmt.Errorf("docker_image is required")
+	}
+	if err := ValidateSecrets(spec.Secrets, spec.Env); err != nil {
+		return err
+	}
+	if err := ValidateWorkloadClass(spec.WorkloadClass); err != nil {
+		return err
+	}
+	if err := ValidateLabels(spec.Labels); err != nil {
+		return err
+	}
+	if spec.Requirements.CUDAVersion != "" {
+		if err := node_registry.ValidateVersion(spec.Requirements.CUDAVersion); err != nil {
+			return fmt.Errorf("invalid requirements.cuda_version: %w", err)
+		}
+	}
+	if spec.RetryPolicy != nil {
+		if err := ValidateRetryPolicy(spec.RetryPolicy); err != nil {
+			return fmt.Errorf("invalid retry_policy: %w", err)
+		}
+	}
+
+	spec.JobID = strings.ToLower(spec.JobID)
+	spec.RenterAddress = common.HexToAddress(spec.RenterAddress).Hex()
+	spec.ProviderAddress = common.HexToAddress(spec.ProviderAddress).Hex()
+	return nil
+}
+
+// saveJobSpec stores a validated spec and its secrets in tx, replacing a spec the same
+// renter registered for a job that has not been funded yet
+func saveJobSpec(tx *gorm.DB, spec *JobSpec) error {
+	var existingJobs int64
+	if err := tx.Model(&Job{}).Where("id = ?", spec.JobID).Count(&existingJobs).Error; err != nil {
+		return fmt.Errorf("failed to check job: %w", err)
+	}
+	if existingJobs > 0 {
+		return fmt.Errorf("job %s has already been created on-chain", spec.JobID)
+	}
+
+	var existing JobSpec
+	err := tx.Where("job_id = ?", spec.JobID).First(&existing).Error
+	switch {
+	case err == nil:
+		if existing.RenterAddress != spec.RenterAddress {
+			return fmt.Errorf("job spec %s belongs to another renter", spec.JobID)
+		}
+		spec.CreatedAt = existing.CreatedAt
+	case err != gorm.ErrRecordNotFound:
+		return fmt.Errorf("failed to get job spec: %w", err)
+	}
+
+	if err := tx.Save(spec).Error; err != nil {
+		return fmt.Errorf("failed to save job spec: %w", err)
+	}
+
+	// Replacing a spec replaces its secrets, which are only ever stored encrypted
+	if err := tx.Where("job_id = ?", spec.JobID).Delete(&JobSecret{}).Error; err != nil {
+		return fmt.Errorf("failed to replace job secrets: %w", err)
+	}
+	if len(spec.Secrets) > 0 {
+		secret := &JobSecret{
+			JobID:           spec.JobID,
+			ProviderAddress: spec.ProviderAddress,
+			Ciphertexts:     spec.Secrets,
+		}
+		if err := tx.Create(secret).Error; err != nil {
+			return fmt.Errorf("failed to save job secrets: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// applyJobSpec fills a JobCreated event with the renter's off-chain spec, if one was
+// registered. Specs registered by a different wallet than the on-chain renter are ignored.
+func (s *Service) applyJobSpec(event *JobCreatedEvent) error {
+	var spec JobSpec
+	if err := s.db.Where("job_id = ?", strings.ToLower(event.JobID)).First(&spec).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if !strings.EqualFold(spec.RenterAddress, event.RenterAddress) {
+		s.logger.Warn("Ignoring job spec registered by another wallet", "job_id", event.JobID, "spec_renter", spec.RenterAddress, "renter", event.RenterAddress)
+		return nil
+	}
+
+	if !strings.EqualFold(spec.ProviderAddress, event.ProviderAddress) {
+		s.logger.Warn("Job spec provider differs from on-chain provider", "job_id", event.JobID, "spec_provider", spec.ProviderAddress, "provider", event.ProviderAddress)
+	}
+
+	event.DockerImage = spec.DockerImage
+	event.InputFileCID = spec.InputFileCID
+	event.Command = spec.Command
+	event.Env = spec.Env
+	event.Requirements = spec.Requirements
+	event.RetryPolicy = spec.RetryPolicy
+	event.WorkloadClass = spec.WorkloadClass
+	event.Labels = spec.Labels
+
+	var secret JobSecret
+	if err := s.db.Where("job_id = ?", spec.JobID).First(&secret).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	// Secrets are encrypted to the spec's provider and are unreadable by anyone else
+	if !strings.EqualFold(secret.ProviderAddress, event.ProviderAddress) {
+		s.logger.Warn("Dropping job secrets encrypted to another provider", "job_id", event.JobID, "secret_provider", secret.ProviderAddress, "provider", event.ProviderAddress)
+		return nil
+	}
+	event.Secrets = secret.Ciphertexts
+	return nil
+}
+
+// CreatePipeline validates and stores a renter's pipeline. Stages without dependencies
+// start out ready to be funded.
+func (s *Service) CreatePipeline(pipeline *Pipeline) error {
+	if !common.IsHexAddress(pipeline.RenterAddress) {
+		return fmt.Errorf("renter_address is not a valid address")
+	}
+	if err := ValidatePipeline(pipeline.Stages); err != nil {
+		return err
+	}
+
+	pipeline.ID = uuid.NewString()
+	pipeline.RenterAddress = common.HexToAddress(pipeline.RenterAddress).Hex()
+	for i := range pipeline.Stages {
+		stage := &pipeline.Stages[i]
+		stage.ProviderAddress = common.HexToAddress(stage.ProviderAddress).Hex()
+		stage.JobID = ""
+		stage.OutputFileCID = ""
+		stage.ErrorMessage = ""
+		stage.Status = StageStatusPending
+		if len(stage.DependsOn) > 0 {
+			// Filled in from the input stage's output once it completes
+			stage.InputFileCID = ""
+		}
+	}
+	promoteReadyStages(pipeline.Stages)
+	pipeline.Status = AggregatePipelineStatus(pipeline.Stages)
+
+	if err := s.db.Create(pipeline).Error; err != nil {
+		return fmt.Errorf("failed to create pipeline: %w", err)
+	}
+
+	s.logger.Info("Pipeline created", "pipeline_id", pipeline.ID, "renter", pipeline.RenterAddress, "stages", len(pipeline.Stages))
+	return nil
+}
+
+// GetPipeline retrieves a pipeline and its stages by ID
+func (s *Service) GetPipeline(pipelineID string) (*Pipeline, error) {
+	var pipeline Pipeline
+	if err := s.db.Preload("Stages", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("id = ?", pipelineID).First(&pipeline).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("pipeline not found: %s", pipelineID)
+		}
+		return nil, fmt.Errorf("failed to get pipeline: %w", err)
+	}
+
+	return &pipeline, nil
+}
+
+// SubmitPipelineStage registers the job spec for a ready stage under the job ID the renter
+// will fund, so the JobCreated event picks up the stage's image, command and input CID
+func (s *Service) SubmitPipelineStage(submission PipelineStageSubmission) (*Pipeline, error) {
+	s.pipelineMu.Lock()
+	defer s.pipelineMu.Unlock()
+
+	pipeline, err := s.GetPipeline(submission.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(pipeline.RenterAddress, submission.RenterAddress) {
+		return nil, fmt.Errorf("pipeline %s belongs to another renter", pipeline.ID)
+	}
+
+	var stage *PipelineStage
+	for i := range pipeline.Stages {
+		if pipeline.Stages[i].Name == submission.Stage {
+			stage = &pipeline.Stages[i]
+		}
+	}
+	if stage == nil {
+		return nil, fmt.Errorf("stage not found: %s", submission.Stage)
+	}
+	if stage.Status != StageStatusReady && stage.Status != StageStatusSubmitted {
+		return nil, fmt.Errorf("stage %s is %s and cannot be submitted", stage.Name, stage.Status)
+	}
+
+	spec := &JobSpec{
+		JobID:           submission.JobID,
+		RenterAddress:   pipeline.RenterAddress,
+		ProviderAddress: stage.ProviderAddress,
+		DockerImage:     stage.DockerImage,
+		InputFileCID:    stage.InputFileCID,
+		Command:         stage.Command,
+		Env:             stage.Env,
+		Requirements:    stage.Requirements,
+	}
+	if err := s.SubmitJobSpec(spec); err != nil {
+		return nil, err
+	}
+
+	stage.JobID = spec.JobID
+	stage.Status = StageStatusSubmitted
+	pipeline.Status = AggregatePipelineStatus(pipeline.Stages)
+
+	if err := s.savePipeline(pipeline); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Pipeline stage submitted", "pipeline_id", pipeline.ID, "stage", stage.Name, "job_id", stage.JobID)
+	return pipeline, nil
+}
+
+// advancePipeline records a finished job on its pipeline stage, if any, and marks the
+// stages it unblocks as ready with the job's output CID as their input
+func (s *Service) advancePipeline(jobID string) error {
+	s.pipelineMu.Lock()
+	defer s.pipelineMu.Unlock()
+
+	var stage PipelineStage
+	if err := s.db.Where("job_id = ?", jobID).First(&stage).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to get pipeline stage: %w", err)
+	}
+
+	job, err := s.GetJobByID(jobID)
+	if err != nil {
+		return err
+	}
+
+	pipeline, err := s.GetPipeline(stage.PipelineID)
+	if err != nil {
+		return err
+	}
+
+	for i := range pipeline.Stages {
+		current := &pipeline.Stages[i]
+		if current.JobID != jobID {
+			continue
+		}
+
+		switch {
+		case job.Status == JobStatusCompleted && job.OutputFileCID != "":
+			current.Status = StageStatusCompleted
+			current.OutputFileCID = job.OutputFileCID
+		case job.Status == JobStatusCompleted:
+			current.Status = StageStatusFailed
+			current.ErrorMessage = "job completed without an output CID"
+		default:
+			current.Status = StageStatusFailed
+			current.ErrorMessage = job.ErrorMessage
+		}
+	}
+
+	promoted := promoteReadyStages(pipeline.Stages)
+	pipeline.Status = AggregatePipelineStatus(pipeline.Stages)
+
+	if err := s.savePipeline(pipeline); err != nil {
+		return err
+	}
+
+	s.logger.Info("Pipeline advanced", "pipeline_id", pipeline.ID, "job_id", jobID, "status", pipeline.Status, "ready_stages", promoted)
+	return nil
+}
+
+// savePipeline persists a pipeline and its stages in one transaction
+func (s *Service) savePipeline(pipeline *Pipeline) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range pipeline.Stages {
+			if err := tx.Save(&pipeline.Stages[i]).Error; err != nil {
+				return fmt.Errorf("failed to save pipeline stage: %w", err)
+			}
+		}
+
+		if err := tx.Model(pipeline).Updates(map[string]interface{}{
+			"status":     pipeline.Status,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update pipeline: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// startScheduler schedules every active template with a cron schedule
+func (s *Service) startScheduler(ctx context.Context) error {
+	var templates []JobTemplate
+	if err := s.db.Where("schedule <> '' AND paused = ?", false).Find(&templates).Error; err != nil {
+		return fmt.Errorf("failed to load scheduled templates: %w", err)
+	}
+
+	for _, template := range templates {
+		if err := s.scheduleTemplate(template); err != nil {
+			s.logger.Error("Failed to schedule template", "error", err, "template_id", template.ID)
+		}
+	}
+
+	s.scheduler.Start()
+	go func() {
+		<-ctx.Done()
+		s.scheduler.Stop()
+	}()
+
+	s.logger.Info("Template scheduler started", "templates", len(templates))
+	return nil
+}
+
+// scheduleTemplate replaces a template's cron entry, removing it if the template is
+// paused or has no schedule
+func (s *Service) scheduleTemplate(template JobTemplate) error {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	if entryID, ok := s.scheduled[template.ID]; ok {
+		s.scheduler.Remove(entryID)
+		delete(s.scheduled, template.ID)
+	}
+
+	if template.Schedule == "" || template.Paused {
+		return nil
+	}
+
+	templateID := template.ID
+	entryID, err := s.scheduler.AddFunc(template.Schedule, func() {
+		s.runScheduledTemplate(templateID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule template: %w", err)
+	}
+
+	s.scheduled[template.ID] = entryID
+	return nil
+}
+
+// runScheduledTemplate instantiates the latest version of a template when its schedule fires
+func (s *Service) runScheduledTemplate(templateID string) {
+	var template JobTemplate
+	if err := s.db.Where("id = ?", templateID).First(&template).Error; err != nil {
+		s.logger.Error("Failed to load scheduled template", "error", err, "template_id", templateID)
+		return
+	}
+	if template.Paused {
+		return
+	}
+
+	if _, err := s.instantiateTemplate(template); err != nil {
+		s.logger.Error("Failed to run scheduled template", "error", err, "template_id", templateID)
+	}
+}
+
+// SaveTemplate validates and stores a renter's template, creating it when it has no ID
+func (s *Service) SaveTemplate(template *JobTemplate) error {
+	if !common.IsHexAddress(template.RenterAddress) {
+		return fmt.Errorf("renter_address is not a valid address")
+	}
+	if err := ValidateTemplate(template); err != nil {
+		return err
+	}
+
+	template.RenterAddress = common.HexToAddress(template.RenterAddress).Hex()
+	for i, provider := range template.PreferredProviders {
+		template.PreferredProviders[i] = common.HexToAddress(provider).Hex()
+	}
+	if template.DelegateAddress != "" {
+		template.DelegateAddress = common.HexToAddress(template.DelegateAddress).Hex()
+	}
+
+	if template.ID == "" {
+		template.ID = uuid.NewString()
+		template.LastRunAt = nil
+		if err := s.db.Create(template).Error; err != nil {
+			return fmt.Errorf("failed to create template: %w", err)
+		}
+	} else {
+		existing, err := s.getOwnedTemplate(template.ID, template.RenterAddress)
+		if err != nil {
+			return err
+		}
+		template.CreatedAt = existing.CreatedAt
+		template.LastRunAt = existing.LastRunAt
+		if err := s.db.Save(template).Error; err != nil {
+			return fmt.Errorf("failed to save template: %w", err)
+		}
+	}
+
+	if err := s.scheduleTemplate(*template); err != nil {
+		return err
+	}
+
+	s.logger.Info("Job template saved", "template_id", template.ID, "renter", template.RenterAddress, "schedule", template.Schedule)
+	return nil
+}
+
+// ListTemplates retrieves a renter's templates
+func (s *Service) ListTemplates(renterAddress string) ([]JobTemplate, error) {
+	var templates []JobTemplate
+	if err := s.db.Where("renter_address = ?", common.HexToAddress(renterAddress).Hex()).
+		Order("created_at DESC").
+		Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// DeleteTemplate removes a renter's template and its schedule
+func (s *Service) DeleteTemplate(request JobTemplateRequest) error {
+	template, err := s.getOwnedTemplate(request.TemplateID, request.RenterAddress)
+	if err != nil {
+		return err
+	}
+
+	template.Paused = true
+	if err := s.scheduleTemplate(*template); err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(template).Error; err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	s.logger.Info("Job template deleted", "template_id", template.ID)
+	return nil
+}
+
+// RunTemplate instantiates a renter's template immediately
+func (s *Service) RunTemplate(request JobTemplateRequest) (*JobTemplateRun, error) {
+	template, err := s.getOwnedTemplate(request.TemplateID, request.RenterAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.instantiateTemplate(*template)
+}
+
+// ListTemplateRuns retrieves the runs of a renter's template, newest first
+func (s *Service) ListTemplateRuns(request JobTemplateRequest) ([]JobTemplateRun, error) {
+	template, err := s.getOwnedTemplate(request.TemplateID, request.RenterAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []JobTemplateRun
+	if err := s.db.Where("template_id = ?", template.ID).
+		Order("created_at DESC").
+		Limit(100).
+		Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get template runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// getOwnedTemplate retrieves a template, checking it belongs to the renter
+func (s *Service) getOwnedTemplate(templateID, renterAddress string) (*JobTemplate, error) {
+	var template JobTemplate
+	if err := s.db.Where("id = ?", templateID).First(&template).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("template not found: %s", templateID)
+		}
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	if !strings.EqualFold(template.RenterAddress, renterAddress) {
+		return nil, fmt.Errorf("template %s belongs to another renter", templateID)
+	}
+
+	return &template, nil
+}
+
+// instantiateTemplate registers a job spec for a new job ID on the first suitable preferred
+// provider, builds the unsigned createJob transaction and notifies the signer to fund it
+func (s *Service) instantiateTemplate(template JobTemplate) (*JobTemplateRun, error) {
+	// Jobs are owned on-chain by whoever sends createJob, so the spec goes to the signer
+	signer := template.RenterAddress
+	if template.DelegateAddress != "" {
+		signer = template.DelegateAddress
+	}
+
+	request := JobFundingRequest{
+		Type:         FundingRequestType,
+		TemplateID:   template.ID,
+		TemplateName: template.Name,
+	}
+
+	run, err := s.buildTemplateRun(template, signer, &request)
+	if err != nil {
+		request.Error = err.Error()
+	}
+
+	s.notifyRenter(signer, request)
+	if signer != template.RenterAddress {
+		s.notifyRenter(template.RenterAddress, request)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Job template run ready to fund", "template_id", template.ID, "job_id", run.JobID, "provider", run.ProviderAddress, "signer", signer)
+	return run, nil
+}
+
+// buildTemplateRun registers the spec and funding transaction for one template run
+func (s *Service) buildTemplateRun(template JobTemplate, signer string, request *JobFundingRequest) (*JobTemplateRun, error) {
+	provider, issues, err := s.selectTemplateProvider(template)
+	request.Issues = issues
+	if err != nil {
+		return nil, err
+	}
+
+	jobID, err := NewJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	spec := JobSpec{
+		JobID:           jobID,
+		RenterAddress:   signer,
+		ProviderAddress: provider,
+		DockerImage:     template.DockerImage,
+		InputFileCID:    template.InputFileCID,
+		Command:         template.Command,
+		Env:             template.Env,
+		Requirements:    template.Requirements,
+	}
+	if err := s.SubmitJobSpec(&spec); err != nil {
+		return nil, err
+	}
+
+	// The chain ID is looked up once at startup so runs do not depend on the RPC endpoint
+	chainID, ok := new(big.Int).SetString(s.chainID, 10)
+	if !ok {
+		return nil, fmt.Errorf("chain ID is unknown: %q", s.chainID)
+	}
+
+	transaction, err := BuildCreateJobTransaction(s.contractAddr, chainID, jobID, provider, template.PaymentAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &JobTemplateRun{
+		JobID:           jobID,
+		TemplateID:      template.ID,
+		RenterAddress:   template.RenterAddress,
+		SignerAddress:   signer,
+		ProviderAddress: provider,
+		Transaction:     transaction,
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to create template run: %w", err)
+	}
+
+	if err := s.db.Model(&JobTemplate{}).Where("id = ?", template.ID).Update("last_run_at", run.CreatedAt).Error; err != nil {
+		return nil, fmt.Errorf("failed to update template: %w", err)
+	}
+
+	request.JobID = jobID
+	request.Spec = spec
+	request.Transaction = transaction
+	return run, nil
+}
+
+// selectTemplateProvider picks the first preferred provider without blocking requirement
+// issues, preferring providers that are online
+func (s *Service) selectTemplateProvider(template JobTemplate) (string, []RequirementIssue, error) {
+	var fallback string
+	var fallbackIssues []RequirementIssue
+
+	for _, provider := range template.PreferredProviders {
+		issues := s.checkProviderRequirements(provider, template.Requirements)
+		if HasBlockingIssues(issues) {
+			continue
+		}
+
+		online := true
+		for _, issue := range issues {
+			if issue.Code == IssueProviderOffline {
+				online = false
+			}
+		}
+		if online {
+			return provider, issues, nil
+		}
+
+		if fallback == "" {
+			fallback, fallbackIssues = provider, issues
+		}
+	}
+
+	if fallback != "" {
+		return fallback, fallbackIssues, nil
+	}
+
+	return "", nil, fmt.Errorf("none of the preferred providers can run this template")
+}
+
+// notifyRenter publishes a notification to a renter or delegated signer
+func (s *Service) notifyRenter(address string, notification interface{}) {
+	if err := s.enqueueOutbox(s.db, RenterNotificationSubject(address), notification); err != nil {
+		s.logger.Error("Failed to notify renter", "error", err, "renter", address)
+		return
+	}
+	s.wakeOutbox()
+}
+
+// CreateVerificationGroup registers the same spec for each job in the request and links the
+// jobs so their output hashes are compared once they finish
+func (s *Service) CreateVerificationGroup(request VerificationRequest) (*VerificationGroup, error) {
+	if !common.IsHexAddress(request.RenterAddress) {
+		return nil, fmt.Errorf("renter_address is not a valid address")
+	}
+	if err := ValidateVerificationRequest(&request); err != nil {
+		return nil, err
+	}
+	if len(request.Spec.Secrets) > 0 {
+		return nil, fmt.Errorf("secrets are encrypted to a single provider and cannot be used in verification groups")
+	}
+
+	deadline := time.Now().Add(time.Duration(request.TimeoutSeconds) * time.Second)
+	group := &VerificationGroup{
+		ID:            uuid.NewString(),
+		RenterAddress: common.HexToAddress(request.RenterAddress).Hex(),
+		Quorum:        request.Quorum,
+		Status:        VerificationStatusPending,
+		Deadline:      &deadline,
+	}
+
+	specs := make([]JobSpec, 0, len(request.Jobs))
+	for _, job := range request.Jobs {
+		spec := request.Spec
+		spec.JobID = job.JobID
+		spec.RenterAddress = group.RenterAddress
+		spec.ProviderAddress = job.ProviderAddress
+		if err := validateJobSpec(&spec); err != nil {
+			return nil, fmt.Errorf("job %s: %w", job.JobID, err)
+		}
+		specs = append(specs, spec)
+
+		group.Members = append(group.Members, VerificationMember{
+			JobID:           spec.JobID,
+			ProviderAddress: spec.ProviderAddress,
+			Outcome:         VerificationOutcomePending,
+		})
+	}
+
+	// The member specs and the group are stored together so a failure leaves no spec behind
+	// without its group
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range specs {
+			if err := saveJobSpec(tx, &specs[i]); err != nil {
+				return fmt.Errorf("job %s: %w", specs[i].JobID, err)
+			}
+		}
+		if err := tx.Create(group).Error; err != nil {
+			return fmt.Errorf("failed to create verification group: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Verification group created", "group_id", group.ID, "renter", group.RenterAddress, "jobs", len(group.Members), "quorum", group.Quorum)
+	return group, nil
 }
 
-// handleJobQuery handles queries for jobs
-func (s *Service) handleJobQuery(data []byte) ([]byte, error) {
-	var query JobQuery
-	if err := json.Unmarshal(data, &query); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
+// GetVerificationGroup retrieves a verification group and its members by ID
+func (s *Service) GetVerificationGroup(groupID string) (*VerificationGroup, error) {
+	var group VerificationGroup
+	if err := s.db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("id = ?", groupID).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("verification group not found: %s", groupID)
+		}
+		return nil, fmt.Errorf("failed to get verification group: %w", err)
 	}
 
-	jobs, err := s.GetJobs(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get jobs: %w", err)
-	}
+	return &group, nil
+}
 
-	response := JobsResponse{
-		Jobs:  jobs,
-		Count: len(jobs),
+// GetProviderVerificationStats counts a provider's resolved verification outcomes
+func (s *Service) GetProviderVerificationStats(providerAddress string) (*ProviderVerificationStats, error) {
+	var rows []struct {
+		Outcome VerificationOutcome
+		Count   int64
+	}
+	address := common.HexToAddress(providerAddress).Hex()
+	if err := s.db.Model(&VerificationMember{}).
+		Select("outcome, COUNT(*) AS count").
+		Where("provider_address = ?", address).
+		Group("outcome").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count verification outcomes: %w", err)
 	}
 
-	responseData, err := json.Marshal(response)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	stats := &ProviderVerificationStats{ProviderAddress: address}
+	for _, row := range rows {
+		switch row.Outcome {
+		case VerificationOutcomeMatched:
+			stats.Matched = row.Count
+		case VerificationOutcomeMismatched:
+			stats.Mismatched = row.Count
+		case VerificationOutcomeMissing:
+			stats.Missing = row.Count
+		case VerificationOutcomeInconclusive:
+			stats.Inconclusive = row.Count
+		}
 	}
 
-	return responseData, nil
+	return stats, nil
 }
 
-// listenToBlockchainEvents listens for blockchain events
-func (s *Service) listenToBlockchainEvents(ctx context.Context) {
-	s.logger.Info("Starting blockchain event listener (polling mode)")
+// evaluateVerification resolves a finished job's verification group, if any, once every
+// member job has finished, and publishes each provider's outcome
+func (s *Service) evaluateVerification(jobID string) error {
+	s.verificationMu.Lock()
+	defer s.verificationMu.Unlock()
 
-	// Get the latest block number to start listening from
-	latestBlock, err := s.blockchain.GetLatestBlockNumber(ctx)
-	if err != nil {
-		s.logger.Error("Failed to get latest block number", "error", err)
-		return
+	var member VerificationMember
+	if err := s.db.Where("job_id = ?", jobID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to get verification member: %w", err)
 	}
 
-	// Start from the latest block
-	fromBlock := uint64(latestBlock)
-	s.logger.Info("Starting event listener from block", "block", fromBlock)
+	return s.resolveVerificationGroup(member.GroupID, time.Now())
+}
 
-	// Poll for events every 10 seconds
-	ticker := time.NewTicker(10 * time.Second)
+// runVerificationDeadlines periodically resolves pending verification groups whose deadline
+// has passed, so groups with jobs that were never funded or never finish do not stay pending
+func (s *Service) runVerificationDeadlines(ctx context.Context) {
+	ticker := time.NewTicker(verificationSweepInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			s.logger.Info("Stopping blockchain event listener")
 			return
 		case <-ticker.C:
-			// Poll for new events
-			if err := s.pollForEvents(ctx, fromBlock); err != nil {
-				s.logger.Error("Failed to poll for events", "error", err)
-			} else {
-				// Update fromBlock to current block
-				if currentBlock, err := s.blockchain.GetLatestBlockNumber(ctx); err == nil {
-					fromBlock = uint64(currentBlock)
-				}
+		}
+
+		// Groups created before deadlines existed get the default timeout
+		now := time.Now()
+		var groupIDs []string
+		if err := s.db.Model(&VerificationGroup{}).
+			Where("status = ? AND (deadline <= ? OR (deadline IS NULL AND created_at <= ?))", VerificationStatusPending, now, now.Add(-defaultVerificationTimeout)).
+			Pluck("id", &groupIDs).Error; err != nil {
+			s.logger.Error("Failed to load expired verification groups", "error", err)
+			continue
+		}
+
+		for _, groupID := range groupIDs {
+			s.verificationMu.Lock()
+			if err := s.resolveVerificationGroup(groupID, now); err != nil {
+				s.logger.Error("Failed to resolve expired verification group", "error", err, "group_id", groupID)
 			}
+			s.verificationMu.Unlock()
 		}
 	}
 }
 
-// pollForEvents polls for blockchain events
-func (s *Service) pollForEvents(ctx context.Context, fromBlock uint64) error {
-	// Get current block
-	currentBlock, err := s.blockchain.GetLatestBlockNumber(ctx)
+// resolveVerificationGroup resolves a pending group once every member job has finished, or
+// once its deadline has passed, in which case members that have not finished are missing.
+// Callers hold verificationMu.
+func (s *Service) resolveVerificationGroup(groupID string, now time.Time) error {
+	group, err := s.GetVerificationGroup(groupID)
 	if err != nil {
-		return fmt.Errorf("failed to get current block: %w", err)
+		return err
+	}
+	if group.Status != VerificationStatusPending {
+		return nil
 	}
 
-	// Only process if there are new blocks
-	if uint64(currentBlock) <= fromBlock {
+	deadline := group.CreatedAt.Add(defaultVerificationTimeout)
+	if group.Deadline != nil {
+		deadline = *group.Deadline
+	}
+	expired := !now.Before(deadline)
+
+	jobIDs := make([]string, len(group.Members))
+	for i, member := range group.Members {
+		jobIDs[i] = member.JobID
+	}
+	var jobs []Job
+	if err := s.db.Where("id IN ?", jobIDs).Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to get verification jobs: %w", err)
+	}
+	jobsByID := make(map[string]Job, len(jobs))
+	for _, job := range jobs {
+		jobsByID[job.ID] = job
+	}
+
+	for i := range group.Members {
+		group.Members[i].OutputHash = ""
+
+		job, ok := jobsByID[group.Members[i].JobID]
+		if !ok || !job.Status.IsTerminal() {
+			// Wait until every linked job has been funded and has finished, or the deadline
+			if !expired {
+				return nil
+			}
+			continue
+		}
+
+		if job.Status == JobStatusCompleted {
+			group.Members[i].OutputHash = job.OutputHash
+		}
+	}
+
+	group.Status, group.ConsensusHash = ResolveVerification(group.Members, group.Quorum)
+	group.ResolvedAt = &now
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i := range group.Members {
+			if err := tx.Save(&group.Members[i]).Error; err != nil {
+				return fmt.Errorf("failed to save verification member: %w", err)
+			}
+		}
+
+		if err := tx.Model(group).Updates(map[string]interface{}{
+			"status":         group.Status,
+			"consensus_hash": group.ConsensusHash,
+			"resolved_at":    now,
+			"updated_at":     now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update verification group: %w", err)
+		}
+
+		for _, resolved := range group.Members {
+			result := VerificationResult{
+				GroupID:         group.ID,
+				JobID:           resolved.JobID,
+				ProviderAddress: resolved.ProviderAddress,
+				Outcome:         resolved.Outcome,
+				OutputHash:      resolved.OutputHash,
+				ConsensusHash:   group.ConsensusHash,
+				GroupStatus:     group.Status,
+			}
+			if err := s.enqueueOutbox(tx, VerificationResultSubject(resolved.ProviderAddress), result); err != nil {
+				return err
+			}
+		}
+
 		return nil
+	}); err != nil {
+		return err
 	}
+	s.wakeOutbox()
 
-	// Query for JobCreated events
-	jobCreatedEvents, err := s.jobManagerContract.FilterJobCreated(&bind.FilterOpts{
-		Start:   fromBlock,
-		End:     &currentBlock,
-		Context: ctx,
-	}, nil, nil, nil)
+	s.logger.Info("Verification group resolved", "group_id", group.ID, "status", group.Status, "consensus_hash", group.ConsensusHash, "expired", expired)
+	return nil
+}
+
+// QuoteJob suggests online providers that can run a spec, cheapest first, with runtimes
+// estimated from similar completed jobs and costs priced at the rates renters paid them
+func (s *Service) QuoteJob(request QuoteRequest) ([]ProviderQuote, error) {
+	if strings.TrimSpace(request.DockerImage) == "" {
+		return nil, fmt.Errorf("docker_image is required")
+	}
+	if err := ValidateWorkloadClass(request.WorkloadClass); err != nil {
+		return nil, err
+	}
+	if request.Limit <= 0 {
+		request.Limit = defaultQuoteLimit
+	}
+	if request.Limit > maxQuoteLimit {
+		request.Limit = maxQuoteLimit
+	}
+
+	query := node_registry.NodeQuery{Limit: 500}
+	if request.Requirements.MinVRAM > 0 {
+		query.MinVRAM = &request.Requirements.MinVRAM
+	}
+	providers, err := s.activeProviders(query)
 	if err != nil {
-		return fmt.Errorf("failed to filter JobCreated events: %w", err)
+		return nil, err
 	}
 
-	// Process JobCreated events
-	for jobCreatedEvents.Next() {
-		event := jobCreatedEvents.Event
-		s.logger.Info("Received JobCreated event", "job_id", fmt.Sprintf("0x%x", event.JobId))
-		if err := s.processJobCreatedEvent(event); err != nil {
-			s.logger.Error("Failed to process JobCreated event", "error", err, "job_id", fmt.Sprintf("0x%x", event.JobId))
+	var history quoteHistory
+	if err := s.db.Where("status = ? AND assigned_at IS NOT NULL AND completed_at IS NOT NULL", JobStatusCompleted).
+		Order("completed_at DESC").
+		Limit(quoteHistoryLimit).
+		Find(&history.jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load job history: %w", err)
+	}
+
+	quotes := []ProviderQuote{}
+	for i := range providers {
+		provider := providers[i]
+
+		issues := ValidateProvider(&provider, request.Requirements)
+		if HasBlockingIssues(issues) {
+			continue
+		}
+
+		samples, basis := history.runtimeSamples(request.DockerImage, request.WorkloadClass, provider.WalletAddress)
+		estimate := EstimateRuntime(samples, basis)
+
+		quote := ProviderQuote{
+			ProviderAddress:  provider.WalletAddress,
+			GPUModel:         provider.GPUModel,
+			VRAM:             provider.VRAM,
+			ReputationScore:  provider.ReputationScore,
+			EstimatedRuntime: estimate,
+			Confidence:       QuoteConfidence(estimate),
+			Issues:           issues,
 		}
+
+		// Advertised prices take precedence over rates inferred from past payments
+		if card := provider.RateCard; card != nil {
+			tier := string(request.WorkloadClass)
+			quote.RateWeiPerHour = card.HourlyPrice(request.DockerImage, tier).String()
+			quote.RateSource = RateSourceRateCard
+			quote.EstimatedCostWei = card.Charge(request.DockerImage, tier, 1, estimate.Seconds).String()
+			quote.CostLowWei = card.Charge(request.DockerImage, tier, 1, estimate.LowSeconds).String()
+			quote.CostHighWei = card.Charge(request.DockerImage, tier, 1, estimate.HighSeconds).String()
+		} else {
+			rate, rateSource := history.rate(provider.WalletAddress)
+			quote.RateWeiPerHour = weiString(rate * 3600)
+			quote.RateSource = rateSource
+			quote.EstimatedCostWei = weiString(rate * estimate.Seconds)
+			quote.CostLowWei = weiString(rate * estimate.LowSeconds)
+			quote.CostHighWei = weiString(rate * estimate.HighSeconds)
+		}
+
+		quotes = append(quotes, quote)
 	}
 
-	return nil
+	// Quotes without an estimate sort last, the rest cheapest first
+	sort.SliceStable(quotes, func(i, j int) bool {
+		iNone := quotes[i].EstimatedRuntime.Basis == QuoteBasisNone
+		jNone := quotes[j].EstimatedRuntime.Basis == QuoteBasisNone
+		if iNone != jNone {
+			return jNone
+		}
+
+		iCost, _ := new(big.Int).SetString(quotes[i].EstimatedCostWei, 10)
+		jCost, _ := new(big.Int).SetString(quotes[j].EstimatedCostWei, 10)
+		if c := iCost.Cmp(jCost); c != 0 {
+			return c < 0
+		}
+		return quotes[i].ReputationScore > quotes[j].ReputationScore
+	})
+
+	if len(quotes) > request.Limit {
+		quotes = quotes[:request.Limit]
+	}
+
+	return quotes, nil
 }
 
-// processJobCreatedEvent processes a JobCreated event from the blockchain
-func (s *Service) processJobCreatedEvent(event *contracts.JobManagerJobCreated) error {
-	s.logger.Info("Processing JobCreated event", "job_id", fmt.Sprintf("0x%x", event.JobId))
+// MatchProviders ranks the available providers that meet a job's requirements by fit,
+// reputation, uptime, load, price and distance from the renter
+func (s *Service) MatchProviders(request MatchRequest) ([]ProviderMatch, error) {
+	if err := ValidateWorkloadClass(request.WorkloadClass); err != nil {
+		return nil, err
+	}
+	request.Region = strings.ToLower(strings.TrimSpace(request.Region))
+	if request.Region != "" {
+		if err := node_registry.ValidateRegion(request.Region); err != nil {
+			return nil, err
+		}
+	}
+	if request.Limit <= 0 {
+		request.Limit = defaultMatchLimit
+	}
+	if request.Limit > maxMatchLimit {
+		request.Limit = maxMatchLimit
+	}
 
-	// Convert the event to our internal format
-	jobEvent := JobCreatedEvent{
-		JobID:           fmt.Sprintf("0x%x", event.JobId),
-		RenterAddress:   event.Renter.Hex(),
-		ProviderAddress: event.Provider.Hex(),
-		PaymentAmount:   event.Payment.String(),
-		BlockNumber:     event.Raw.BlockNumber,
-		TransactionHash: event.Raw.TxHash.Hex(),
+	query := node_registry.NodeQuery{Limit: 500}
+	if request.Requirements.MinVRAM > 0 {
+		query.MinVRAM = &request.Requirements.MinVRAM
+	}
+	providers, err := s.activeProviders(query)
+	if err != nil {
+		return nil, err
+	}
+
+	load, err := s.providerJobCounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var history quoteHistory
+	if err := s.db.Where("status = ? AND assigned_at IS NOT NULL AND completed_at IS NOT NULL", JobStatusCompleted).
+		Order("completed_at DESC").
+		Limit(quoteHistoryLimit).
+		Find(&history.jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load job history: %w", err)
+	}
+
+	candidates := []matchCandidate{}
+	for i := range providers {
+		provider := providers[i]
+
+		issues := ValidateProvider(&provider, request.Requirements)
+		if HasBlockingIssues(issues) {
+			continue
+		}
+
+		counts := load[common.HexToAddress(provider.WalletAddress).Hex()]
+		candidate := matchCandidate{
+			provider:    provider,
+			issues:      issues,
+			activeJobs:  counts.assigned + counts.running,
+			queuedJobs:  counts.queued,
+			concurrency: s.providerConcurrency(&provider),
+		}
+
+		// Network-wide rates are the same for every provider, so only a provider's own
+		// prices count towards the price score
+		if card := provider.RateCard; card != nil {
+			candidate.rate = card.HourlyPrice(request.DockerImage, string(request.WorkloadClass))
+			candidate.rateSource = RateSourceRateCard
+		} else if rate, rateSource := history.rate(provider.WalletAddress); rateSource == RateSourceProvider {
+			candidate.rate, _ = new(big.Int).SetString(weiString(rate*3600), 10)
+			candidate.rateSource = rateSource
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	weights := s.config.MatchWeights
+	if weights == nil {
+		weights = DefaultMatchWeights()
+	}
+	matches := scoreMatches(candidates, request, weights)
+	if len(matches) > request.Limit {
+		matches = matches[:request.Limit]
 	}
 
-	// Process the event using existing logic
-	return s.ProcessJobCreatedEvent(jobEvent)
+	return matches, nil
 }
 
-// ProcessJobCreatedEvent processes a JobCreated event
-func (s *Service) ProcessJobCreatedEvent(event JobCreatedEvent) error {
-	s.logger.Info("Processing JobCreated event", "job_id", event.JobID)
+// providerJobs counts a provider's unfinished jobs
+type providerJobs struct {
+	assigned int64
+	running  int64
+	queued   int64
+}
 
-	// Create job record
-	job := &Job{
-		ID:              event.JobID,
-		RenterAddress:   event.RenterAddress,
-		ProviderAddress: event.ProviderAddress,
-		DockerImage:     event.DockerImage,
-		InputFileCID:    event.InputFileCID,
-		PaymentAmount:   event.PaymentAmount,
-		Status:          JobStatusCreated,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
+// providerJobCounts counts every provider's assigned, running and queued jobs, keyed by provider address
+func (s *Service) providerJobCounts() (map[string]providerJobs, error) {
+	var rows []struct {
+		ProviderAddress string
+		Status          JobStatus
+		Count           int64
+	}
+	if err := s.db.Model(&Job{}).
+		Select("provider_address, status, COUNT(*) AS count").
+		Where("status IN ?", []JobStatus{JobStatusQueued, JobStatusAssigned, JobStatusRunning}).
+		Group("provider_address, status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count provider jobs: %w", err)
 	}
 
-	// Save job to database
-	if err := s.db.Create(job).Error; err != nil {
-		return fmt.Errorf("failed to create job: %w", err)
+	counts := make(map[string]providerJobs)
+	for _, row := range rows {
+		address := common.HexToAddress(row.ProviderAddress).Hex()
+		jobs := counts[address]
+		switch row.Status {
+		case JobStatusAssigned:
+			jobs.assigned += row.Count
+		case JobStatusRunning:
+			jobs.running += row.Count
+		case JobStatusQueued:
+			jobs.queued += row.Count
+		}
+		counts[address] = jobs
 	}
 
-	// Dispatch job to provider
-	if err := s.dispatchJobToProvider(event); err != nil {
-		return fmt.Errorf("failed to dispatch job: %w", err)
+	return counts, nil
+}
+
+// activeProviders fetches the online providers from the node registry
+func (s *Service) activeProviders(query node_registry.NodeQuery) ([]node_registry.Provider, error) {
+	responseData, err := s.natsClient.PublishWithReply("nodes.query", query, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query node registry: %w", err)
 	}
 
-	s.logger.Info("Job created and dispatched successfully", "job_id", event.JobID)
-	return nil
+	var response node_registry.ActiveNodesResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal active nodes: %w", err)
+	}
+
+	return response.Nodes, nil
 }
 
-// dispatchJobToProvider dispatches a job to a specific provider
-func (s *Service) dispatchJobToProvider(event JobCreatedEvent) error {
-	// Create job assignment
-	assignment := JobAssignment{
-		JobID:        event.JobID,
-		DockerImage:  event.DockerImage,
-		InputFileCID: event.InputFileCID,
+// checkProviderRequirements validates a provider against job requirements using the
+// node registry's view of the provider
+func (s *Service) checkProviderRequirements(providerAddress string, requirements ResourceRequirements) []RequirementIssue {
+	provider, err := s.lookupProvider(providerAddress)
+	if err != nil {
+		s.logger.Warn("Failed to look up provider in node registry", "error", err, "provider", providerAddress)
+		return []RequirementIssue{{
+			Severity: IssueSeverityWarning,
+			Code:     IssueRegistryUnavailable,
+			Message:  "node registry could not be reached to verify the provider",
+		}}
 	}
 
-	// Publish to provider-specific subject
-	subject := fmt.Sprintf("jobs.dispatch.%s", event.ProviderAddress)
-	if err := s.natsClient.Publish(subject, assignment); err != nil {
-		return fmt.Errorf("failed to publish job assignment: %w", err)
+	return ValidateProvider(provider, requirements)
+}
+
+// lookupProvider fetches a provider from the node registry, returning nil if it is not registered
+func (s *Service) lookupProvider(providerAddress string) (*node_registry.Provider, error) {
+	lookup := node_registry.NodeLookup{WalletAddress: providerAddress}
+
+	responseData, err := s.natsClient.PublishWithReply("nodes.get", lookup, 5*time.Second)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update job status to assigned
-	now := time.Now()
-	if err := s.db.Model(&Job{}).
-		Where("id = ?", event.JobID).
-		Updates(map[string]interface{}{
-			"status":      JobStatusAssigned,
-			"assigned_at": now,
-			"updated_at":  now,
-		}).Error; err != nil {
-		return fmt.Errorf("failed to update job status: %w", err)
+	var response node_registry.NodeLookupResponse
+	if err := json.Unmarshal(responseData, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal node lookup: %w", err)
 	}
 
-	s.logger.Info("Job dispatched to provider", "job_id", event.JobID, "provider", event.ProviderAddress)
-	return nil
+	return response.Node, nil
 }
 
 // GetJobs retrieves jobs based on query criteria
 func (s *Service) GetJobs(query JobQuery) ([]Job, error) {
 	var jobs []Job
 
+	order, err := ValidateJobQuery(&query)
+	if err != nil {
+		return nil, err
+	}
+
 	db := s.db
 
+	if query.JobID != "" {
+		db = db.Where("id = ?", query.JobID)
+	}
+
 	if query.RenterAddress != "" {
 		db = db.Where("renter_address = ?", query.RenterAddress)
 	}
@@ -269,22 +3342,106 @@ func (s *Service) GetJobs(query JobQuery) ([]Job, error) {
 		db = db.Where("status = ?", query.Status)
 	}
 
+	// Labels with values are matched by containment, bare keys by presence
+	values := map[string]string{}
+	for key, value := range query.Labels {
+		if value == "" {
+			db = db.Where("labels -> ? IS NOT NULL", key)
+		} else {
+			values[key] = value
+		}
+	}
+	if len(values) > 0 {
+		labels, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal label filter: %w", err)
+		}
+		db = db.Where("labels @> ?::jsonb", string(labels))
+	}
+
+	if query.DockerImage != "" {
+		db = db.Where("(docker_image = ? OR docker_image LIKE ? OR docker_image LIKE ?)",
+			query.DockerImage, query.DockerImage+":%", query.DockerImage+"@%")
+	}
+
+	if query.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *query.CreatedTo)
+	}
+	if query.CompletedFrom != nil {
+		db = db.Where("completed_at >= ?", *query.CompletedFrom)
+	}
+	if query.CompletedTo != nil {
+		db = db.Where("completed_at <= ?", *query.CompletedTo)
+	}
+
+	if query.MinPayment != "" {
+		db = db.Where("CAST(NULLIF(payment_amount, '') AS NUMERIC) >= CAST(? AS NUMERIC)", query.MinPayment)
+	}
+	if query.MaxPayment != "" {
+		db = db.Where("CAST(NULLIF(payment_amount, '') AS NUMERIC) <= CAST(? AS NUMERIC)", query.MaxPayment)
+	}
+
+	if query.ChainID != "" {
+		db = db.Where("chain_id = ?", query.ChainID)
+	}
+
+	if search := strings.TrimSpace(query.Search); search != "" {
+		pattern := containsPattern(search)
+		db = db.Where("(docker_image ILIKE ? OR error_message ILIKE ?)", pattern, pattern)
+	}
+
 	// Set default limit if not specified
 	limit := query.Limit
 	if limit <= 0 {
 		limit = 100
 	}
 
-	// Order by creation time descending
-	db = db.Order("created_at DESC")
+	db = db.Order(order)
 
 	if err := db.Offset(query.Offset).Limit(limit).Find(&jobs).Error; err != nil {
 		return nil, fmt.Errorf("failed to get jobs: %w", err)
 	}
 
+	if err := s.annotateQueuePositions(jobs); err != nil {
+		return nil, err
+	}
+
 	return jobs, nil
 }
 
+// annotateQueuePositions sets the 1-based position of each queued job in its provider's queue
+func (s *Service) annotateQueuePositions(jobs []Job) error {
+	positions := make(map[string]int)
+	loaded := make(map[string]bool)
+
+	for i := range jobs {
+		if jobs[i].Status != JobStatusQueued {
+			continue
+		}
+
+		provider := jobs[i].ProviderAddress
+		if !loaded[provider] {
+			var queued []Job
+			if err := s.db.Where("provider_address = ? AND status = ?", provider, JobStatusQueued).Find(&queued).Error; err != nil {
+				return fmt.Errorf("failed to load provider queue: %w", err)
+			}
+			SortQueue(queued)
+
+			for position, job := range queued {
+				positions[job.ID] = position + 1
+			}
+			loaded[provider] = true
+		}
+
+		jobs[i].QueuePosition = positions[jobs[i].ID]
+	}
+
+	return nil
+}
+
 // GetJobByID retrieves a job by its ID
 func (s *Service) GetJobByID(jobID string) (*Job, error) {
 	var job Job
@@ -328,9 +3485,29 @@ func (s *Service) UpdateJobStatus(jobID string, status JobStatus, errorMessage s
 	}
 
 	s.logger.Info("Updated job status", "job_id", jobID, "status", status)
+
+	if status.IsTerminal() {
+		return s.handleJobFinished(jobID)
+	}
+
 	return nil
 }
 
+// handleJobFinished updates the pipelines and verification groups a finished job belongs to
+func (s *Service) handleJobFinished(jobID string) error {
+	// Secrets are only needed until the provider has run the job
+	if err := s.db.Where("job_id = ?", jobID).Delete(&JobSecret{}).Error; err != nil {
+		return fmt.Errorf("failed to purge job secrets: %w", err)
+	}
+
+	// Finished jobs may unblock the next stage of a pipeline
+	if err := s.advancePipeline(jobID); err != nil {
+		return err
+	}
+
+	return s.evaluateVerification(jobID)
+}
+
 // GetJobsByProvider retrieves all jobs for a specific provider
 func (s *Service) GetJobsByProvider(providerAddress string, limit, offset int) ([]Job, error) {
 	var jobs []Job
@@ -0,0 +1,133 @@
+package job_dispatcher
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// assignmentSignatureDomain separates assignment signatures from any other message the
+// dispatcher key might sign
+const assignmentSignatureDomain = "lamda-job-assignment:v1"
+
+// DispatcherKeyResponse describes the key the dispatcher signs assignments with
+type DispatcherKeyResponse struct {
+	Address   string `json:"address"`
+	PublicKey string `json:"publicKey"`
+	Scheme    string `json:"scheme"`
+}
+
+// AssignmentSpecHash hashes the parts of an assignment that tell the provider what to run
+func AssignmentSpecHash(assignment JobAssignment) (common.Hash, error) {
+	spec := struct {
+		DockerImage  string            `json:"dockerImage"`
+		InputFileCID string            `json:"inputFileCID"`
+		Command      []string          `json:"command,omitempty"`
+		Env          map[string]string `json:"env,omitempty"`
+		Secrets      map[string]string `json:"secrets,omitempty"`
+		Attempt      int               `json:"attempt,omitempty"`
+	}{
+		DockerImage:  assignment.DockerImage,
+		InputFileCID: assignment.InputFileCID,
+		Command:      assignment.Command,
+		Env:          assignment.Env,
+		Secrets:      assignment.Secrets,
+		Attempt:      assignment.Attempt,
+	}
+
+	// encoding/json writes struct fields in order and sorts map keys, so this is canonical
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to marshal assignment spec: %w", err)
+	}
+
+	return crypto.Keccak256Hash(data), nil
+}
+
+// assignmentDigest is the hash signed by the dispatcher:
+// keccak256(domain || jobId || txHash || specHash || uint64 expiresAt)
+func assignmentDigest(assignment JobAssignment, specHash common.Hash) ([]byte, error) {
+	jobID, err := hexutil.Decode(assignment.JobID)
+	if err != nil || len(jobID) != common.HashLength {
+		return nil, fmt.Errorf("job ID must be a 32-byte hex string")
+	}
+
+	expiresAt := make([]byte, 8)
+	binary.BigEndian.PutUint64(expiresAt, uint64(assignment.ExpiresAt))
+
+	return crypto.Keccak256(
+		[]byte(assignmentSignatureDomain),
+		jobID,
+		common.HexToHash(assignment.TransactionHash).Bytes(),
+		specHash.Bytes(),
+		expiresAt,
+	), nil
+}
+
+// SignAssignment sets the assignment's spec hash, expiry and dispatcher signature
+func SignAssignment(assignment *JobAssignment, key *ecdsa.PrivateKey, ttl time.Duration) error {
+	specHash, err := AssignmentSpecHash(*assignment)
+	if err != nil {
+		return err
+	}
+
+	assignment.SpecHash = specHash.Hex()
+	assignment.ExpiresAt = time.Now().Add(ttl).Unix()
+
+	digest, err := assignmentDigest(*assignment, specHash)
+	if err != nil {
+		return err
+	}
+
+	signature, err := crypto.Sign(digest, key)
+	if err != nil {
+		return fmt.Errorf("failed to sign assignment: %w", err)
+	}
+
+	assignment.Signature = hexutil.Encode(signature)
+	return nil
+}
+
+// VerifyAssignment checks that an assignment was signed by the dispatcher, that its spec was
+// not altered and that it has not expired. Agents should also remember job IDs they have
+// accepted until their expiry to reject replays.
+func VerifyAssignment(assignment JobAssignment, dispatcher common.Address, now time.Time) error {
+	specHash, err := AssignmentSpecHash(assignment)
+	if err != nil {
+		return err
+	}
+	if specHash.Hex() != assignment.SpecHash {
+		return fmt.Errorf("assignment spec does not match its signed spec hash")
+	}
+
+	if now.Unix() > assignment.ExpiresAt {
+		return fmt.Errorf("assignment expired at %s", time.Unix(assignment.ExpiresAt, 0).UTC().Format(time.RFC3339))
+	}
+
+	digest, err := assignmentDigest(assignment, specHash)
+	if err != nil {
+		return err
+	}
+
+	signature, err := hexutil.Decode(assignment.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	publicKey, err := crypto.SigToPub(digest, signature)
+	if err != nil {
+		return fmt.Errorf("failed to recover signer: %w", err)
+	}
+
+	if crypto.PubkeyToAddress(*publicKey) != dispatcher {
+		return fmt.Errorf("assignment was not signed by the dispatcher")
+	}
+
+	return nil
+}
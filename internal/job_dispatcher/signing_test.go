@@ -0,0 +1,65 @@
+package job_dispatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestSignAssignment_Verify(t *testing.T) {
+	dispatcherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	dispatcher := crypto.PubkeyToAddress(dispatcherKey.PublicKey)
+
+	assignment := JobAssignment{
+		JobID:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		DockerImage:     "nvidia/cuda:11.8-base",
+		InputFileCID:    "QmXabc123def456ghi789jkl012mno345pqr678stu901vwx234yz",
+		Command:         []string{"python", "train.py"},
+		Env:             map[string]string{"EPOCHS": "10", "LR": "0.01"},
+		TransactionHash: "0xabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcdefabcd",
+	}
+
+	if err := SignAssignment(&assignment, dispatcherKey, time.Hour); err != nil {
+		t.Fatalf("failed to sign assignment: %v", err)
+	}
+	if err := VerifyAssignment(assignment, dispatcher, time.Now()); err != nil {
+		t.Fatalf("expected valid assignment, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(a *JobAssignment)
+		now    time.Time
+	}{
+		{name: "altered image", mutate: func(a *JobAssignment) { a.DockerImage = "evil/miner:latest" }},
+		{name: "altered env", mutate: func(a *JobAssignment) { a.Env = map[string]string{"EPOCHS": "1000"} }},
+		{name: "other job", mutate: func(a *JobAssignment) {
+			a.JobID = "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+		}},
+		{name: "extended expiry", mutate: func(a *JobAssignment) { a.ExpiresAt += 3600 }},
+		{name: "expired", mutate: func(a *JobAssignment) {}, now: time.Now().Add(2 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := assignment
+			tt.mutate(&tampered)
+			now := tt.now
+			if now.IsZero() {
+				now = time.Now()
+			}
+			if err := VerifyAssignment(tampered, dispatcher, now); err == nil {
+				t.Error("expected verification to fail")
+			}
+		})
+	}
+
+	otherKey, _ := crypto.GenerateKey()
+	if err := VerifyAssignment(assignment, crypto.PubkeyToAddress(otherKey.PublicKey), time.Now()); err == nil {
+		t.Error("expected assignment to fail verification against another dispatcher")
+	}
+}
@@ -0,0 +1,109 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"time"
+)
+
+// Telemetry tuning
+const (
+	// maxTelemetryClockSkew bounds how far a sample's timestamp may be from the dispatcher's clock
+	maxTelemetryClockSkew = 5 * time.Minute
+	// gpuBoundUtilization is the average GPU utilization above which a job counts as GPU-bound
+	gpuBoundUtilization = 80.0
+	// vramMisreportTolerance allows for drivers reporting slightly more memory than the card's
+	// marketed size
+	vramMisreportTolerance = 1.05
+)
+
+// ValidateTelemetrySample checks a sample's readings are plausible, its timestamp is close
+// to now and it carries a nonce
+func ValidateTelemetrySample(sample *TelemetrySample, now time.Time) error {
+	if sample.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+	if skew := now.Sub(sample.Timestamp); skew > maxTelemetryClockSkew || skew < -maxTelemetryClockSkew {
+		return fmt.Errorf("telemetry timestamp %s is too far from the current time", sample.Timestamp.UTC().Format(time.RFC3339))
+	}
+	if err := validateReportNonce(sample.Nonce); err != nil {
+		return err
+	}
+
+	if sample.GPUUtilization < 0 || sample.GPUUtilization > 100 {
+		return fmt.Errorf("gpuUtilization must be between 0 and 100")
+	}
+	if sample.CPUUtilization < 0 || sample.CPUUtilization > 100 {
+		return fmt.Errorf("cpuUtilization must be between 0 and 100")
+	}
+	if sample.VRAMUsedMB < 0 || sample.RAMUsedMB < 0 || sample.PowerDrawW < 0 {
+		return fmt.Errorf("memory and power readings must not be negative")
+	}
+	if sample.GPUTemperatureC < -50 || sample.GPUTemperatureC > 150 {
+		return fmt.Errorf("gpuTemperatureC is out of range")
+	}
+
+	return nil
+}
+
+// TelemetryBucketStart returns the start of the downsampling window a timestamp falls in
+func TelemetryBucketStart(timestamp time.Time, width time.Duration) time.Time {
+	return timestamp.UTC().Truncate(width)
+}
+
+// Add folds a sample into the bucket's running averages and peaks
+func (b *JobMetricBucket) Add(sample TelemetrySample) {
+	n := float64(b.Samples)
+	average := func(current, value float64) float64 {
+		return (current*n + value) / (n + 1)
+	}
+
+	b.AvgGPUUtilization = average(b.AvgGPUUtilization, sample.GPUUtilization)
+	b.AvgVRAMUsedMB = average(b.AvgVRAMUsedMB, sample.VRAMUsedMB)
+	b.AvgGPUTemperatureC = average(b.AvgGPUTemperatureC, sample.GPUTemperatureC)
+	b.AvgPowerDrawW = average(b.AvgPowerDrawW, sample.PowerDrawW)
+	b.AvgCPUUtilization = average(b.AvgCPUUtilization, sample.CPUUtilization)
+	b.AvgRAMUsedMB = average(b.AvgRAMUsedMB, sample.RAMUsedMB)
+
+	b.MaxGPUUtilization = max(b.MaxGPUUtilization, sample.GPUUtilization)
+	b.MaxVRAMUsedMB = max(b.MaxVRAMUsedMB, sample.VRAMUsedMB)
+	b.MaxGPUTemperatureC = max(b.MaxGPUTemperatureC, sample.GPUTemperatureC)
+	b.MaxPowerDrawW = max(b.MaxPowerDrawW, sample.PowerDrawW)
+	b.MaxRAMUsedMB = max(b.MaxRAMUsedMB, sample.RAMUsedMB)
+
+	b.Samples++
+}
+
+// SummarizeMetrics combines a job's buckets, weighting averages by sample count, and flags
+// providers whose reported VRAM use exceeds the VRAM (in GB) they registered
+func SummarizeMetrics(buckets []JobMetricBucket, registeredVRAMGB int) JobMetricsSummary {
+	summary := JobMetricsSummary{}
+	if registeredVRAMGB > 0 {
+		summary.RegisteredVRAMMB = registeredVRAMGB * 1024
+	}
+
+	for _, bucket := range buckets {
+		weight := float64(bucket.Samples)
+		summary.AvgGPUUtilization += bucket.AvgGPUUtilization * weight
+		summary.AvgPowerDrawW += bucket.AvgPowerDrawW * weight
+		summary.AvgCPUUtilization += bucket.AvgCPUUtilization * weight
+		summary.PeakVRAMUsedMB = max(summary.PeakVRAMUsedMB, bucket.MaxVRAMUsedMB)
+		summary.PeakGPUTemperature = max(summary.PeakGPUTemperature, bucket.MaxGPUTemperatureC)
+		summary.PeakRAMUsedMB = max(summary.PeakRAMUsedMB, bucket.MaxRAMUsedMB)
+		summary.Samples += bucket.Samples
+	}
+	if summary.Samples == 0 {
+		return summary
+	}
+
+	total := float64(summary.Samples)
+	summary.AvgGPUUtilization /= total
+	summary.AvgPowerDrawW /= total
+	summary.AvgCPUUtilization /= total
+	summary.GPUBound = summary.AvgGPUUtilization >= gpuBoundUtilization
+
+	if summary.RegisteredVRAMMB > 0 {
+		summary.VRAMExceedsRegistered = summary.PeakVRAMUsedMB > float64(summary.RegisteredVRAMMB)*vramMisreportTolerance
+	}
+
+	return summary
+}
@@ -0,0 +1,62 @@
+package job_dispatcher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetricBuckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 45, 0, time.UTC)
+
+	sample := TelemetrySample{Timestamp: now, Nonce: "3f9a2c7e1b", GPUUtilization: 90, VRAMUsedMB: 20000, GPUTemperatureC: 70, PowerDrawW: 300, CPUUtilization: 10, RAMUsedMB: 8000}
+	if err := ValidateTelemetrySample(&sample, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if start := TelemetryBucketStart(now, time.Minute); !start.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected bucket start %s", start)
+	}
+
+	first := JobMetricBucket{}
+	first.Add(sample)
+	first.Add(TelemetrySample{GPUUtilization: 70, VRAMUsedMB: 22000, GPUTemperatureC: 75, PowerDrawW: 320})
+	if first.Samples != 2 || first.AvgGPUUtilization != 80 || first.MaxVRAMUsedMB != 22000 || first.MaxGPUTemperatureC != 75 {
+		t.Errorf("unexpected bucket: %+v", first)
+	}
+
+	second := JobMetricBucket{}
+	second.Add(TelemetrySample{GPUUtilization: 100, VRAMUsedMB: 24000})
+
+	summary := SummarizeMetrics([]JobMetricBucket{first, second}, 24)
+	if summary.Samples != 3 {
+		t.Errorf("expected 3 samples, got %d", summary.Samples)
+	}
+	if summary.AvgGPUUtilization < 86.6 || summary.AvgGPUUtilization > 86.7 {
+		t.Errorf("expected sample-weighted average, got %v", summary.AvgGPUUtilization)
+	}
+	if !summary.GPUBound {
+		t.Error("expected job to be GPU-bound")
+	}
+	if summary.VRAMExceedsRegistered {
+		t.Error("24000 MB fits within a registered 24 GB")
+	}
+	if summary := SummarizeMetrics([]JobMetricBucket{first, second}, 16); !summary.VRAMExceedsRegistered {
+		t.Error("expected 24000 MB to exceed a registered 16 GB")
+	}
+
+	nonce := "3f9a2c7e1b"
+	invalid := []TelemetrySample{
+		{Nonce: nonce},
+		{Timestamp: now},
+		{Timestamp: now.Add(-time.Hour), Nonce: nonce},
+		{Timestamp: now, Nonce: nonce, GPUUtilization: 120},
+		{Timestamp: now, Nonce: nonce, CPUUtilization: -1},
+		{Timestamp: now, Nonce: nonce, VRAMUsedMB: -1},
+		{Timestamp: now, Nonce: nonce, GPUTemperatureC: 400},
+	}
+	for i := range invalid {
+		if err := ValidateTelemetrySample(&invalid[i], now); err == nil {
+			t.Errorf("sample %d: expected error", i)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package job_dispatcher
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"lamda_backend/pkg/contracts"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/robfig/cron/v3"
+)
+
+// FundingRequestType identifies JobFundingRequest notifications
+const FundingRequestType = "job_funding_request"
+
+// ValidateTemplate checks a template's spec, providers, payment and schedule
+func ValidateTemplate(template *JobTemplate) error {
+	if strings.TrimSpace(template.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(template.DockerImage) == "" {
+		return fmt.Errorf("docker_image is required")
+	}
+	if len(template.PreferredProviders) == 0 {
+		return fmt.Errorf("at least one preferred provider is required")
+	}
+	for _, provider := range template.PreferredProviders {
+		if !common.IsHexAddress(provider) {
+			return fmt.Errorf("preferred provider %s is not a valid address", provider)
+		}
+	}
+	if template.DelegateAddress != "" && !common.IsHexAddress(template.DelegateAddress) {
+		return fmt.Errorf("delegate_address is not a valid address")
+	}
+
+	payment, ok := new(big.Int).SetString(template.PaymentAmount, 10)
+	if !ok || payment.Sign() <= 0 {
+		return fmt.Errorf("payment_amount must be a positive amount in wei")
+	}
+
+	if template.Schedule != "" {
+		if _, err := cron.ParseStandard(template.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// NewJobID generates a random bytes32 job ID for a template run
+func NewJobID() (string, error) {
+	var id [32]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	return hexutil.Encode(id[:]), nil
+}
+
+// BuildCreateJobTransaction builds the unsigned JobManager.createJob call funding a job
+func BuildCreateJobTransaction(contractAddr string, chainID *big.Int, jobID, providerAddress, payment string) (UnsignedTransaction, error) {
+	jobManagerABI, err := contracts.JobManagerMetaData.GetAbi()
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to load JobManager ABI: %w", err)
+	}
+
+	id, err := hexutil.Decode(jobID)
+	if err != nil || len(id) != common.HashLength {
+		return UnsignedTransaction{}, fmt.Errorf("job ID must be a 32-byte hex string")
+	}
+
+	value, ok := new(big.Int).SetString(payment, 10)
+	if !ok {
+		return UnsignedTransaction{}, fmt.Errorf("invalid payment amount: %s", payment)
+	}
+
+	data, err := jobManagerABI.Pack("createJob", common.BytesToHash(id), common.HexToAddress(providerAddress))
+	if err != nil {
+		return UnsignedTransaction{}, fmt.Errorf("failed to pack createJob call: %w", err)
+	}
+
+	return UnsignedTransaction{
+		ChainID: chainID.String(),
+		To:      common.HexToAddress(contractAddr).Hex(),
+		Value:   value.String(),
+		Data:    hexutil.Encode(data),
+	}, nil
+}
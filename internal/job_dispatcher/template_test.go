@@ -0,0 +1,86 @@
+package job_dispatcher
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"lamda_backend/pkg/contracts"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	valid := func() *JobTemplate {
+		return &JobTemplate{
+			Name:               "nightly-inference",
+			DockerImage:        "lamda/infer:2.0",
+			PreferredProviders: []string{testProvider},
+			PaymentAmount:      "1000000000000000",
+			Schedule:           "0 2 * * *",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(t *JobTemplate)
+		wantErr bool
+	}{
+		{name: "valid", mutate: func(t *JobTemplate) {}},
+		{name: "no schedule", mutate: func(t *JobTemplate) { t.Schedule = "" }},
+		{name: "missing image", mutate: func(t *JobTemplate) { t.DockerImage = "" }, wantErr: true},
+		{name: "no providers", mutate: func(t *JobTemplate) { t.PreferredProviders = nil }, wantErr: true},
+		{name: "bad provider", mutate: func(t *JobTemplate) { t.PreferredProviders = []string{"gpu-1"} }, wantErr: true},
+		{name: "zero payment", mutate: func(t *JobTemplate) { t.PaymentAmount = "0" }, wantErr: true},
+		{name: "bad schedule", mutate: func(t *JobTemplate) { t.Schedule = "every night" }, wantErr: true},
+		{name: "bad delegate", mutate: func(t *JobTemplate) { t.DelegateAddress = "0x123" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := valid()
+			tt.mutate(template)
+			if err := ValidateTemplate(template); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildCreateJobTransaction(t *testing.T) {
+	jobID, err := NewJobID()
+	if err != nil {
+		t.Fatalf("failed to generate job ID: %v", err)
+	}
+	contract := "0x108f2c400C9828d8044a5F6985f0C9589B90758D"
+
+	tx, err := BuildCreateJobTransaction(contract, big.NewInt(97), jobID, testProvider, "5000")
+	if err != nil {
+		t.Fatalf("failed to build transaction: %v", err)
+	}
+	if tx.To != contract || tx.Value != "5000" || tx.ChainID != "97" {
+		t.Errorf("unexpected transaction: %+v", tx)
+	}
+
+	data, err := hexutil.Decode(tx.Data)
+	if err != nil {
+		t.Fatalf("failed to decode calldata: %v", err)
+	}
+	if !strings.HasPrefix(tx.Data, "0x7706ebc8") {
+		t.Errorf("expected createJob selector, got %s", tx.Data[:10])
+	}
+
+	jobManagerABI, _ := contracts.JobManagerMetaData.GetAbi()
+	args, err := jobManagerABI.Methods["createJob"].Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("failed to unpack calldata: %v", err)
+	}
+	packedID := args[0].([32]byte)
+	if hexutil.Encode(packedID[:]) != jobID {
+		t.Errorf("calldata job ID does not match %s", jobID)
+	}
+	if args[1].(common.Address) != common.HexToAddress(testProvider) {
+		t.Errorf("calldata provider does not match %s", testProvider)
+	}
+}
@@ -0,0 +1,108 @@
+package job_dispatcher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Verification deadlines
+const (
+	defaultVerificationTimeout = 24 * time.Hour
+	minVerificationTimeout     = 10 * time.Minute
+	maxVerificationTimeout     = 7 * 24 * time.Hour
+	// verificationSweepInterval is how often groups past their deadline are resolved
+	verificationSweepInterval = time.Minute
+)
+
+// ValidateVerificationRequest checks that a verification request names distinct jobs on
+// distinct providers and a quorum that can only be met by a majority
+func ValidateVerificationRequest(request *VerificationRequest) error {
+	if len(request.Jobs) < 2 {
+		return fmt.Errorf("verification needs at least two jobs")
+	}
+
+	jobIDs := make(map[string]bool)
+	providers := make(map[string]bool)
+	for _, job := range request.Jobs {
+		if !jobIDPattern.MatchString(job.JobID) {
+			return fmt.Errorf("job_id %s must be a 0x-prefixed 32-byte hex string", job.JobID)
+		}
+		if !common.IsHexAddress(job.ProviderAddress) {
+			return fmt.Errorf("provider_address %s is not a valid address", job.ProviderAddress)
+		}
+
+		jobID := strings.ToLower(job.JobID)
+		provider := common.HexToAddress(job.ProviderAddress).Hex()
+		if jobIDs[jobID] {
+			return fmt.Errorf("duplicate job_id: %s", job.JobID)
+		}
+		if providers[provider] {
+			return fmt.Errorf("each job must run on a different provider, %s appears twice", provider)
+		}
+		jobIDs[jobID] = true
+		providers[provider] = true
+	}
+
+	n := len(request.Jobs)
+	if request.Quorum == 0 {
+		request.Quorum = n/2 + 1
+	}
+	if request.Quorum <= n/2 || request.Quorum > n {
+		return fmt.Errorf("quorum must be a majority of the %d jobs (between %d and %d)", n, n/2+1, n)
+	}
+
+	if request.TimeoutSeconds == 0 {
+		request.TimeoutSeconds = int(defaultVerificationTimeout / time.Second)
+	}
+	if timeout := time.Duration(request.TimeoutSeconds) * time.Second; timeout < minVerificationTimeout || timeout > maxVerificationTimeout {
+		return fmt.Errorf("timeout_seconds must be between %d and %d", int(minVerificationTimeout/time.Second), int(maxVerificationTimeout/time.Second))
+	}
+
+	return nil
+}
+
+// ResolveVerification compares the output hashes of a group's members and sets each
+// member's outcome. Members without a hash failed or did not report one.
+func ResolveVerification(members []VerificationMember, quorum int) (VerificationStatus, string) {
+	counts := make(map[string]int)
+	for _, member := range members {
+		if member.OutputHash != "" {
+			counts[member.OutputHash]++
+		}
+	}
+
+	consensus, best := "", 0
+	for hash, count := range counts {
+		if count > best || (count == best && hash < consensus) {
+			consensus, best = hash, count
+		}
+	}
+
+	status := VerificationStatusAgreed
+	if best < quorum {
+		status, consensus = VerificationStatusDisagreed, ""
+	}
+
+	for i := range members {
+		switch {
+		case members[i].OutputHash == "":
+			members[i].Outcome = VerificationOutcomeMissing
+		case status == VerificationStatusDisagreed:
+			members[i].Outcome = VerificationOutcomeInconclusive
+		case members[i].OutputHash == consensus:
+			members[i].Outcome = VerificationOutcomeMatched
+		default:
+			members[i].Outcome = VerificationOutcomeMismatched
+		}
+	}
+
+	return status, consensus
+}
+
+// normalizeOutputHash lowercases a reported output hash so hex digests compare equal
+func normalizeOutputHash(hash string) string {
+	return strings.ToLower(strings.TrimSpace(hash))
+}
@@ -0,0 +1,96 @@
+package job_dispatcher
+
+import (
+	"testing"
+)
+
+func TestResolveVerification(t *testing.T) {
+	member := func(hash string) VerificationMember {
+		return VerificationMember{OutputHash: hash, Outcome: VerificationOutcomePending}
+	}
+
+	tests := []struct {
+		name          string
+		members       []VerificationMember
+		quorum        int
+		wantStatus    VerificationStatus
+		wantConsensus string
+		wantOutcomes  []VerificationOutcome
+	}{
+		{
+			name:          "unanimous",
+			members:       []VerificationMember{member("aa"), member("aa"), member("aa")},
+			quorum:        2,
+			wantStatus:    VerificationStatusAgreed,
+			wantConsensus: "aa",
+			wantOutcomes:  []VerificationOutcome{VerificationOutcomeMatched, VerificationOutcomeMatched, VerificationOutcomeMatched},
+		},
+		{
+			name:          "majority with one liar and one failure",
+			members:       []VerificationMember{member("aa"), member("bb"), member("aa"), member("")},
+			quorum:        2,
+			wantStatus:    VerificationStatusAgreed,
+			wantConsensus: "aa",
+			wantOutcomes:  []VerificationOutcome{VerificationOutcomeMatched, VerificationOutcomeMismatched, VerificationOutcomeMatched, VerificationOutcomeMissing},
+		},
+		{
+			name:         "split",
+			members:      []VerificationMember{member("aa"), member("bb")},
+			quorum:       2,
+			wantStatus:   VerificationStatusDisagreed,
+			wantOutcomes: []VerificationOutcome{VerificationOutcomeInconclusive, VerificationOutcomeInconclusive},
+		},
+		{
+			name:         "quorum not reached after failures",
+			members:      []VerificationMember{member("aa"), member(""), member("")},
+			quorum:       2,
+			wantStatus:   VerificationStatusDisagreed,
+			wantOutcomes: []VerificationOutcome{VerificationOutcomeInconclusive, VerificationOutcomeMissing, VerificationOutcomeMissing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, consensus := ResolveVerification(tt.members, tt.quorum)
+			if status != tt.wantStatus || consensus != tt.wantConsensus {
+				t.Errorf("got (%s, %q), want (%s, %q)", status, consensus, tt.wantStatus, tt.wantConsensus)
+			}
+			for i, want := range tt.wantOutcomes {
+				if tt.members[i].Outcome != want {
+					t.Errorf("member %d: got %s, want %s", i, tt.members[i].Outcome, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateVerificationRequest(t *testing.T) {
+	jobA := "0x1111111111111111111111111111111111111111111111111111111111111111"
+	jobB := "0x2222222222222222222222222222222222222222222222222222222222222222"
+	otherProvider := "0x108f2c400C9828d8044a5F6985f0C9589B90758D"
+
+	request := &VerificationRequest{Jobs: []VerificationJob{{jobA, testProvider}, {jobB, otherProvider}}}
+	if err := ValidateVerificationRequest(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.Quorum != 2 {
+		t.Errorf("expected default quorum of 2, got %d", request.Quorum)
+	}
+	if request.TimeoutSeconds != 86400 {
+		t.Errorf("expected default timeout of a day, got %d", request.TimeoutSeconds)
+	}
+
+	invalid := []*VerificationRequest{
+		{Jobs: []VerificationJob{{jobA, testProvider}}},
+		{Jobs: []VerificationJob{{jobA, testProvider}, {jobB, testProvider}}},
+		{Jobs: []VerificationJob{{jobA, testProvider}, {jobA, otherProvider}}},
+		{Jobs: []VerificationJob{{jobA, testProvider}, {jobB, otherProvider}}, Quorum: 1},
+		{Jobs: []VerificationJob{{jobA, testProvider}, {jobB, otherProvider}}, TimeoutSeconds: 60},
+		{Jobs: []VerificationJob{{jobA, testProvider}, {jobB, otherProvider}}, TimeoutSeconds: -1},
+	}
+	for i, request := range invalid {
+		if err := ValidateVerificationRequest(request); err == nil {
+			t.Errorf("request %d: expected error", i)
+		}
+	}
+}
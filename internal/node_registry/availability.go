@@ -0,0 +1,173 @@
+package node_registry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Availability schedule limits
+const (
+	maxMaintenanceWindows  = 16
+	maxAvailabilityWindows = 28
+	maxMaintenanceReason   = 256
+)
+
+// Reasons a provider is not accepting jobs
+const (
+	UnavailableMaintenance  = "maintenance"
+	UnavailableOutsideHours = "outside_availability_window"
+)
+
+// AvailabilityStatus is whether a provider accepts jobs at a point in time. AvailableAt is
+// when an unavailable provider is next expected back, nil if it has not said.
+type AvailabilityStatus struct {
+	Available   bool
+	Reason      string
+	AvailableAt *time.Time
+}
+
+// ValidateAvailabilitySchedule checks a schedule and normalizes it: maintenance without a
+// start begins now, maintenance that has already ended is dropped, and windows are sorted
+func ValidateAvailabilitySchedule(schedule *AvailabilitySchedule, now time.Time) error {
+	if len(schedule.Maintenance) > maxMaintenanceWindows {
+		return fmt.Errorf("at most %d maintenance windows are allowed", maxMaintenanceWindows)
+	}
+
+	maintenance := make([]MaintenanceWindow, 0, len(schedule.Maintenance))
+	for i, window := range schedule.Maintenance {
+		if window.StartsAt.IsZero() {
+			window.StartsAt = now
+		}
+		window.StartsAt = window.StartsAt.UTC()
+		if window.EndsAt != nil {
+			if !window.EndsAt.After(window.StartsAt) {
+				return fmt.Errorf("maintenance window %d must end after it starts", i)
+			}
+			endsAt := window.EndsAt.UTC()
+			window.EndsAt = &endsAt
+			if !endsAt.After(now) {
+				continue
+			}
+		}
+		window.Reason = strings.TrimSpace(window.Reason)
+		if len(window.Reason) > maxMaintenanceReason {
+			return fmt.Errorf("maintenance window %d: reason must be at most %d characters", i, maxMaintenanceReason)
+		}
+		maintenance = append(maintenance, window)
+	}
+	sort.SliceStable(maintenance, func(i, j int) bool {
+		return maintenance[i].StartsAt.Before(maintenance[j].StartsAt)
+	})
+	schedule.Maintenance = maintenance
+
+	if len(schedule.WeeklyWindows) > maxAvailabilityWindows {
+		return fmt.Errorf("at most %d availability windows are allowed", maxAvailabilityWindows)
+	}
+	for i, window := range schedule.WeeklyWindows {
+		if window.Day < time.Sunday || window.Day > time.Saturday {
+			return fmt.Errorf("availability window %d: day must be 0 (Sunday) to 6 (Saturday)", i)
+		}
+		start, err := parseClock(window.Start)
+		if err != nil {
+			return fmt.Errorf("availability window %d: start: %w", i, err)
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return fmt.Errorf("availability window %d: end: %w", i, err)
+		}
+		if end <= start {
+			return fmt.Errorf("availability window %d must end after it starts; split windows that cross midnight", i)
+		}
+	}
+	sort.SliceStable(schedule.WeeklyWindows, func(i, j int) bool {
+		a, b := schedule.WeeklyWindows[i], schedule.WeeklyWindows[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		return a.Start < b.Start
+	})
+
+	return nil
+}
+
+// Status evaluates the schedule at a point in time. Providers without weekly windows are
+// available around the clock outside maintenance.
+func (s AvailabilitySchedule) Status(now time.Time) AvailabilityStatus {
+	now = now.UTC()
+
+	reason := s.unavailableReason(now)
+	if reason == "" {
+		return AvailabilityStatus{Available: true}
+	}
+
+	// The provider is back at the earliest maintenance end or window opening when
+	// the other part of the schedule also allows it
+	candidates := []time.Time{}
+	for _, window := range s.Maintenance {
+		if window.EndsAt != nil && window.EndsAt.After(now) {
+			candidates = append(candidates, *window.EndsAt)
+		}
+	}
+	for _, window := range s.WeeklyWindows {
+		start, _ := parseClock(window.Start)
+		for week := 0; week <= 1; week++ {
+			days := (int(window.Day) - int(now.Weekday()) + 7) % 7
+			day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, days+7*week)
+			if opens := day.Add(start); opens.After(now) {
+				candidates = append(candidates, opens)
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Before(candidates[j])
+	})
+
+	status := AvailabilityStatus{Reason: reason}
+	for _, candidate := range candidates {
+		if s.unavailableReason(candidate) == "" {
+			availableAt := candidate
+			status.AvailableAt = &availableAt
+			break
+		}
+	}
+	return status
+}
+
+// unavailableReason returns why the provider is unavailable at a time, or "" if it is available
+func (s AvailabilitySchedule) unavailableReason(at time.Time) string {
+	for _, window := range s.Maintenance {
+		if !at.Before(window.StartsAt) && (window.EndsAt == nil || at.Before(*window.EndsAt)) {
+			return UnavailableMaintenance
+		}
+	}
+
+	if len(s.WeeklyWindows) == 0 {
+		return ""
+	}
+	sinceMidnight := time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute + time.Duration(at.Second())*time.Second
+	for _, window := range s.WeeklyWindows {
+		if window.Day != at.Weekday() {
+			continue
+		}
+		start, _ := parseClock(window.Start)
+		end, _ := parseClock(window.End)
+		if sinceMidnight >= start && sinceMidnight < end {
+			return ""
+		}
+	}
+	return UnavailableOutsideHours
+}
+
+// parseClock parses an "HH:MM" time of day in UTC, allowing "24:00" as the end of the day
+func parseClock(clock string) (time.Duration, error) {
+	if clock == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", clock)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
@@ -0,0 +1,124 @@
+package node_registry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateAvailabilitySchedule(t *testing.T) {
+	now := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC) // Wednesday
+	ended := now.Add(-time.Hour)
+	later := now.Add(2 * time.Hour)
+
+	schedule := AvailabilitySchedule{
+		Maintenance: []MaintenanceWindow{
+			{EndsAt: &later, Reason: " driver upgrade "},
+			{StartsAt: now.Add(-2 * time.Hour), EndsAt: &ended},
+		},
+		WeeklyWindows: []AvailabilityWindow{
+			{Day: time.Friday, Start: "00:00", End: "24:00"},
+			{Day: time.Monday, Start: "09:00", End: "17:30"},
+		},
+	}
+	if err := ValidateAvailabilitySchedule(&schedule, now); err != nil {
+		t.Fatalf("expected valid schedule, got %v", err)
+	}
+	if len(schedule.Maintenance) != 1 || !schedule.Maintenance[0].StartsAt.Equal(now) || schedule.Maintenance[0].Reason != "driver upgrade" {
+		t.Errorf("expected ended maintenance dropped and immediate maintenance to start now, got %+v", schedule.Maintenance)
+	}
+	if schedule.WeeklyWindows[0].Day != time.Monday {
+		t.Errorf("expected windows sorted by day, got %+v", schedule.WeeklyWindows)
+	}
+
+	invalid := map[string]AvailabilitySchedule{
+		"ends before start":   {Maintenance: []MaintenanceWindow{{StartsAt: now, EndsAt: &ended}}},
+		"bad day":             {WeeklyWindows: []AvailabilityWindow{{Day: 7, Start: "09:00", End: "17:00"}}},
+		"bad clock":           {WeeklyWindows: []AvailabilityWindow{{Day: time.Monday, Start: "9am", End: "17:00"}}},
+		"crosses midnight":    {WeeklyWindows: []AvailabilityWindow{{Day: time.Monday, Start: "22:00", End: "02:00"}}},
+		"empty window length": {WeeklyWindows: []AvailabilityWindow{{Day: time.Monday, Start: "09:00", End: "09:00"}}},
+	}
+	for name, schedule := range invalid {
+		if err := ValidateAvailabilitySchedule(&schedule, now); err == nil {
+			t.Errorf("%s: expected schedule to be rejected", name)
+		}
+	}
+}
+
+func TestAvailabilitySchedule_Status(t *testing.T) {
+	wednesdayNoon := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	ends := wednesdayNoon.Add(time.Hour)
+
+	weekdays := []AvailabilityWindow{}
+	for day := time.Monday; day <= time.Friday; day++ {
+		weekdays = append(weekdays, AvailabilityWindow{Day: day, Start: "08:00", End: "20:00"})
+	}
+
+	tests := []struct {
+		name            string
+		schedule        AvailabilitySchedule
+		at              time.Time
+		wantAvailable   bool
+		wantReason      string
+		wantAvailableAt *time.Time
+	}{
+		{
+			name:          "no schedule",
+			at:            wednesdayNoon,
+			wantAvailable: true,
+		},
+		{
+			name:            "scheduled maintenance",
+			schedule:        AvailabilitySchedule{Maintenance: []MaintenanceWindow{{StartsAt: wednesdayNoon.Add(-time.Minute), EndsAt: &ends}}},
+			at:              wednesdayNoon,
+			wantReason:      UnavailableMaintenance,
+			wantAvailableAt: &ends,
+		},
+		{
+			name:       "open-ended maintenance",
+			schedule:   AvailabilitySchedule{Maintenance: []MaintenanceWindow{{StartsAt: wednesdayNoon}}},
+			at:         wednesdayNoon,
+			wantReason: UnavailableMaintenance,
+		},
+		{
+			name:          "inside weekday hours",
+			schedule:      AvailabilitySchedule{WeeklyWindows: weekdays},
+			at:            wednesdayNoon,
+			wantAvailable: true,
+		},
+		{
+			name:            "friday night waits for monday",
+			schedule:        AvailabilitySchedule{WeeklyWindows: weekdays},
+			at:              time.Date(2024, 1, 5, 21, 0, 0, 0, time.UTC),
+			wantReason:      UnavailableOutsideHours,
+			wantAvailableAt: timePtr(time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC)),
+		},
+		{
+			name: "maintenance ending after hours waits for the next window",
+			schedule: AvailabilitySchedule{
+				Maintenance:   []MaintenanceWindow{{StartsAt: wednesdayNoon, EndsAt: timePtr(time.Date(2024, 1, 3, 22, 0, 0, 0, time.UTC))}},
+				WeeklyWindows: weekdays,
+			},
+			at:              wednesdayNoon,
+			wantReason:      UnavailableMaintenance,
+			wantAvailableAt: timePtr(time.Date(2024, 1, 4, 8, 0, 0, 0, time.UTC)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.schedule.Status(tt.at)
+
+			if status.Available != tt.wantAvailable || status.Reason != tt.wantReason {
+				t.Fatalf("expected available=%v reason=%q, got %+v", tt.wantAvailable, tt.wantReason, status)
+			}
+			if (tt.wantAvailableAt == nil) != (status.AvailableAt == nil) ||
+				(tt.wantAvailableAt != nil && !tt.wantAvailableAt.Equal(*status.AvailableAt)) {
+				t.Errorf("expected available_at %v, got %v", tt.wantAvailableAt, status.AvailableAt)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
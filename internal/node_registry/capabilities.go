@@ -0,0 +1,135 @@
+package node_registry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Capability profile limits
+const (
+	maxGPUCount          = 64
+	maxContainerRuntimes = 8
+)
+
+var (
+	versionPattern          = regexp.MustCompile(`^[0-9]{1,4}(\.[0-9]{1,4}){0,3}$`)
+	containerRuntimePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,31}$`)
+)
+
+// ValidateVersion checks that a version is dotted numeric, e.g. "12.2" or "535.104.05"
+func ValidateVersion(version string) error {
+	if !versionPattern.MatchString(version) {
+		return fmt.Errorf("invalid version %q: expected dotted numbers such as 12.2", version)
+	}
+	return nil
+}
+
+// CompareVersions compares two dotted numeric versions component by component,
+// returning -1, 0 or 1. Missing components count as zero, so "12" equals "12.0".
+func CompareVersions(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y int
+		if i < len(aParts) {
+			x, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			y, _ = strconv.Atoi(bParts[i])
+		}
+		if x < y {
+			return -1
+		}
+		if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// ValidateCapabilityProfile checks a reported profile and normalizes its container runtimes
+func ValidateCapabilityProfile(profile *CapabilityProfile) error {
+	if profile.GPUCount < 1 || profile.GPUCount > maxGPUCount {
+		return fmt.Errorf("gpu_count must be between 1 and %d", maxGPUCount)
+	}
+
+	versions := []struct {
+		field string
+		value string
+	}{
+		{"compute_capability", profile.ComputeCapability},
+		{"driver_version", profile.DriverVersion},
+		{"cuda_version", profile.CUDAVersion},
+	}
+	for _, version := range versions {
+		if err := ValidateVersion(version.value); err != nil {
+			return fmt.Errorf("%s: %w", version.field, err)
+		}
+	}
+
+	if profile.CPUCores < 1 {
+		return fmt.Errorf("cpu_cores must be positive")
+	}
+	if profile.RAMGB < 1 {
+		return fmt.Errorf("ram_gb must be positive")
+	}
+	if profile.FreeDiskGB < 0 {
+		return fmt.Errorf("free_disk_gb cannot be negative")
+	}
+	if profile.BandwidthMbps < 0 {
+		return fmt.Errorf("bandwidth_mbps cannot be negative")
+	}
+
+	if len(profile.ContainerRuntimes) == 0 {
+		return fmt.Errorf("at least one container runtime is required")
+	}
+	if len(profile.ContainerRuntimes) > maxContainerRuntimes {
+		return fmt.Errorf("at most %d container runtimes are allowed", maxContainerRuntimes)
+	}
+
+	seen := make(map[string]bool)
+	runtimes := make([]string, 0, len(profile.ContainerRuntimes))
+	for _, runtime := range profile.ContainerRuntimes {
+		runtime = strings.ToLower(strings.TrimSpace(runtime))
+		if !containerRuntimePattern.MatchString(runtime) {
+			return fmt.Errorf("invalid container runtime %q", runtime)
+		}
+		if !seen[runtime] {
+			seen[runtime] = true
+			runtimes = append(runtimes, runtime)
+		}
+	}
+	sort.Strings(runtimes)
+	profile.ContainerRuntimes = runtimes
+
+	return nil
+}
+
+// SameCapabilities reports whether two profiles describe the same hardware and software.
+// Free disk and bandwidth change between reports, so they do not start a new version.
+func SameCapabilities(a, b CapabilityProfile) bool {
+	if len(a.ContainerRuntimes) != len(b.ContainerRuntimes) {
+		return false
+	}
+	for i := range a.ContainerRuntimes {
+		if a.ContainerRuntimes[i] != b.ContainerRuntimes[i] {
+			return false
+		}
+	}
+	return a.GPUCount == b.GPUCount &&
+		a.ComputeCapability == b.ComputeCapability &&
+		a.DriverVersion == b.DriverVersion &&
+		a.CUDAVersion == b.CUDAVersion &&
+		a.CPUCores == b.CPUCores &&
+		a.RAMGB == b.RAMGB
+}
+
+// versionAtLeast returns a SQL condition comparing a dotted version column numerically.
+// Providers that have not reported the version never match.
+func versionAtLeast(column string) string {
+	return fmt.Sprintf("%s <> '' AND string_to_array(%s, '.')::int[] >= string_to_array(?, '.')::int[]", column, column)
+}
@@ -0,0 +1,78 @@
+package node_registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"12.2", "12.2", 0},
+		{"12", "12.0", 0},
+		{"12.10", "12.9", 1},
+		{"11.8", "12.1", -1},
+		{"535.104.05", "535.54.03", 1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestValidateCapabilityProfile(t *testing.T) {
+	valid := func() CapabilityProfile {
+		return CapabilityProfile{
+			GPUCount:          2,
+			ComputeCapability: "8.9",
+			DriverVersion:     "535.104.05",
+			CUDAVersion:       "12.2",
+			CPUCores:          32,
+			RAMGB:             128,
+			FreeDiskGB:        900,
+			BandwidthMbps:     1000,
+			ContainerRuntimes: []string{"NVIDIA", "docker", "docker"},
+		}
+	}
+
+	profile := valid()
+	if err := ValidateCapabilityProfile(&profile); err != nil {
+		t.Fatalf("expected valid profile, got %v", err)
+	}
+	if !reflect.DeepEqual(profile.ContainerRuntimes, []string{"docker", "nvidia"}) {
+		t.Errorf("expected runtimes to be normalized, got %v", profile.ContainerRuntimes)
+	}
+
+	invalid := map[string]func(p *CapabilityProfile){
+		"no gpus":          func(p *CapabilityProfile) { p.GPUCount = 0 },
+		"bad cuda version": func(p *CapabilityProfile) { p.CUDAVersion = "12.x" },
+		"no runtimes":      func(p *CapabilityProfile) { p.ContainerRuntimes = nil },
+		"bad runtime":      func(p *CapabilityProfile) { p.ContainerRuntimes = []string{"docker; rm"} },
+		"negative disk":    func(p *CapabilityProfile) { p.FreeDiskGB = -1 },
+	}
+	for name, mutate := range invalid {
+		profile := valid()
+		mutate(&profile)
+		if err := ValidateCapabilityProfile(&profile); err == nil {
+			t.Errorf("%s: expected profile to be rejected", name)
+		}
+	}
+}
+
+func TestSameCapabilities(t *testing.T) {
+	a := CapabilityProfile{GPUCount: 1, CUDAVersion: "12.2", FreeDiskGB: 500, ContainerRuntimes: []string{"docker"}}
+	b := a
+	b.FreeDiskGB = 420
+	if !SameCapabilities(a, b) {
+		t.Error("expected free disk changes to keep the same version")
+	}
+
+	b.CUDAVersion = "12.4"
+	if SameCapabilities(a, b) {
+		t.Error("expected a CUDA upgrade to start a new version")
+	}
+}
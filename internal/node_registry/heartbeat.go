@@ -0,0 +1,77 @@
+package node_registry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// HeartbeatMaxAge is how old a signed heartbeat's timestamp may be when it arrives
+	HeartbeatMaxAge = 30 * time.Second
+	// heartbeatMaxSkew tolerates provider clocks running ahead of the registry
+	heartbeatMaxSkew = 10 * time.Second
+
+	minHeartbeatNonceLength = 8
+	maxHeartbeatNonceLength = 64
+)
+
+// ValidateHeartbeat checks a signed heartbeat's timestamp, nonce and reported load
+func ValidateHeartbeat(heartbeat SignedHeartbeat, now time.Time) error {
+	sent := time.UnixMilli(heartbeat.Timestamp)
+	if sent.After(now.Add(heartbeatMaxSkew)) {
+		return fmt.Errorf("heartbeat timestamp is in the future")
+	}
+	if now.Sub(sent) > HeartbeatMaxAge {
+		return fmt.Errorf("stale heartbeat: timestamp is older than %s", HeartbeatMaxAge)
+	}
+
+	if len(heartbeat.Nonce) < minHeartbeatNonceLength || len(heartbeat.Nonce) > maxHeartbeatNonceLength {
+		return fmt.Errorf("nonce must be between %d and %d characters", minHeartbeatNonceLength, maxHeartbeatNonceLength)
+	}
+
+	if heartbeat.ActiveJobs < 0 {
+		return fmt.Errorf("active_jobs cannot be negative")
+	}
+	if heartbeat.GPUUtilization < 0 || heartbeat.GPUUtilization > 100 {
+		return fmt.Errorf("gpu_utilization must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// heartbeatNonces remembers the nonces each provider used within the heartbeat window
+// so a captured heartbeat cannot be replayed while its timestamp is still fresh
+type heartbeatNonces struct {
+	mu   sync.Mutex
+	seen map[string]map[string]time.Time
+}
+
+func newHeartbeatNonces() *heartbeatNonces {
+	return &heartbeatNonces{seen: make(map[string]map[string]time.Time)}
+}
+
+// Claim records the nonce for the provider, returning false if it was already used
+func (n *heartbeatNonces) Claim(walletAddress, nonce string, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	nonces := n.seen[walletAddress]
+	if nonces == nil {
+		nonces = make(map[string]time.Time)
+		n.seen[walletAddress] = nonces
+	}
+
+	// Nonces older than the window are covered by the timestamp check
+	for seenNonce, seenAt := range nonces {
+		if now.Sub(seenAt) > HeartbeatMaxAge+heartbeatMaxSkew {
+			delete(nonces, seenNonce)
+		}
+	}
+
+	if _, ok := nonces[nonce]; ok {
+		return false
+	}
+	nonces[nonce] = now
+	return true
+}
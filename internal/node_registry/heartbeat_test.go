@@ -0,0 +1,59 @@
+package node_registry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateHeartbeat(t *testing.T) {
+	now := time.Now()
+	valid := SignedHeartbeat{Timestamp: now.UnixMilli(), Nonce: "a1b2c3d4e5", ActiveJobs: 1, GPUUtilization: 87.5}
+
+	if err := ValidateHeartbeat(valid, now); err != nil {
+		t.Fatalf("expected valid heartbeat, got %v", err)
+	}
+
+	stale := valid
+	stale.Timestamp = now.Add(-HeartbeatMaxAge - time.Second).UnixMilli()
+	if err := ValidateHeartbeat(stale, now); err == nil {
+		t.Error("expected stale heartbeat to be rejected")
+	}
+
+	future := valid
+	future.Timestamp = now.Add(time.Minute).UnixMilli()
+	if err := ValidateHeartbeat(future, now); err == nil {
+		t.Error("expected future heartbeat to be rejected")
+	}
+
+	shortNonce := valid
+	shortNonce.Nonce = "abc"
+	if err := ValidateHeartbeat(shortNonce, now); err == nil {
+		t.Error("expected short nonce to be rejected")
+	}
+
+	overloaded := valid
+	overloaded.GPUUtilization = 120
+	if err := ValidateHeartbeat(overloaded, now); err == nil {
+		t.Error("expected utilization above 100 to be rejected")
+	}
+}
+
+func TestHeartbeatNonces_Claim(t *testing.T) {
+	nonces := newHeartbeatNonces()
+	now := time.Now()
+
+	if !nonces.Claim(testProvider, "nonce-0001", now) {
+		t.Fatal("expected first use of a nonce to be accepted")
+	}
+	if nonces.Claim(testProvider, "nonce-0001", now.Add(time.Second)) {
+		t.Error("expected replayed nonce to be rejected")
+	}
+	if !nonces.Claim("0x0000000000000000000000000000000000000001", "nonce-0001", now) {
+		t.Error("expected nonces to be tracked per provider")
+	}
+	if !nonces.Claim(testProvider, "nonce-0001", now.Add(2*time.Minute)) {
+		t.Error("expected nonce to be forgotten after the heartbeat window")
+	}
+}
+
+const testProvider = "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
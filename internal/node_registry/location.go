@@ -0,0 +1,186 @@
+package node_registry
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Where a provider's location came from
+const (
+	LocationDeclared = "declared"
+	LocationGeoIP    = "geoip"
+)
+
+// regionCentre is the point distances to a region are measured from
+type regionCentre struct {
+	latitude  float64
+	longitude float64
+}
+
+// regions are the locations providers can declare, centred on a major datacenter city in each
+var regions = map[string]regionCentre{
+	"us-east":      {39.04, -77.49},  // Ashburn
+	"us-central":   {41.88, -87.63},  // Chicago
+	"us-west":      {37.34, -121.89}, // San Jose
+	"ca-central":   {45.50, -73.57},  // Montreal
+	"sa-east":      {-23.55, -46.63}, // São Paulo
+	"eu-west":      {53.35, -6.26},   // Dublin
+	"eu-central":   {50.11, 8.68},    // Frankfurt
+	"eu-north":     {59.33, 18.07},   // Stockholm
+	"eu-south":     {45.46, 9.19},    // Milan
+	"me-central":   {25.20, 55.27},   // Dubai
+	"af-south":     {-33.92, 18.42},  // Cape Town
+	"ap-south":     {19.08, 72.88},   // Mumbai
+	"ap-southeast": {1.35, 103.82},   // Singapore
+	"ap-east":      {22.32, 114.17},  // Hong Kong
+	"ap-northeast": {35.68, 139.69},  // Tokyo
+	"oceania":      {-33.87, 151.21}, // Sydney
+}
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Regions returns the regions providers can declare, sorted by name
+func Regions() []string {
+	names := make([]string, 0, len(regions))
+	for name := range regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateRegion checks that a region is one of the known regions
+func ValidateRegion(region string) error {
+	if _, ok := regions[region]; !ok {
+		return fmt.Errorf("unknown region %q: expected one of %s", region, strings.Join(Regions(), ", "))
+	}
+	return nil
+}
+
+// ValidateCountry checks that a country is an ISO 3166-1 alpha-2 code
+func ValidateCountry(country string) error {
+	if !countryPattern.MatchString(country) {
+		return fmt.Errorf("invalid country %q: expected an ISO 3166-1 alpha-2 code such as DE", country)
+	}
+	return nil
+}
+
+// NormalizeLocation lowercases the region and uppercases the country of a declared location
+func NormalizeLocation(location *ProviderLocation) {
+	location.Region = strings.ToLower(strings.TrimSpace(location.Region))
+	location.Country = strings.ToUpper(strings.TrimSpace(location.Country))
+}
+
+// NearestRegion returns the region whose centre is closest to a point
+func NearestRegion(latitude, longitude float64) string {
+	nearest := ""
+	best := math.Inf(1)
+	for _, name := range Regions() {
+		centre := regions[name]
+		if distance := distanceKm(latitude, longitude, centre.latitude, centre.longitude); distance < best {
+			nearest, best = name, distance
+		}
+	}
+	return nearest
+}
+
+// RegionDistanceKm returns the great-circle distance between two regions' centres
+func RegionDistanceKm(a, b string) (float64, error) {
+	from, ok := regions[a]
+	if !ok {
+		return 0, ValidateRegion(a)
+	}
+	to, ok := regions[b]
+	if !ok {
+		return 0, ValidateRegion(b)
+	}
+	return distanceKm(from.latitude, from.longitude, to.latitude, to.longitude), nil
+}
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// distanceKm returns the great-circle distance between two points using the haversine formula
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// distanceColumn returns a SQL expression for a provider's distance in kilometres from a
+// region's centre, NULL for providers without coordinates. The coordinates come from the
+// region table, never from the request, so they are safe to format into the query.
+func distanceColumn(region string) (string, error) {
+	centre, ok := regions[region]
+	if !ok {
+		return "", ValidateRegion(region)
+	}
+	return fmt.Sprintf("%f * acos(LEAST(1.0, GREATEST(-1.0, sin(radians(%f)) * sin(radians(latitude)) + cos(radians(%f)) * cos(radians(latitude)) * cos(radians(longitude - (%f))))))",
+		earthRadiusKm, centre.latitude, centre.latitude, centre.longitude), nil
+}
+
+// GeoLocation is what a GeoIP database knows about an address
+type GeoLocation struct {
+	Country   string
+	Latitude  float64
+	Longitude float64
+}
+
+// GeoIPDatabase looks up addresses in a local MaxMind-format city database
+type GeoIPDatabase struct {
+	reader *maxminddb.Reader
+}
+
+// OpenGeoIPDatabase opens a GeoLite2 or GeoIP2 database file
+func OpenGeoIPDatabase(path string) (*GeoIPDatabase, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	return &GeoIPDatabase{reader: reader}, nil
+}
+
+// Locate returns the country and coordinates of an address. Only city databases carry
+// coordinates, so country databases locate nothing.
+func (g *GeoIPDatabase) Locate(address string) (*GeoLocation, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", address)
+	}
+
+	var record struct {
+		Country struct {
+			ISOCode string `maxminddb:"iso_code"`
+		} `maxminddb:"country"`
+		Location struct {
+			Latitude  *float64 `maxminddb:"latitude"`
+			Longitude *float64 `maxminddb:"longitude"`
+		} `maxminddb:"location"`
+	}
+	if err := g.reader.Lookup(ip, &record); err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", address, err)
+	}
+	if record.Country.ISOCode == "" || record.Location.Latitude == nil || record.Location.Longitude == nil {
+		return nil, fmt.Errorf("no location known for %s", address)
+	}
+
+	return &GeoLocation{
+		Country:   record.Country.ISOCode,
+		Latitude:  *record.Location.Latitude,
+		Longitude: *record.Location.Longitude,
+	}, nil
+}
+
+// Close releases the database file
+func (g *GeoIPDatabase) Close() error {
+	return g.reader.Close()
+}
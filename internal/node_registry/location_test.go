@@ -0,0 +1,49 @@
+package node_registry
+
+import (
+	"testing"
+)
+
+func TestNearestRegion(t *testing.T) {
+	cases := map[string][2]float64{
+		"eu-central":   {52.52, 13.40},   // Berlin
+		"us-west":      {47.61, -122.33}, // Seattle
+		"ap-southeast": {3.14, 101.69},   // Kuala Lumpur
+		"sa-east":      {-34.60, -58.38}, // Buenos Aires
+	}
+	for want, point := range cases {
+		if got := NearestRegion(point[0], point[1]); got != want {
+			t.Errorf("expected %v to be nearest %s, got %s", point, want, got)
+		}
+	}
+}
+
+func TestRegionDistanceKm(t *testing.T) {
+	distance, err := RegionDistanceKm("eu-west", "eu-central")
+	if err != nil || distance < 1000 || distance > 1200 {
+		t.Errorf("expected Dublin to Frankfurt to be about 1,090km, got %v (%v)", distance, err)
+	}
+	if same, _ := RegionDistanceKm("oceania", "oceania"); same != 0 {
+		t.Errorf("expected zero distance within a region, got %v", same)
+	}
+	if _, err := RegionDistanceKm("eu-west", "atlantis"); err == nil {
+		t.Error("expected unknown region to be rejected")
+	}
+}
+
+func TestNormalizeLocation(t *testing.T) {
+	location := ProviderLocation{Region: " EU-West ", Country: "ie"}
+	NormalizeLocation(&location)
+	if location.Region != "eu-west" || location.Country != "IE" {
+		t.Errorf("unexpected normalized location %+v", location)
+	}
+	if err := ValidateRegion(location.Region); err != nil {
+		t.Errorf("expected region to be valid, got %v", err)
+	}
+	if err := ValidateCountry(location.Country); err != nil {
+		t.Errorf("expected country to be valid, got %v", err)
+	}
+	if err := ValidateCountry("IRL"); err == nil {
+		t.Error("expected a three-letter country to be rejected")
+	}
+}
@@ -3,9 +3,19 @@ package node_registry
 import (
 	"time"
 
+	"lamda_backend/internal/auth"
+	"lamda_backend/internal/image_policy"
+
 	"gorm.io/gorm"
 )
 
+// ProviderAvailableSubject is published with a NodeLookup when a provider comes back from
+// maintenance or enters an availability window
+const ProviderAvailableSubject = "nodes.available"
+
+// ProviderLoadSubject carries the dispatcher's ProviderLoadSnapshot of every provider's jobs
+const ProviderLoadSubject = "nodes.load"
+
 // Provider represents a GPU provider in the Lamda network
 type Provider struct {
 	ID                 uint      `json:"id" gorm:"primaryKey"`
@@ -16,8 +26,45 @@ type Provider struct {
 	IsOnline           bool      `json:"is_online" gorm:"default:true"`
 	TotalJobsCompleted int       `json:"total_jobs_completed" gorm:"default:0"`
 	ReputationScore    int       `json:"reputation_score" gorm:"default:0"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	PublicKey          string    `json:"public_key,omitempty"`
+	// MaxConcurrentJobs is the number of jobs the provider runs at once, 0 if not advertised
+	MaxConcurrentJobs   int        `json:"max_concurrent_jobs" gorm:"default:0"`
+	ConcurrencyIssuedAt *time.Time `json:"-"`
+	// Load reported by the provider's last signed NATS heartbeat
+	ActiveJobs     int        `json:"active_jobs" gorm:"default:0"`
+	GPUUtilization float64    `json:"gpu_utilization" gorm:"default:0"`
+	HeartbeatAt    *time.Time `json:"heartbeat_at,omitempty"`
+	// Latest signed capability profile, version 0 if the agent has not reported one
+	Capabilities         CapabilityProfile `json:"capabilities" gorm:"embedded;embeddedPrefix:capability_"`
+	CapabilityVersion    int               `json:"capability_version" gorm:"default:0"`
+	CapabilitiesIssuedAt *time.Time        `json:"-"`
+	// Latest signed rate card, nil if the provider has not advertised prices
+	RateCard         *RateCard  `json:"rate_card,omitempty" gorm:"type:jsonb;serializer:json"`
+	RateCardIssuedAt *time.Time `json:"-"`
+	// Signed maintenance and availability schedule, and whether it currently lets the provider take jobs
+	Availability         *AvailabilitySchedule `json:"availability,omitempty" gorm:"type:jsonb;serializer:json"`
+	AvailabilityIssuedAt *time.Time            `json:"-"`
+	Unavailable          bool                  `json:"unavailable" gorm:"default:false;index"`
+	UnavailableReason    string                `json:"unavailable_reason,omitempty"`
+	AvailableAt          *time.Time            `json:"available_at,omitempty"`
+	// Declared or GeoIP-derived location; coordinates are the region's centre when declared
+	Region           string     `json:"region,omitempty" gorm:"index"`
+	Country          string     `json:"country,omitempty" gorm:"index"`
+	Latitude         *float64   `json:"latitude,omitempty"`
+	Longitude        *float64   `json:"longitude,omitempty"`
+	LocationSource   string     `json:"location_source,omitempty"`
+	LocationIssuedAt *time.Time `json:"-"`
+	// Uptime over the last 7 days, refreshed with offline checks; nil until the provider has history
+	Uptime7d *float64 `json:"uptime_7d,omitempty"`
+	// Dispatcher's live count of the provider's jobs. CurrentLoad is its assigned and running
+	// jobs; SlotLimit and FreeSlots are nil when it has no concurrency limit.
+	CurrentLoad   int        `json:"current_load" gorm:"default:0"`
+	QueuedJobs    int        `json:"queued_jobs" gorm:"default:0"`
+	SlotLimit     *int       `json:"slot_limit,omitempty"`
+	FreeSlots     *int       `json:"free_slots,omitempty" gorm:"index"`
+	LoadUpdatedAt *time.Time `json:"load_updated_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 // TableName specifies the table name for the Provider model
@@ -31,11 +78,150 @@ func (p *Provider) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
+// CapabilityProfile describes a provider machine as reported by its agent
+type CapabilityProfile struct {
+	GPUCount          int      `json:"gpu_count"`
+	ComputeCapability string   `json:"compute_capability"`
+	DriverVersion     string   `json:"driver_version"`
+	CUDAVersion       string   `json:"cuda_version"`
+	CPUCores          int      `json:"cpu_cores"`
+	RAMGB             int      `json:"ram_gb"`
+	FreeDiskGB        int      `json:"free_disk_gb"`
+	BandwidthMbps     int      `json:"bandwidth_mbps"`
+	ContainerRuntimes []string `json:"container_runtimes" gorm:"type:jsonb;serializer:json"`
+}
+
+// RateCard is a provider's advertised pricing, with amounts in wei as decimal strings.
+// Image prices are glob patterns matched like image policy repositories, and tier
+// prices are keyed by workload class (e.g. "training").
+type RateCard struct {
+	PricePerGPUHourWei string       `json:"price_per_gpu_hour_wei"`
+	MinimumChargeWei   string       `json:"minimum_charge_wei"`
+	ImagePrices        []ImagePrice `json:"image_prices,omitempty"`
+	TierPrices         []TierPrice  `json:"tier_prices,omitempty"`
+}
+
+// ImagePrice overrides the GPU-hour price for images from matching repositories
+type ImagePrice struct {
+	Repository         string `json:"repository"`
+	PricePerGPUHourWei string `json:"price_per_gpu_hour_wei"`
+}
+
+// TierPrice overrides the GPU-hour price for a workload tier
+type TierPrice struct {
+	Tier               string `json:"tier"`
+	PricePerGPUHourWei string `json:"price_per_gpu_hour_wei"`
+}
+
+// AvailabilitySchedule declares when a provider does not want jobs
+type AvailabilitySchedule struct {
+	Maintenance   []MaintenanceWindow  `json:"maintenance,omitempty"`
+	WeeklyWindows []AvailabilityWindow `json:"weekly_windows,omitempty"`
+}
+
+// MaintenanceWindow takes a provider out of service, until cleared when EndsAt is nil
+type MaintenanceWindow struct {
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+}
+
+// AvailabilityWindow is a weekly period, in UTC, during which a provider accepts jobs
+type AvailabilityWindow struct {
+	Day   time.Weekday `json:"day"`
+	Start string       `json:"start"`
+	End   string       `json:"end"`
+}
+
+// ProviderUptimeInterval is a period a provider was continuously online or offline.
+// The current interval has no end.
+type ProviderUptimeInterval struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	WalletAddress string     `json:"wallet_address" gorm:"index:idx_uptime_provider_start;not null"`
+	Online        bool       `json:"online"`
+	StartedAt     time.Time  `json:"started_at" gorm:"index:idx_uptime_provider_start;not null"`
+	EndedAt       *time.Time `json:"ended_at,omitempty" gorm:"index"`
+}
+
+// TableName specifies the table name for the ProviderUptimeInterval model
+func (ProviderUptimeInterval) TableName() string {
+	return "provider_uptime_intervals"
+}
+
+// ProviderHeartbeatBucket aggregates the gaps between a provider's heartbeats over one hour
+type ProviderHeartbeatBucket struct {
+	WalletAddress       string    `json:"wallet_address" gorm:"primaryKey"`
+	BucketStart         time.Time `json:"bucket_start" gorm:"primaryKey"`
+	Heartbeats          int64     `json:"heartbeats"`
+	GapCount            int64     `json:"gap_count"`
+	GapSumSeconds       float64   `json:"gap_sum_seconds"`
+	GapSquareSumSeconds float64   `json:"gap_square_sum_seconds"`
+	MaxGapSeconds       float64   `json:"max_gap_seconds"`
+}
+
+// TableName specifies the table name for the ProviderHeartbeatBucket model
+func (ProviderHeartbeatBucket) TableName() string {
+	return "provider_heartbeat_buckets"
+}
+
+// UptimeWindow is a provider's uptime over a rolling window. MTBFSeconds is the mean online
+// time between failures, nil when the provider did not go offline in the window.
+type UptimeWindow struct {
+	Window          string   `json:"window"`
+	UptimePercent   float64  `json:"uptime_percent"`
+	OnlineSeconds   float64  `json:"online_seconds"`
+	ObservedSeconds float64  `json:"observed_seconds"`
+	Failures        int      `json:"failures"`
+	MTBFSeconds     *float64 `json:"mtbf_seconds,omitempty"`
+}
+
+// HeartbeatRegularity summarizes the gaps between a provider's recent heartbeats
+type HeartbeatRegularity struct {
+	Heartbeats            int64   `json:"heartbeats"`
+	MeanIntervalSeconds   float64 `json:"mean_interval_seconds"`
+	StdDevIntervalSeconds float64 `json:"stddev_interval_seconds"`
+	MaxIntervalSeconds    float64 `json:"max_interval_seconds"`
+	Regularity            float64 `json:"regularity"`
+}
+
+// UptimeReport is a provider's liveness history and SLA figures
+type UptimeReport struct {
+	WalletAddress string                   `json:"wallet_address"`
+	IsOnline      bool                     `json:"is_online"`
+	Windows       []UptimeWindow           `json:"windows"`
+	Heartbeats    HeartbeatRegularity      `json:"heartbeats"`
+	Intervals     []ProviderUptimeInterval `json:"intervals"`
+	GeneratedAt   time.Time                `json:"generated_at"`
+}
+
+// UptimeResponse represents the response for a provider uptime lookup
+type UptimeResponse struct {
+	Report *UptimeReport `json:"report,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// ProviderCapabilityVersion is one version in a provider's capability profile history
+type ProviderCapabilityVersion struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	WalletAddress string            `json:"wallet_address" gorm:"uniqueIndex:idx_capability_version;not null"`
+	Version       int               `json:"version" gorm:"uniqueIndex:idx_capability_version;not null"`
+	Profile       CapabilityProfile `json:"profile" gorm:"serializer:json"`
+	IssuedAt      time.Time         `json:"issued_at" gorm:"not null"`
+	Signature     string            `json:"signature" gorm:"not null"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// TableName specifies the table name for the ProviderCapabilityVersion model
+func (ProviderCapabilityVersion) TableName() string {
+	return "provider_capability_versions"
+}
+
 // NodeRegisteredEvent represents the NodeRegistered event from the smart contract
 type NodeRegisteredEvent struct {
 	ProviderAddress string `json:"provider_address"`
 	GPUModel        string `json:"gpu_model"`
 	VRAM            int    `json:"vram"`
+	PublicKey       string `json:"public_key,omitempty"`
 	BlockNumber     uint64 `json:"block_number"`
 	TransactionHash string `json:"transaction_hash"`
 }
@@ -47,10 +233,27 @@ type NodeHeartbeatEvent struct {
 	TransactionHash string `json:"transaction_hash"`
 }
 
+// SignedHeartbeat is the payload a provider signs and publishes on nodes.heartbeat.
+// Timestamp is in unix milliseconds so heartbeats can be sent every few seconds.
+type SignedHeartbeat struct {
+	auth.Scope
+	Timestamp      int64   `json:"timestamp"`
+	Nonce          string  `json:"nonce"`
+	ActiveJobs     int     `json:"active_jobs"`
+	GPUUtilization float64 `json:"gpu_utilization"`
+}
+
+// HeartbeatResponse represents the reply to a signed heartbeat
+type HeartbeatResponse struct {
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
+
 // ActiveNodesResponse represents the response for active nodes query
 type ActiveNodesResponse struct {
 	Nodes []Provider `json:"nodes"`
 	Count int        `json:"count"`
+	Error string     `json:"error,omitempty"`
 }
 
 // NodeQuery represents a query for nodes
@@ -58,6 +261,183 @@ type NodeQuery struct {
 	MinVRAM            *int   `json:"min_vram,omitempty"`
 	GPUModel           string `json:"gpu_model,omitempty"`
 	MinReputationScore *int   `json:"min_reputation_score,omitempty"`
-	Limit              int    `json:"limit,omitempty"`
-	Offset             int    `json:"offset,omitempty"`
+	// Capability profile filters; providers without a profile never match them
+	MinGPUCount          *int   `json:"min_gpu_count,omitempty"`
+	MinComputeCapability string `json:"min_compute_capability,omitempty"`
+	MinDriverVersion     string `json:"min_driver_version,omitempty"`
+	MinCUDAVersion       string `json:"min_cuda_version,omitempty"`
+	MinCPUCores          *int   `json:"min_cpu_cores,omitempty"`
+	MinRAMGB             *int   `json:"min_ram_gb,omitempty"`
+	MinFreeDiskGB        *int   `json:"min_free_disk_gb,omitempty"`
+	MinBandwidthMbps     *int   `json:"min_bandwidth_mbps,omitempty"`
+	ContainerRuntime     string `json:"container_runtime,omitempty"`
+	// Location filters; NearRegion sorts the nearest providers first unless SortBy says otherwise
+	Regions    []string `json:"regions,omitempty"`
+	Countries  []string `json:"countries,omitempty"`
+	NearRegion string   `json:"near_region,omitempty"`
+	// ExcludeSaturated leaves out providers with no free slots
+	ExcludeSaturated bool `json:"exclude_saturated,omitempty"`
+	// MaxPricePerGPUHourWei matches providers whose base rate is at most this many wei
+	MaxPricePerGPUHourWei string `json:"max_price_per_gpu_hour_wei,omitempty"`
+	SortBy                string `json:"sort_by,omitempty"`
+	SortOrder             string `json:"sort_order,omitempty"`
+	Limit                 int    `json:"limit,omitempty"`
+	Offset                int    `json:"offset,omitempty"`
+}
+
+// NodeLookup represents a lookup of a single provider regardless of its online state
+type NodeLookup struct {
+	WalletAddress string `json:"wallet_address"`
+}
+
+// NodeLookupResponse represents the response for a node lookup
+type NodeLookupResponse struct {
+	Found bool      `json:"found"`
+	Node  *Provider `json:"node,omitempty"`
+}
+
+// ProviderImagePolicy is a provider operator's own rules for which images may run on its GPUs
+type ProviderImagePolicy struct {
+	ID            uint               `json:"id" gorm:"primaryKey"`
+	WalletAddress string             `json:"wallet_address" gorm:"uniqueIndex;not null"`
+	Rules         image_policy.Rules `json:"rules" gorm:"serializer:json"`
+	IssuedAt      time.Time          `json:"issued_at" gorm:"not null"`
+	Signature     string             `json:"signature" gorm:"not null"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// TableName specifies the table name for the ProviderImagePolicy model
+func (ProviderImagePolicy) TableName() string {
+	return "provider_image_policies"
+}
+
+// Actions signed provider payloads name in their scope
+const (
+	ActionSetImagePolicy  = "set_image_policy"
+	ActionSetConcurrency  = "set_concurrency"
+	ActionSetCapabilities = "set_capabilities"
+	ActionSetRateCard     = "set_rate_card"
+	ActionSetAvailability = "set_availability"
+	ActionSetLocation     = "set_location"
+	ActionPublishLoad     = "publish_load"
+	ActionHeartbeat       = "heartbeat"
+)
+
+// ImagePolicyUpdate is the signed payload a provider submits to replace its image policy
+type ImagePolicyUpdate struct {
+	auth.Scope
+	Rules    image_policy.Rules `json:"rules"`
+	IssuedAt int64              `json:"issued_at"`
+}
+
+// ConcurrencyUpdate is the signed payload a provider submits to advertise its concurrency limit
+type ConcurrencyUpdate struct {
+	auth.Scope
+	MaxConcurrentJobs int   `json:"max_concurrent_jobs"`
+	IssuedAt          int64 `json:"issued_at"`
+}
+
+// CapabilityUpdate is the signed payload a provider's agent submits to report its capability profile
+type CapabilityUpdate struct {
+	auth.Scope
+	Profile  CapabilityProfile `json:"profile"`
+	IssuedAt int64             `json:"issued_at"`
+}
+
+// RateCardUpdate is the signed payload a provider submits to replace its rate card
+type RateCardUpdate struct {
+	auth.Scope
+	RateCard RateCard `json:"rate_card"`
+	IssuedAt int64    `json:"issued_at"`
+}
+
+// RateCardResponse represents the response for rate card updates
+type RateCardResponse struct {
+	Node  *Provider `json:"node,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// ProviderLoad is the dispatcher's count of one provider's unfinished jobs
+type ProviderLoad struct {
+	WalletAddress string `json:"wallet_address"`
+	AssignedJobs  int    `json:"assigned_jobs"`
+	RunningJobs   int    `json:"running_jobs"`
+	QueuedJobs    int    `json:"queued_jobs"`
+}
+
+// ProviderLoadSnapshot lists every provider with unfinished jobs; providers left out have none.
+// DefaultConcurrency is the limit the dispatcher applies to providers that have not advertised
+// one, 0 meaning unlimited. It is published as the payload of a request signed with the
+// dispatcher's signing key.
+type ProviderLoadSnapshot struct {
+	auth.Scope
+	Loads              []ProviderLoad `json:"loads"`
+	DefaultConcurrency int            `json:"default_concurrency"`
+	PublishedAt        time.Time      `json:"published_at"`
+}
+
+// ProviderLocation is where a provider declares it runs. Region is one of the known
+// regions and Country an ISO 3166-1 alpha-2 code.
+type ProviderLocation struct {
+	Region  string `json:"region,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+// LocationUpdate is the signed payload a provider submits to set its location. Leaving the
+// region empty asks the registry to derive the location from the connection IP.
+type LocationUpdate struct {
+	auth.Scope
+	Location ProviderLocation `json:"location"`
+	IssuedAt int64            `json:"issued_at"`
+}
+
+// LocationRequest is a signed location update along with the IP the gateway saw it from
+type LocationRequest struct {
+	auth.SignedRequest
+	ConnectionIP string `json:"connection_ip,omitempty"`
+}
+
+// LocationResponse represents the response for location updates
+type LocationResponse struct {
+	Node  *Provider `json:"node,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// AvailabilityUpdate is the signed payload a provider submits to replace its availability
+// schedule. An empty schedule ends any maintenance and makes the provider available at all times.
+type AvailabilityUpdate struct {
+	auth.Scope
+	Schedule AvailabilitySchedule `json:"schedule"`
+	IssuedAt int64                `json:"issued_at"`
+}
+
+// AvailabilityResponse represents the response for availability schedule updates
+type AvailabilityResponse struct {
+	Node  *Provider `json:"node,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// CapabilityResponse represents the response for capability profile updates
+type CapabilityResponse struct {
+	Node  *Provider `json:"node,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// CapabilityHistoryResponse represents the response for a provider's capability profile history
+type CapabilityHistoryResponse struct {
+	Versions []ProviderCapabilityVersion `json:"versions"`
+	Error    string                      `json:"error,omitempty"`
+}
+
+// ConcurrencyResponse represents the response for concurrency limit updates
+type ConcurrencyResponse struct {
+	Node  *Provider `json:"node,omitempty"`
+	Error string    `json:"error,omitempty"`
+}
+
+// ImagePolicyResponse represents the response for image policy requests
+type ImagePolicyResponse struct {
+	Policy *ProviderImagePolicy `json:"policy,omitempty"`
+	Error  string               `json:"error,omitempty"`
 }
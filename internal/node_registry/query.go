@@ -0,0 +1,64 @@
+package node_registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Price of a provider's base rate, NULL for providers without a rate card
+const ratePriceColumn = "CAST(rate_card->>'price_per_gpu_hour_wei' AS NUMERIC)"
+
+// nodeSortColumns maps the sortable node fields to their SQL expressions
+var nodeSortColumns = map[string]string{
+	"reputation_score":       "reputation_score",
+	"price_per_gpu_hour_wei": ratePriceColumn,
+	"vram":                   "vram",
+	"last_seen":              "last_seen",
+}
+
+// nodeOrderClause builds an ORDER BY clause from a whitelisted sort field. Nodes sort by
+// reputation, highest first, by default, or nearest first when a region to measure from is
+// given; prices and distances sort lowest first unless asked otherwise.
+func nodeOrderClause(sortBy, sortOrder, nearRegion string) (string, error) {
+	if sortBy == "" {
+		sortBy = "reputation_score"
+		if nearRegion != "" {
+			sortBy = "distance"
+		}
+	}
+
+	var column string
+	direction := "DESC"
+	if sortBy == "distance" {
+		if nearRegion == "" {
+			return "", fmt.Errorf("sorting by distance requires near_region")
+		}
+		distance, err := distanceColumn(nearRegion)
+		if err != nil {
+			return "", err
+		}
+		column = distance
+		direction = "ASC"
+	} else {
+		var ok bool
+		column, ok = nodeSortColumns[sortBy]
+		if !ok {
+			return "", fmt.Errorf("cannot sort nodes by %q", sortBy)
+		}
+		if column == ratePriceColumn {
+			direction = "ASC"
+		}
+	}
+	switch strings.ToLower(sortOrder) {
+	case "":
+	case "asc":
+		direction = "ASC"
+	case "desc":
+		direction = "DESC"
+	default:
+		return "", fmt.Errorf("sort_order must be asc or desc")
+	}
+
+	// Nodes without the sorted value go last, and the ID keeps pages stable
+	return fmt.Sprintf("%s %s NULLS LAST, id %s", column, direction, direction), nil
+}
@@ -0,0 +1,36 @@
+package node_registry
+
+import (
+	"testing"
+)
+
+func TestNodeOrderClause(t *testing.T) {
+	clause, err := nodeOrderClause("", "", "")
+	if err != nil || clause != "reputation_score DESC NULLS LAST, id DESC" {
+		t.Errorf("unexpected default order %q (%v)", clause, err)
+	}
+
+	clause, err = nodeOrderClause("price_per_gpu_hour_wei", "", "")
+	if err != nil || clause != ratePriceColumn+" ASC NULLS LAST, id ASC" {
+		t.Errorf("expected prices to sort cheapest first, got %q (%v)", clause, err)
+	}
+
+	if _, err := nodeOrderClause("wallet_address; DROP TABLE providers", "", ""); err == nil {
+		t.Error("expected unknown sort field to be rejected")
+	}
+	if _, err := nodeOrderClause("vram", "sideways", ""); err == nil {
+		t.Error("expected invalid sort order to be rejected")
+	}
+
+	distance, _ := distanceColumn("eu-west")
+	clause, err = nodeOrderClause("", "", "eu-west")
+	if err != nil || clause != distance+" ASC NULLS LAST, id ASC" {
+		t.Errorf("expected a region to sort nearest first, got %q (%v)", clause, err)
+	}
+	if _, err := nodeOrderClause("distance", "", ""); err == nil {
+		t.Error("expected distance sort without a region to be rejected")
+	}
+	if _, err := nodeOrderClause("", "", "atlantis"); err == nil {
+		t.Error("expected unknown region to be rejected")
+	}
+}
@@ -0,0 +1,138 @@
+package node_registry
+
+import (
+	"fmt"
+	"math/big"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// Rate card limits
+const (
+	maxImagePrices = 32
+	maxTierPrices  = 16
+)
+
+var tierPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// ValidateRateCard checks a rate card's amounts and overrides and normalizes its wei strings
+func ValidateRateCard(card *RateCard) error {
+	price, err := parseWei("price_per_gpu_hour_wei", card.PricePerGPUHourWei)
+	if err != nil {
+		return err
+	}
+	card.PricePerGPUHourWei = price.String()
+
+	if card.MinimumChargeWei == "" {
+		card.MinimumChargeWei = "0"
+	}
+	minimum, err := parseWei("minimum_charge_wei", card.MinimumChargeWei)
+	if err != nil {
+		return err
+	}
+	card.MinimumChargeWei = minimum.String()
+
+	if len(card.ImagePrices) > maxImagePrices {
+		return fmt.Errorf("at most %d image prices are allowed", maxImagePrices)
+	}
+	for i := range card.ImagePrices {
+		override := &card.ImagePrices[i]
+		override.Repository = strings.ToLower(strings.TrimSpace(override.Repository))
+		if override.Repository == "" {
+			return fmt.Errorf("image price %d: repository is required", i)
+		}
+		if _, err := path.Match(override.Repository, ""); err != nil {
+			return fmt.Errorf("image price %d: invalid repository pattern %q", i, override.Repository)
+		}
+		price, err := parseWei("image price", override.PricePerGPUHourWei)
+		if err != nil {
+			return err
+		}
+		override.PricePerGPUHourWei = price.String()
+	}
+
+	if len(card.TierPrices) > maxTierPrices {
+		return fmt.Errorf("at most %d tier prices are allowed", maxTierPrices)
+	}
+	seen := make(map[string]bool)
+	for i := range card.TierPrices {
+		override := &card.TierPrices[i]
+		override.Tier = strings.ToLower(strings.TrimSpace(override.Tier))
+		if !tierPattern.MatchString(override.Tier) {
+			return fmt.Errorf("tier price %d: invalid tier %q", i, override.Tier)
+		}
+		if seen[override.Tier] {
+			return fmt.Errorf("tier %q is priced more than once", override.Tier)
+		}
+		seen[override.Tier] = true
+		price, err := parseWei("tier price", override.PricePerGPUHourWei)
+		if err != nil {
+			return err
+		}
+		override.PricePerGPUHourWei = price.String()
+	}
+
+	return nil
+}
+
+// HourlyPrice returns the wei per GPU-hour charged for an image and tier. The first image
+// price matching the image wins, then the tier price, then the base price.
+func (c RateCard) HourlyPrice(image, tier string) *big.Int {
+	if ref, err := reference.ParseNormalizedNamed(strings.TrimSpace(image)); err == nil {
+		names := []string{strings.ToLower(reference.FamiliarName(ref)), strings.ToLower(ref.Name())}
+		for _, override := range c.ImagePrices {
+			for _, name := range names {
+				if ok, _ := path.Match(override.Repository, name); ok {
+					return weiOrZero(override.PricePerGPUHourWei)
+				}
+			}
+		}
+	}
+
+	tier = strings.ToLower(tier)
+	for _, override := range c.TierPrices {
+		if tier != "" && override.Tier == tier {
+			return weiOrZero(override.PricePerGPUHourWei)
+		}
+	}
+
+	return weiOrZero(c.PricePerGPUHourWei)
+}
+
+// Charge returns the wei charged for running an image on the given number of GPUs
+// for a number of seconds, never less than the minimum job charge
+func (c RateCard) Charge(image, tier string, gpus int, seconds float64) *big.Int {
+	if gpus < 1 {
+		gpus = 1
+	}
+
+	hourly := new(big.Float).SetInt(c.HourlyPrice(image, tier))
+	gpuHours := big.NewFloat(float64(gpus) * seconds / 3600)
+	charge, _ := new(big.Float).Mul(hourly, gpuHours).Int(nil)
+
+	if minimum := weiOrZero(c.MinimumChargeWei); charge.Cmp(minimum) < 0 {
+		return minimum
+	}
+	return charge
+}
+
+// parseWei parses a non-negative decimal amount of wei
+func parseWei(field, amount string) (*big.Int, error) {
+	value, ok := new(big.Int).SetString(strings.TrimSpace(amount), 10)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative amount of wei", field)
+	}
+	return value, nil
+}
+
+// weiOrZero parses a validated amount of wei, treating anything unparsable as zero
+func weiOrZero(amount string) *big.Int {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+package node_registry
+
+import (
+	"testing"
+)
+
+func TestValidateRateCard(t *testing.T) {
+	card := RateCard{
+		PricePerGPUHourWei: "001000000000000000",
+		ImagePrices:        []ImagePrice{{Repository: " PyTorch/* ", PricePerGPUHourWei: "2000000000000000"}},
+		TierPrices:         []TierPrice{{Tier: "Training", PricePerGPUHourWei: "1500000000000000"}},
+	}
+	if err := ValidateRateCard(&card); err != nil {
+		t.Fatalf("expected valid rate card, got %v", err)
+	}
+	if card.PricePerGPUHourWei != "1000000000000000" || card.MinimumChargeWei != "0" {
+		t.Errorf("expected normalized amounts, got %+v", card)
+	}
+	if card.ImagePrices[0].Repository != "pytorch/*" || card.TierPrices[0].Tier != "training" {
+		t.Errorf("expected normalized overrides, got %+v", card)
+	}
+
+	invalid := map[string]RateCard{
+		"missing price":  {},
+		"negative price": {PricePerGPUHourWei: "-1"},
+		"bad minimum":    {PricePerGPUHourWei: "1", MinimumChargeWei: "1e18"},
+		"bad pattern":    {PricePerGPUHourWei: "1", ImagePrices: []ImagePrice{{Repository: "[", PricePerGPUHourWei: "1"}}},
+		"duplicate tier": {PricePerGPUHourWei: "1", TierPrices: []TierPrice{{Tier: "batch", PricePerGPUHourWei: "1"}, {Tier: "batch", PricePerGPUHourWei: "2"}}},
+	}
+	for name, card := range invalid {
+		if err := ValidateRateCard(&card); err == nil {
+			t.Errorf("%s: expected rate card to be rejected", name)
+		}
+	}
+}
+
+func TestRateCard_HourlyPrice(t *testing.T) {
+	card := RateCard{
+		PricePerGPUHourWei: "100",
+		ImagePrices:        []ImagePrice{{Repository: "pytorch/*", PricePerGPUHourWei: "300"}},
+		TierPrices:         []TierPrice{{Tier: "training", PricePerGPUHourWei: "200"}},
+	}
+
+	tests := []struct {
+		image, tier string
+		want        int64
+	}{
+		{"ubuntu:22.04", "", 100},
+		{"ubuntu:22.04", "training", 200},
+		{"pytorch/pytorch:2.1.0-cuda12.1", "training", 300},
+		{"docker.io/pytorch/pytorch", "", 300},
+	}
+	for _, tt := range tests {
+		if got := card.HourlyPrice(tt.image, tt.tier); got.Int64() != tt.want {
+			t.Errorf("HourlyPrice(%q, %q) = %s, want %d", tt.image, tt.tier, got, tt.want)
+		}
+	}
+}
+
+func TestRateCard_Charge(t *testing.T) {
+	card := RateCard{PricePerGPUHourWei: "3600", MinimumChargeWei: "500"}
+
+	if got := card.Charge("ubuntu", "", 2, 3600); got.Int64() != 7200 {
+		t.Errorf("expected two GPU-hours to cost 7200, got %s", got)
+	}
+	if got := card.Charge("ubuntu", "", 1, 60); got.Int64() != 500 {
+		t.Errorf("expected short jobs to pay the minimum charge, got %s", got)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"lamda_backend/internal/auth"
 	"lamda_backend/pkg/blockchain"
 	"lamda_backend/pkg/contracts"
 	"lamda_backend/pkg/logger"
@@ -13,7 +16,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Service handles node registry operations
@@ -24,16 +30,29 @@ type Service struct {
 	logger                 *logger.Logger
 	contractAddr           string
 	nodeReputationContract *contracts.NodeReputation
+	offlineAfter           time.Duration
+	heartbeatNonces        *heartbeatNonces
+	geoip                  *GeoIPDatabase
+	// dispatcherAddress signs the load snapshots the registry accepts
+	dispatcherAddress string
+	// loadMu serializes applying load snapshots; loadPublishedAt is the newest one applied
+	loadMu          sync.Mutex
+	loadPublishedAt time.Time
 }
 
-// NewService creates a new node registry service
-func NewService(db *gorm.DB, natsClient *nats.NATSClient, blockchain *blockchain.EVMClient, logger *logger.Logger, contractAddr string) *Service {
+// NewService creates a new node registry service. geoip may be nil, in which case providers
+// must declare their region. Load snapshots are ignored when dispatcherAddress is empty.
+func NewService(db *gorm.DB, natsClient *nats.NATSClient, blockchain *blockchain.EVMClient, logger *logger.Logger, contractAddr string, offlineAfter time.Duration, geoip *GeoIPDatabase, dispatcherAddress string) *Service {
 	return &Service{
-		db:           db,
-		natsClient:   natsClient,
-		blockchain:   blockchain,
-		logger:       logger.WithService("node-registry"),
-		contractAddr: contractAddr,
+		db:                db,
+		natsClient:        natsClient,
+		blockchain:        blockchain,
+		logger:            logger.WithService("node-registry"),
+		contractAddr:      contractAddr,
+		offlineAfter:      offlineAfter,
+		heartbeatNonces:   newHeartbeatNonces(),
+		geoip:             geoip,
+		dispatcherAddress: dispatcherAddress,
 	}
 }
 
@@ -49,6 +68,11 @@ func (s *Service) Start(ctx context.Context) error {
 	}
 	s.nodeReputationContract = nodeReputationContract
 
+	// Start uptime history for providers that predate it
+	if err := s.openUptimeIntervals(); err != nil {
+		return fmt.Errorf("failed to open uptime intervals: %w", err)
+	}
+
 	// Subscribe to NATS queries
 	if err := s.subscribeToQueries(); err != nil {
 		return fmt.Errorf("failed to subscribe to queries: %w", err)
@@ -57,6 +81,9 @@ func (s *Service) Start(ctx context.Context) error {
 	// Start blockchain event listener
 	go s.listenToBlockchainEvents(ctx)
 
+	// Keep providers' availability in step with their maintenance and weekly schedules
+	go s.runAvailabilityRefresh(ctx)
+
 	s.logger.Info("Node registry service started successfully")
 	return nil
 }
@@ -69,7 +96,77 @@ func (s *Service) subscribeToQueries() error {
 		return fmt.Errorf("failed to subscribe to nodes.query: %w", err)
 	}
 
-	s.logger.Info("Subscribed to nodes.query")
+	// Subscribe to nodes.get subject
+	_, err = s.natsClient.SubscribeWithReply("nodes.get", s.handleNodeLookup)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.get: %w", err)
+	}
+
+	// Subscribe to image policy subjects
+	_, err = s.natsClient.SubscribeWithReply("nodes.policy.get", s.handleImagePolicyGet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.policy.get: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("nodes.policy.set", s.handleImagePolicySet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.policy.set: %w", err)
+	}
+
+	// Subscribe to nodes.concurrency.set subject
+	_, err = s.natsClient.SubscribeWithReply("nodes.concurrency.set", s.handleConcurrencySet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.concurrency.set: %w", err)
+	}
+
+	// Subscribe to capability profile subjects
+	_, err = s.natsClient.SubscribeWithReply("nodes.capabilities.set", s.handleCapabilitiesSet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.capabilities.set: %w", err)
+	}
+
+	_, err = s.natsClient.SubscribeWithReply("nodes.capabilities.history", s.handleCapabilityHistory)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.capabilities.history: %w", err)
+	}
+
+	// Subscribe to nodes.location.set subject
+	_, err = s.natsClient.SubscribeWithReply("nodes.location.set", s.handleLocationSet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.location.set: %w", err)
+	}
+
+	// Subscribe to nodes.uptime subject
+	_, err = s.natsClient.SubscribeWithReply("nodes.uptime", s.handleUptime)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.uptime: %w", err)
+	}
+
+	// Subscribe to nodes.ratecard.set subject
+	_, err = s.natsClient.SubscribeWithReply("nodes.ratecard.set", s.handleRateCardSet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.ratecard.set: %w", err)
+	}
+
+	// Subscribe to nodes.availability.set subject
+	_, err = s.natsClient.SubscribeWithReply("nodes.availability.set", s.handleAvailabilitySet)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.availability.set: %w", err)
+	}
+
+	// Subscribe to signed off-chain heartbeats. Providers may publish without a reply subject.
+	_, err = s.natsClient.SubscribeWithReply("nodes.heartbeat", s.handleSignedHeartbeat)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to nodes.heartbeat: %w", err)
+	}
+
+	// Subscribe to the dispatcher's provider load snapshots
+	_, err = s.natsClient.Subscribe(ProviderLoadSubject, s.handleProviderLoad)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", ProviderLoadSubject, err)
+	}
+
+	s.logger.Info("Subscribed to nodes.query, nodes.get, nodes.policy, nodes.concurrency, nodes.capabilities, nodes.ratecard, nodes.availability, nodes.location, nodes.uptime, nodes.heartbeat and nodes.load")
 	return nil
 }
 
@@ -80,14 +177,270 @@ func (s *Service) handleNodeQuery(data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to unmarshal query: %w", err)
 	}
 
+	response := ActiveNodesResponse{}
 	nodes, err := s.GetActiveNodes(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active nodes: %w", err)
+		response.Error = err.Error()
+	}
+	response.Nodes = nodes
+	response.Count = len(nodes)
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleNodeLookup handles lookups of a single provider by wallet address
+func (s *Service) handleNodeLookup(data []byte) ([]byte, error) {
+	var lookup NodeLookup
+	if err := json.Unmarshal(data, &lookup); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lookup: %w", err)
+	}
+
+	provider, err := s.GetNodeByAddress(lookup.WalletAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	response := NodeLookupResponse{
+		Found: provider != nil,
+		Node:  provider,
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleImagePolicyGet handles lookups of a provider's image policy
+func (s *Service) handleImagePolicyGet(data []byte) ([]byte, error) {
+	var lookup NodeLookup
+	if err := json.Unmarshal(data, &lookup); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lookup: %w", err)
+	}
+
+	response := ImagePolicyResponse{}
+	policy, err := s.GetImagePolicy(lookup.WalletAddress)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Policy = policy
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleImagePolicySet handles signed image policy updates from providers
+func (s *Service) handleImagePolicySet(data []byte) ([]byte, error) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := ImagePolicyResponse{}
+	policy, err := s.SetImagePolicy(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Policy = policy
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleConcurrencySet handles signed concurrency limit updates from providers
+func (s *Service) handleConcurrencySet(data []byte) ([]byte, error) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := ConcurrencyResponse{}
+	provider, err := s.SetConcurrencyLimit(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Node = provider
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleCapabilitiesSet handles signed capability profile reports from provider agents
+func (s *Service) handleCapabilitiesSet(data []byte) ([]byte, error) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := CapabilityResponse{}
+	provider, err := s.SetCapabilities(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Node = provider
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleCapabilityHistory handles lookups of a provider's capability profile history
+func (s *Service) handleCapabilityHistory(data []byte) ([]byte, error) {
+	var lookup NodeLookup
+	if err := json.Unmarshal(data, &lookup); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lookup: %w", err)
+	}
+
+	response := CapabilityHistoryResponse{}
+	versions, err := s.GetCapabilityHistory(lookup.WalletAddress)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Versions = versions
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleProviderLoad handles the dispatcher's snapshots of every provider's unfinished jobs
+func (s *Service) handleProviderLoad(data []byte) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		s.logger.Error("Failed to unmarshal provider load snapshot", "error", err)
+		return
+	}
+
+	if err := s.ApplyLoadSnapshot(request); err != nil {
+		s.logger.Error("Failed to apply provider load snapshot", "error", err)
+	}
+}
+
+// handleLocationSet handles signed location declarations from providers
+func (s *Service) handleLocationSet(data []byte) ([]byte, error) {
+	var request LocationRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := LocationResponse{}
+	provider, err := s.SetLocation(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Node = provider
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleUptime handles lookups of a provider's uptime history and SLA figures
+func (s *Service) handleUptime(data []byte) ([]byte, error) {
+	var lookup NodeLookup
+	if err := json.Unmarshal(data, &lookup); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lookup: %w", err)
+	}
+
+	response := UptimeResponse{}
+	report, err := s.GetUptimeReport(lookup.WalletAddress)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Report = report
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleRateCardSet handles signed rate card updates from providers
+func (s *Service) handleRateCardSet(data []byte) ([]byte, error) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := RateCardResponse{}
+	provider, err := s.SetRateCard(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Node = provider
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleAvailabilitySet handles signed maintenance and availability schedules from providers
+func (s *Service) handleAvailabilitySet(data []byte) ([]byte, error) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+	}
+
+	response := AvailabilityResponse{}
+	provider, err := s.SetAvailability(request)
+	if err != nil {
+		response.Error = err.Error()
+	}
+	response.Node = provider
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	return responseData, nil
+}
+
+// handleSignedHeartbeat handles wallet-signed liveness heartbeats from providers
+func (s *Service) handleSignedHeartbeat(data []byte) ([]byte, error) {
+	var request auth.SignedRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
 	}
 
-	response := ActiveNodesResponse{
-		Nodes: nodes,
-		Count: len(nodes),
+	response := HeartbeatResponse{Accepted: true}
+	if err := s.ProcessSignedHeartbeat(request); err != nil {
+		s.logger.Debug("Rejected signed heartbeat", "error", err, "provider", request.Address)
+		response.Accepted = false
+		response.Error = err.Error()
 	}
 
 	responseData, err := json.Marshal(response)
@@ -117,8 +470,8 @@ func (s *Service) listenToBlockchainEvents(ctx context.Context) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
-	// Also poll for offline providers every 5 minutes
-	offlineTicker := time.NewTicker(5 * time.Minute)
+	// Also poll for offline providers once per offline threshold
+	offlineTicker := time.NewTicker(s.offlineAfter)
 	defer offlineTicker.Stop()
 
 	for {
@@ -140,6 +493,12 @@ func (s *Service) listenToBlockchainEvents(ctx context.Context) {
 			if err := s.MarkOfflineProviders(); err != nil {
 				s.logger.Error("Failed to mark offline providers", "error", err)
 			}
+			if err := s.PruneUptimeHistory(); err != nil {
+				s.logger.Error("Failed to prune uptime history", "error", err)
+			}
+			if err := s.RefreshUptimeScores(); err != nil {
+				s.logger.Error("Failed to refresh uptime scores", "error", err)
+			}
 		}
 	}
 }
@@ -207,6 +566,7 @@ func (s *Service) processNodeRegisteredEvent(event *contracts.NodeReputationNode
 		ProviderAddress: event.Provider.Hex(),
 		GPUModel:        event.GpuModel,
 		VRAM:            int(event.Vram.Int64()),
+		PublicKey:       s.recoverProviderPublicKey(event.Provider, event.Raw.TxHash),
 		BlockNumber:     event.Raw.BlockNumber,
 		TransactionHash: event.Raw.TxHash.Hex(),
 	}
@@ -226,10 +586,40 @@ func (s *Service) processNodeHeartbeatEvent(event *contracts.NodeReputationNodeH
 		TransactionHash: event.Raw.TxHash.Hex(),
 	}
 
+	// Backfill the public key of providers registered before keys were recorded
+	var provider Provider
+	if err := s.db.Select("public_key").Where("wallet_address = ?", event.Provider.Hex()).First(&provider).Error; err == nil && provider.PublicKey == "" {
+		if publicKey := s.recoverProviderPublicKey(event.Provider, event.Raw.TxHash); publicKey != "" {
+			if err := s.db.Model(&Provider{}).Where("wallet_address = ?", event.Provider.Hex()).Update("public_key", publicKey).Error; err != nil {
+				s.logger.Error("Failed to store provider public key", "error", err, "provider", event.Provider.Hex())
+			}
+		}
+	}
+
 	// Process the event using existing logic
 	return s.ProcessNodeHeartbeatEvent(heartbeatEvent)
 }
 
+// recoverProviderPublicKey recovers the provider's public key from a transaction it signed.
+// It returns an empty string if the transaction was not sent by the provider itself.
+func (s *Service) recoverProviderPublicKey(provider common.Address, txHash common.Hash) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	publicKey, err := s.blockchain.RecoverTransactionPublicKey(ctx, txHash)
+	if err != nil {
+		s.logger.Warn("Failed to recover provider public key", "error", err, "provider", provider.Hex(), "tx_hash", txHash.Hex())
+		return ""
+	}
+
+	if crypto.PubkeyToAddress(*publicKey) != provider {
+		s.logger.Warn("Transaction was not signed by the provider, skipping public key", "provider", provider.Hex(), "tx_hash", txHash.Hex())
+		return ""
+	}
+
+	return hexutil.Encode(crypto.FromECDSAPub(publicKey))
+}
+
 // ProcessNodeRegisteredEvent processes a NodeRegistered event
 func (s *Service) ProcessNodeRegisteredEvent(event NodeRegisteredEvent) error {
 	s.logger.Info("Processing NodeRegistered event", "provider", event.ProviderAddress)
@@ -240,6 +630,7 @@ func (s *Service) ProcessNodeRegisteredEvent(event NodeRegisteredEvent) error {
 		VRAM:          event.VRAM,
 		LastSeen:      time.Now(),
 		IsOnline:      true,
+		PublicKey:     event.PublicKey,
 	}
 
 	// Upsert the provider
@@ -251,6 +642,10 @@ func (s *Service) ProcessNodeRegisteredEvent(event NodeRegisteredEvent) error {
 		return fmt.Errorf("failed to upsert provider: %w", result.Error)
 	}
 
+	if err := s.recordTransition(s.db, event.ProviderAddress, true, provider.LastSeen); err != nil {
+		s.logger.Error("Failed to record provider uptime", "error", err, "provider", event.ProviderAddress)
+	}
+
 	s.logger.Info("Provider registered successfully", "provider", event.ProviderAddress)
 	return nil
 }
@@ -259,11 +654,21 @@ func (s *Service) ProcessNodeRegisteredEvent(event NodeRegisteredEvent) error {
 func (s *Service) ProcessNodeHeartbeatEvent(event NodeHeartbeatEvent) error {
 	s.logger.Debug("Processing NodeHeartbeat event", "provider", event.ProviderAddress)
 
+	previous, err := s.GetNodeByAddress(event.ProviderAddress)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		s.logger.Warn("Provider not found for heartbeat", "provider", event.ProviderAddress)
+		return nil
+	}
+
 	// Update the provider's last seen time
+	now := time.Now()
 	result := s.db.Model(&Provider{}).
-		Where("wallet_address = ?", event.ProviderAddress).
+		Where("wallet_address = ?", previous.WalletAddress).
 		Updates(map[string]interface{}{
-			"last_seen": time.Now(),
+			"last_seen": now,
 			"is_online": true,
 		})
 
@@ -271,62 +676,944 @@ func (s *Service) ProcessNodeHeartbeatEvent(event NodeHeartbeatEvent) error {
 		return fmt.Errorf("failed to update provider heartbeat: %w", result.Error)
 	}
 
-	if result.RowsAffected == 0 {
-		s.logger.Warn("Provider not found for heartbeat", "provider", event.ProviderAddress)
-	}
-
+	s.recordHeartbeat(previous, now)
 	return nil
 }
 
-// GetActiveNodes retrieves active nodes based on query criteria
-func (s *Service) GetActiveNodes(query NodeQuery) ([]Provider, error) {
-	var providers []Provider
-
-	db := s.db.Where("is_online = ?", true)
-
-	if query.MinVRAM != nil {
-		db = db.Where("vram >= ?", *query.MinVRAM)
+// ProcessSignedHeartbeat verifies a provider-signed heartbeat and updates its liveness and load.
+// Heartbeats must be fresh, carry an unused nonce and be newer than the last accepted one.
+func (s *Service) ProcessSignedHeartbeat(request auth.SignedRequest) error {
+	var heartbeat SignedHeartbeat
+	if err := request.Decode(&heartbeat); err != nil {
+		return err
+	}
+	if err := request.ValidateScope(heartbeat.Scope, ActionHeartbeat); err != nil {
+		return err
 	}
 
-	if query.GPUModel != "" {
-		db = db.Where("gpu_model = ?", query.GPUModel)
+	now := time.Now()
+	if err := ValidateHeartbeat(heartbeat, now); err != nil {
+		return err
 	}
 
-	if query.MinReputationScore != nil {
-		db = db.Where("reputation_score >= ?", *query.MinReputationScore)
+	walletAddress := common.HexToAddress(request.Address).Hex()
+	if !s.heartbeatNonces.Claim(walletAddress, heartbeat.Nonce, now) {
+		return fmt.Errorf("replayed heartbeat: nonce already used")
 	}
 
-	// Set default limit if not specified
-	limit := query.Limit
-	if limit <= 0 {
-		limit = 100
+	previous, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return fmt.Errorf("provider not found: %s", walletAddress)
 	}
 
-	// Order by reputation score descending
-	db = db.Order("reputation_score DESC")
+	// The timestamp condition makes the update atomic against older or concurrent heartbeats
+	sentAt := time.UnixMilli(heartbeat.Timestamp)
+	result := s.db.Model(&Provider{}).
+		Where("wallet_address = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", walletAddress, sentAt).
+		Updates(map[string]interface{}{
+			"last_seen":       now,
+			"is_online":       true,
+			"active_jobs":     heartbeat.ActiveJobs,
+			"gpu_utilization": heartbeat.GPUUtilization,
+			"heartbeat_at":    sentAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update provider heartbeat: %w", result.Error)
+	}
 
-	if err := db.Offset(query.Offset).Limit(limit).Find(&providers).Error; err != nil {
-		return nil, fmt.Errorf("failed to get active nodes: %w", err)
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("replayed heartbeat: not newer than the last accepted heartbeat")
 	}
 
-	return providers, nil
+	s.recordHeartbeat(previous, now)
+	return nil
 }
 
-// MarkOfflineProviders marks providers as offline if they haven't sent a heartbeat recently
-func (s *Service) MarkOfflineProviders() error {
-	// Mark providers as offline if they haven't been seen in the last 5 minutes
-	threshold := time.Now().Add(-5 * time.Minute)
+// recordHeartbeat adds the gap since a provider's previous heartbeat to its regularity figures
+// and starts an online interval if the provider had been offline. Liveness is already updated,
+// so failures are logged rather than returned.
+func (s *Service) recordHeartbeat(previous *Provider, now time.Time) {
+	if !previous.IsOnline {
+		if err := s.recordTransition(s.db, previous.WalletAddress, true, now); err != nil {
+			s.logger.Error("Failed to record provider uptime", "error", err, "provider", previous.WalletAddress)
+		}
+	}
 
-	result := s.db.Model(&Provider{}).
-		Where("is_online = ? AND last_seen < ?", true, threshold).
-		Update("is_online", false)
+	// Gaps spanning an offline period say nothing about how evenly the provider heartbeats
+	var gapCount int64
+	var gap float64
+	if previous.IsOnline && now.After(previous.LastSeen) {
+		gapCount = 1
+		gap = now.Sub(previous.LastSeen).Seconds()
+	}
 
-	if result.Error != nil {
-		return fmt.Errorf("failed to mark offline providers: %w", result.Error)
+	bucket := ProviderHeartbeatBucket{
+		WalletAddress:       previous.WalletAddress,
+		BucketStart:         HeartbeatBucketStart(now),
+		Heartbeats:          1,
+		GapCount:            gapCount,
+		GapSumSeconds:       gap,
+		GapSquareSumSeconds: gap * gap,
+		MaxGapSeconds:       gap,
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "wallet_address"}, {Name: "bucket_start"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"heartbeats":             gorm.Expr("provider_heartbeat_buckets.heartbeats + 1"),
+			"gap_count":              gorm.Expr("provider_heartbeat_buckets.gap_count + ?", gapCount),
+			"gap_sum_seconds":        gorm.Expr("provider_heartbeat_buckets.gap_sum_seconds + ?", gap),
+			"gap_square_sum_seconds": gorm.Expr("provider_heartbeat_buckets.gap_square_sum_seconds + ?", gap*gap),
+			"max_gap_seconds":        gorm.Expr("GREATEST(provider_heartbeat_buckets.max_gap_seconds, ?)", gap),
+		}),
+	}).Create(&bucket).Error; err != nil {
+		s.logger.Error("Failed to record provider heartbeat", "error", err, "provider", previous.WalletAddress)
 	}
+}
 
-	if result.RowsAffected > 0 {
-		s.logger.Info("Marked providers as offline", "count", result.RowsAffected)
+// recordTransition closes a provider's current uptime interval and opens one in the new state,
+// doing nothing if the provider is already in that state
+func (s *Service) recordTransition(tx *gorm.DB, walletAddress string, online bool, at time.Time) error {
+	var current ProviderUptimeInterval
+	err := tx.Where("wallet_address = ? AND ended_at IS NULL", walletAddress).Order("started_at DESC").First(&current).Error
+	switch {
+	case err == nil:
+		if current.Online == online {
+			return nil
+		}
+		if at.Before(current.StartedAt) {
+			at = current.StartedAt
+		}
+		if err := tx.Model(&current).Update("ended_at", at).Error; err != nil {
+			return fmt.Errorf("failed to close uptime interval: %w", err)
+		}
+	case err != gorm.ErrRecordNotFound:
+		return fmt.Errorf("failed to get uptime interval: %w", err)
+	}
+
+	if err := tx.Create(&ProviderUptimeInterval{WalletAddress: walletAddress, Online: online, StartedAt: at}).Error; err != nil {
+		return fmt.Errorf("failed to open uptime interval: %w", err)
+	}
+
+	return nil
+}
+
+// openUptimeIntervals starts an interval in their current state for providers without one
+func (s *Service) openUptimeIntervals() error {
+	var providers []Provider
+	if err := s.db.Where("NOT EXISTS (SELECT 1 FROM provider_uptime_intervals i WHERE i.wallet_address = providers.wallet_address AND i.ended_at IS NULL)").
+		Find(&providers).Error; err != nil {
+		return fmt.Errorf("failed to find providers without uptime history: %w", err)
+	}
+
+	now := time.Now()
+	for _, provider := range providers {
+		if err := s.recordTransition(s.db, provider.WalletAddress, provider.IsOnline, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetActiveNodes retrieves active nodes based on query criteria
+func (s *Service) GetActiveNodes(query NodeQuery) ([]Provider, error) {
+	var providers []Provider
+
+	// Providers in maintenance or outside their availability windows are not offered jobs
+	db := s.db.Where("is_online = ? AND unavailable = ?", true, false)
+
+	if query.MinVRAM != nil {
+		db = db.Where("vram >= ?", *query.MinVRAM)
+	}
+
+	if query.GPUModel != "" {
+		db = db.Where("gpu_model = ?", query.GPUModel)
+	}
+
+	if query.MinReputationScore != nil {
+		db = db.Where("reputation_score >= ?", *query.MinReputationScore)
+	}
+
+	if query.MinGPUCount != nil {
+		db = db.Where("capability_gpu_count >= ?", *query.MinGPUCount)
+	}
+
+	versionFilters := []struct {
+		column string
+		value  string
+	}{
+		{"capability_compute_capability", query.MinComputeCapability},
+		{"capability_driver_version", query.MinDriverVersion},
+		{"capability_cuda_version", query.MinCUDAVersion},
+	}
+	for _, filter := range versionFilters {
+		if filter.value == "" {
+			continue
+		}
+		if err := ValidateVersion(filter.value); err != nil {
+			return nil, err
+		}
+		db = db.Where(versionAtLeast(filter.column), filter.value)
+	}
+
+	if query.MinCPUCores != nil {
+		db = db.Where("capability_cpu_cores >= ?", *query.MinCPUCores)
+	}
+
+	if query.MinRAMGB != nil {
+		db = db.Where("capability_ram_gb >= ?", *query.MinRAMGB)
+	}
+
+	if query.MinFreeDiskGB != nil {
+		db = db.Where("capability_free_disk_gb >= ?", *query.MinFreeDiskGB)
+	}
+
+	if query.MinBandwidthMbps != nil {
+		db = db.Where("capability_bandwidth_mbps >= ?", *query.MinBandwidthMbps)
+	}
+
+	if query.ContainerRuntime != "" {
+		runtimes, err := json.Marshal([]string{strings.ToLower(query.ContainerRuntime)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal container runtime filter: %w", err)
+		}
+		db = db.Where("capability_container_runtimes @> ?", string(runtimes))
+	}
+
+	if query.MaxPricePerGPUHourWei != "" {
+		if _, err := parseWei("max_price_per_gpu_hour_wei", query.MaxPricePerGPUHourWei); err != nil {
+			return nil, err
+		}
+		db = db.Where(ratePriceColumn+" <= CAST(? AS NUMERIC)", query.MaxPricePerGPUHourWei)
+	}
+
+	if len(query.Regions) > 0 {
+		regions := make([]string, 0, len(query.Regions))
+		for _, region := range query.Regions {
+			region = strings.ToLower(strings.TrimSpace(region))
+			if err := ValidateRegion(region); err != nil {
+				return nil, err
+			}
+			regions = append(regions, region)
+		}
+		db = db.Where("region IN ?", regions)
+	}
+
+	if len(query.Countries) > 0 {
+		countries := make([]string, 0, len(query.Countries))
+		for _, country := range query.Countries {
+			country = strings.ToUpper(strings.TrimSpace(country))
+			if err := ValidateCountry(country); err != nil {
+				return nil, err
+			}
+			countries = append(countries, country)
+		}
+		db = db.Where("country IN ?", countries)
+	}
+
+	if query.ExcludeSaturated {
+		db = db.Where("free_slots IS NULL OR free_slots > 0")
+	}
+
+	orderClause, err := nodeOrderClause(query.SortBy, query.SortOrder, strings.ToLower(strings.TrimSpace(query.NearRegion)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set default limit if not specified
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	db = db.Order(orderClause)
+
+	if err := db.Offset(query.Offset).Limit(limit).Find(&providers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active nodes: %w", err)
+	}
+
+	return providers, nil
+}
+
+// GetNodeByAddress retrieves a provider by wallet address, returning nil if it is not registered
+func (s *Service) GetNodeByAddress(walletAddress string) (*Provider, error) {
+	var provider Provider
+	if err := s.db.Where("wallet_address = ?", common.HexToAddress(walletAddress).Hex()).First(&provider).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+
+	// Evaluate the schedule now rather than waiting for the next refresh
+	applyAvailability(&provider, time.Now())
+	return &provider, nil
+}
+
+// GetImagePolicy retrieves a provider's image policy, returning nil if it has none
+func (s *Service) GetImagePolicy(walletAddress string) (*ProviderImagePolicy, error) {
+	var policy ProviderImagePolicy
+	if err := s.db.Where("wallet_address = ?", common.HexToAddress(walletAddress).Hex()).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get image policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// SetImagePolicy verifies a provider-signed policy update and replaces its image policy
+func (s *Service) SetImagePolicy(request auth.SignedRequest) (*ProviderImagePolicy, error) {
+	var update ImagePolicyUpdate
+	if err := request.Decode(&update); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateScope(update.Scope, ActionSetImagePolicy); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateIssuedAt(update.IssuedAt); err != nil {
+		return nil, err
+	}
+
+	walletAddress := common.HexToAddress(request.Address).Hex()
+	provider, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider not found: %s", walletAddress)
+	}
+
+	existing, err := s.GetImagePolicy(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt := time.Unix(update.IssuedAt, 0)
+	policy := &ProviderImagePolicy{
+		WalletAddress: walletAddress,
+		Rules:         update.Rules,
+		IssuedAt:      issuedAt,
+		Signature:     request.Signature,
+	}
+
+	if existing != nil {
+		// Reject replays of older signed updates
+		if !issuedAt.After(existing.IssuedAt) {
+			return nil, fmt.Errorf("policy update is not newer than the current policy")
+		}
+		policy.ID = existing.ID
+		policy.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.db.Save(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to save image policy: %w", err)
+	}
+
+	s.logger.Info("Updated provider image policy", "provider", walletAddress)
+	return policy, nil
+}
+
+// maxConcurrencyLimit caps the concurrency a provider can advertise
+const maxConcurrencyLimit = 64
+
+// SetConcurrencyLimit verifies a provider-signed update and stores how many jobs it runs at once
+func (s *Service) SetConcurrencyLimit(request auth.SignedRequest) (*Provider, error) {
+	var update ConcurrencyUpdate
+	if err := request.Decode(&update); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateScope(update.Scope, ActionSetConcurrency); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateIssuedAt(update.IssuedAt); err != nil {
+		return nil, err
+	}
+	if update.MaxConcurrentJobs < 1 || update.MaxConcurrentJobs > maxConcurrencyLimit {
+		return nil, fmt.Errorf("max_concurrent_jobs must be between 1 and %d", maxConcurrencyLimit)
+	}
+
+	walletAddress := common.HexToAddress(request.Address).Hex()
+	provider, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider not found: %s", walletAddress)
+	}
+
+	// Reject replays of older signed updates
+	issuedAt := time.Unix(update.IssuedAt, 0)
+	if provider.ConcurrencyIssuedAt != nil && !issuedAt.After(*provider.ConcurrencyIssuedAt) {
+		return nil, fmt.Errorf("concurrency update is not newer than the current limit")
+	}
+
+	provider.MaxConcurrentJobs = update.MaxConcurrentJobs
+	provider.ConcurrencyIssuedAt = &issuedAt
+	if err := s.db.Model(provider).Select("max_concurrent_jobs", "concurrency_issued_at", "updated_at").Updates(provider).Error; err != nil {
+		return nil, fmt.Errorf("failed to update concurrency limit: %w", err)
+	}
+
+	s.logger.Info("Updated provider concurrency limit", "provider", walletAddress, "max_concurrent_jobs", update.MaxConcurrentJobs)
+	return provider, nil
+}
+
+// SetRateCard verifies a provider-signed rate card and replaces the provider's prices
+func (s *Service) SetRateCard(request auth.SignedRequest) (*Provider, error) {
+	var update RateCardUpdate
+	if err := request.Decode(&update); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateScope(update.Scope, ActionSetRateCard); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateIssuedAt(update.IssuedAt); err != nil {
+		return nil, err
+	}
+	if err := ValidateRateCard(&update.RateCard); err != nil {
+		return nil, err
+	}
+
+	walletAddress := common.HexToAddress(request.Address).Hex()
+	provider, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider not found: %s", walletAddress)
+	}
+
+	// Reject replays of older signed rate cards
+	issuedAt := time.Unix(update.IssuedAt, 0)
+	if provider.RateCardIssuedAt != nil && !issuedAt.After(*provider.RateCardIssuedAt) {
+		return nil, fmt.Errorf("rate card is not newer than the current rate card")
+	}
+
+	provider.RateCard = &update.RateCard
+	provider.RateCardIssuedAt = &issuedAt
+	if err := s.db.Model(provider).Select("rate_card", "rate_card_issued_at", "updated_at").Updates(provider).Error; err != nil {
+		return nil, fmt.Errorf("failed to update rate card: %w", err)
+	}
+
+	s.logger.Info("Updated provider rate card", "provider", walletAddress, "price_per_gpu_hour_wei", update.RateCard.PricePerGPUHourWei)
+	return provider, nil
+}
+
+// SetLocation verifies a provider-signed location and stores it. A declared region is placed
+// at the region's centre; without one the location is derived from the connection IP.
+func (s *Service) SetLocation(request LocationRequest) (*Provider, error) {
+	var update LocationUpdate
+	if err := request.Decode(&update); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateScope(update.Scope, ActionSetLocation); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateIssuedAt(update.IssuedAt); err != nil {
+		return nil, err
+	}
+
+	location := update.Location
+	NormalizeLocation(&location)
+	if location.Country != "" {
+		if err := ValidateCountry(location.Country); err != nil {
+			return nil, err
+		}
+	}
+
+	var latitude, longitude float64
+	source := LocationDeclared
+	if location.Region != "" {
+		if err := ValidateRegion(location.Region); err != nil {
+			return nil, err
+		}
+		centre := regions[location.Region]
+		latitude, longitude = centre.latitude, centre.longitude
+	} else {
+		if s.geoip == nil {
+			return nil, fmt.Errorf("region is required: GeoIP lookup is not configured")
+		}
+		if request.ConnectionIP == "" {
+			return nil, fmt.Errorf("region is required: connection IP is unknown")
+		}
+		geo, err := s.geoip.Locate(request.ConnectionIP)
+		if err != nil {
+			return nil, fmt.Errorf("region is required: %w", err)
+		}
+		// A declared country takes precedence over the one the address is registered in
+		if location.Country == "" {
+			location.Country = geo.Country
+		}
+		location.Region = NearestRegion(geo.Latitude, geo.Longitude)
+		latitude, longitude = geo.Latitude, geo.Longitude
+		source = LocationGeoIP
+	}
+
+	walletAddress := common.HexToAddress(request.Address).Hex()
+	provider, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider not found: %s", walletAddress)
+	}
+
+	// Reject replays of older signed locations
+	issuedAt := time.Unix(update.IssuedAt, 0)
+	if provider.LocationIssuedAt != nil && !issuedAt.After(*provider.LocationIssuedAt) {
+		return nil, fmt.Errorf("location is not newer than the current location")
+	}
+
+	provider.Region = location.Region
+	provider.Country = location.Country
+	provider.Latitude = &latitude
+	provider.Longitude = &longitude
+	provider.LocationSource = source
+	provider.LocationIssuedAt = &issuedAt
+	if err := s.db.Model(provider).Select("region", "country", "latitude", "longitude", "location_source", "location_issued_at", "updated_at").Updates(provider).Error; err != nil {
+		return nil, fmt.Errorf("failed to update location: %w", err)
+	}
+
+	s.logger.Info("Updated provider location", "provider", walletAddress, "region", location.Region, "country", location.Country, "source", source)
+	return provider, nil
+}
+
+// availabilityRefreshInterval is how often schedules are re-evaluated; windows are minute-granular
+const availabilityRefreshInterval = time.Minute
+
+// SetAvailability verifies a provider-signed availability schedule and replaces the provider's
+// maintenance windows and weekly availability
+func (s *Service) SetAvailability(request auth.SignedRequest) (*Provider, error) {
+	var update AvailabilityUpdate
+	if err := request.Decode(&update); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateScope(update.Scope, ActionSetAvailability); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateIssuedAt(update.IssuedAt); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := ValidateAvailabilitySchedule(&update.Schedule, now); err != nil {
+		return nil, err
+	}
+
+	walletAddress := common.HexToAddress(request.Address).Hex()
+	provider, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider not found: %s", walletAddress)
+	}
+
+	// Reject replays of older signed schedules
+	issuedAt := time.Unix(update.IssuedAt, 0)
+	if provider.AvailabilityIssuedAt != nil && !issuedAt.After(*provider.AvailabilityIssuedAt) {
+		return nil, fmt.Errorf("availability schedule is not newer than the current schedule")
+	}
+
+	wasUnavailable := provider.Unavailable
+	provider.Availability = nil
+	if len(update.Schedule.Maintenance) > 0 || len(update.Schedule.WeeklyWindows) > 0 {
+		provider.Availability = &update.Schedule
+	}
+	provider.AvailabilityIssuedAt = &issuedAt
+	applyAvailability(provider, now)
+
+	if err := s.db.Model(provider).Select(availabilityColumns).Updates(provider).Error; err != nil {
+		return nil, fmt.Errorf("failed to update availability schedule: %w", err)
+	}
+
+	if wasUnavailable && !provider.Unavailable {
+		s.announceAvailable(walletAddress)
+	}
+
+	s.logger.Info("Updated provider availability schedule", "provider", walletAddress, "unavailable", provider.Unavailable, "reason", provider.UnavailableReason)
+	return provider, nil
+}
+
+// availabilityColumns are the provider columns written when its availability changes
+var availabilityColumns = []string{
+	"availability", "availability_issued_at", "unavailable", "unavailable_reason", "available_at", "updated_at",
+}
+
+// applyAvailability sets a provider's availability fields from its schedule, reporting
+// whether they changed
+func applyAvailability(provider *Provider, now time.Time) bool {
+	status := AvailabilityStatus{Available: true}
+	if provider.Availability != nil {
+		status = provider.Availability.Status(now)
+	}
+
+	sameAvailableAt := (status.AvailableAt == nil && provider.AvailableAt == nil) ||
+		(status.AvailableAt != nil && provider.AvailableAt != nil && status.AvailableAt.Equal(*provider.AvailableAt))
+	if provider.Unavailable == !status.Available && provider.UnavailableReason == status.Reason && sameAvailableAt {
+		return false
+	}
+
+	provider.Unavailable = !status.Available
+	provider.UnavailableReason = status.Reason
+	provider.AvailableAt = status.AvailableAt
+	return true
+}
+
+// runAvailabilityRefresh re-evaluates availability schedules until the context is cancelled
+func (s *Service) runAvailabilityRefresh(ctx context.Context) {
+	ticker := time.NewTicker(availabilityRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RefreshAvailability(); err != nil {
+				s.logger.Error("Failed to refresh provider availability", "error", err)
+			}
+		}
+	}
+}
+
+// RefreshAvailability updates the availability of providers whose schedule has moved them in
+// or out of service, announcing the ones that came back
+func (s *Service) RefreshAvailability() error {
+	var providers []Provider
+	if err := s.db.Where("availability IS NOT NULL OR unavailable = ?", true).Find(&providers).Error; err != nil {
+		return fmt.Errorf("failed to load provider schedules: %w", err)
+	}
+
+	now := time.Now()
+	for i := range providers {
+		provider := &providers[i]
+		wasUnavailable := provider.Unavailable
+		if !applyAvailability(provider, now) {
+			continue
+		}
+
+		if err := s.db.Model(provider).Select("unavailable", "unavailable_reason", "available_at", "updated_at").Updates(provider).Error; err != nil {
+			return fmt.Errorf("failed to update provider availability: %w", err)
+		}
+
+		if wasUnavailable && !provider.Unavailable {
+			s.announceAvailable(provider.WalletAddress)
+		}
+		s.logger.Info("Provider availability changed", "provider", provider.WalletAddress, "unavailable", provider.Unavailable, "reason", provider.UnavailableReason)
+	}
+
+	return nil
+}
+
+// announceAvailable tells the dispatcher a provider is taking jobs again so it can release
+// any jobs it held back
+func (s *Service) announceAvailable(walletAddress string) {
+	if err := s.natsClient.Publish(ProviderAvailableSubject, NodeLookup{WalletAddress: walletAddress}); err != nil {
+		s.logger.Error("Failed to announce provider availability", "error", err, "provider", walletAddress)
+	}
+}
+
+// capabilityColumns are the provider columns written when a capability profile is stored
+var capabilityColumns = []string{
+	"capability_gpu_count", "capability_compute_capability", "capability_driver_version",
+	"capability_cuda_version", "capability_cpu_cores", "capability_ram_gb", "capability_free_disk_gb",
+	"capability_bandwidth_mbps", "capability_container_runtimes", "capability_version",
+	"capabilities_issued_at", "updated_at",
+}
+
+// SetCapabilities verifies a provider-signed capability profile and stores it. A profile
+// with different hardware or software than the current one is recorded as a new version.
+func (s *Service) SetCapabilities(request auth.SignedRequest) (*Provider, error) {
+	var update CapabilityUpdate
+	if err := request.Decode(&update); err != nil {
+		return nil, err
+	}
+	if err := request.ValidateScope(update.Scope, ActionSetCapabilities); err != nil {
+		return nil, err
+	}
+	if err := auth.ValidateIssuedAt(update.IssuedAt); err != nil {
+		return nil, err
+	}
+	if err := ValidateCapabilityProfile(&update.Profile); err != nil {
+		return nil, err
+	}
+
+	walletAddress := common.HexToAddress(request.Address).Hex()
+	provider, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("provider not found: %s", walletAddress)
+	}
+
+	// Reject replays of older signed profiles
+	issuedAt := time.Unix(update.IssuedAt, 0)
+	if provider.CapabilitiesIssuedAt != nil && !issuedAt.After(*provider.CapabilitiesIssuedAt) {
+		return nil, fmt.Errorf("capability profile is not newer than the current profile")
+	}
+
+	newVersion := provider.CapabilityVersion == 0 || !SameCapabilities(provider.Capabilities, update.Profile)
+	provider.Capabilities = update.Profile
+	provider.CapabilitiesIssuedAt = &issuedAt
+	if newVersion {
+		provider.CapabilityVersion++
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if newVersion {
+			version := &ProviderCapabilityVersion{
+				WalletAddress: walletAddress,
+				Version:       provider.CapabilityVersion,
+				Profile:       update.Profile,
+				IssuedAt:      issuedAt,
+				Signature:     request.Signature,
+			}
+			if err := tx.Create(version).Error; err != nil {
+				return fmt.Errorf("failed to record capability version: %w", err)
+			}
+		}
+
+		if err := tx.Model(provider).Select(capabilityColumns).Updates(provider).Error; err != nil {
+			return fmt.Errorf("failed to update capability profile: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Updated provider capability profile", "provider", walletAddress, "version", provider.CapabilityVersion, "new_version", newVersion)
+	return provider, nil
+}
+
+// GetCapabilityHistory retrieves every version of a provider's capability profile, newest first
+func (s *Service) GetCapabilityHistory(walletAddress string) ([]ProviderCapabilityVersion, error) {
+	var versions []ProviderCapabilityVersion
+	if err := s.db.Where("wallet_address = ?", common.HexToAddress(walletAddress).Hex()).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get capability history: %w", err)
+	}
+
+	return versions, nil
+}
+
+// MarkOfflineProviders marks providers as offline if they haven't sent a heartbeat recently
+func (s *Service) MarkOfflineProviders() error {
+	// Mark providers as offline if they haven't been seen within the offline threshold
+	threshold := time.Now().Add(-s.offlineAfter)
+
+	var providers []Provider
+	if err := s.db.Where("is_online = ? AND last_seen < ?", true, threshold).Find(&providers).Error; err != nil {
+		return fmt.Errorf("failed to find offline providers: %w", err)
+	}
+
+	marked := 0
+	for _, provider := range providers {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&Provider{}).
+				Where("id = ? AND is_online = ? AND last_seen < ?", provider.ID, true, threshold).
+				Update("is_online", false)
+			if result.Error != nil {
+				return fmt.Errorf("failed to mark provider offline: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+			marked++
+
+			// The provider went down after its last heartbeat, not when it was noticed
+			return s.recordTransition(tx, provider.WalletAddress, false, provider.LastSeen)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to mark offline providers: %w", err)
+		}
+	}
+
+	if marked > 0 {
+		s.logger.Info("Marked providers as offline", "count", marked)
+	}
+
+	return nil
+}
+
+// maxUptimeReportIntervals caps how many recent intervals an uptime report lists
+const maxUptimeReportIntervals = 100
+
+// GetUptimeReport computes a provider's rolling uptime, failures and heartbeat regularity,
+// returning nil if the provider is not registered
+func (s *Service) GetUptimeReport(walletAddress string) (*UptimeReport, error) {
+	provider, err := s.GetNodeByAddress(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	var intervals []ProviderUptimeInterval
+	if err := s.db.Where("wallet_address = ? AND (ended_at IS NULL OR ended_at > ?)", provider.WalletAddress, now.Add(-uptimeRetention)).
+		Order("started_at DESC").
+		Find(&intervals).Error; err != nil {
+		return nil, fmt.Errorf("failed to get uptime intervals: %w", err)
+	}
+
+	var buckets []ProviderHeartbeatBucket
+	if err := s.db.Where("wallet_address = ? AND bucket_start >= ?", provider.WalletAddress, HeartbeatBucketStart(now.Add(-heartbeatRegularityWindow))).
+		Find(&buckets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get heartbeat history: %w", err)
+	}
+
+	report := &UptimeReport{
+		WalletAddress: provider.WalletAddress,
+		IsOnline:      provider.IsOnline,
+		Windows:       make([]UptimeWindow, 0, len(uptimeWindows)),
+		Heartbeats:    ComputeHeartbeatRegularity(buckets),
+		Intervals:     intervals,
+		GeneratedAt:   now,
+	}
+	for _, window := range uptimeWindows {
+		report.Windows = append(report.Windows, ComputeUptime(intervals, now, window.name, window.duration))
+	}
+	if len(report.Intervals) > maxUptimeReportIntervals {
+		report.Intervals = report.Intervals[:maxUptimeReportIntervals]
+	}
+
+	return report, nil
+}
+
+// Load snapshot freshness
+const (
+	// loadSnapshotMaxAge is how old a load snapshot may be when it arrives
+	loadSnapshotMaxAge = time.Minute
+	// loadSnapshotMaxSkew tolerates the dispatcher's clock running ahead of the registry
+	loadSnapshotMaxSkew = 10 * time.Second
+)
+
+// ApplyLoadSnapshot verifies a load snapshot signed by the dispatcher, then replaces every
+// provider's job counts with it and recomputes their free slots. Snapshots older than the
+// last one applied are ignored.
+func (s *Service) ApplyLoadSnapshot(request auth.SignedRequest) error {
+	if s.dispatcherAddress == "" {
+		return fmt.Errorf("no dispatcher address configured to verify load snapshots")
+	}
+	if !common.IsHexAddress(request.Address) || common.HexToAddress(request.Address) != common.HexToAddress(s.dispatcherAddress) {
+		return fmt.Errorf("load snapshot is not signed by the dispatcher")
+	}
+
+	var snapshot ProviderLoadSnapshot
+	if err := request.Decode(&snapshot); err != nil {
+		return err
+	}
+	if err := request.ValidateScope(snapshot.Scope, ActionPublishLoad); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if snapshot.PublishedAt.After(now.Add(loadSnapshotMaxSkew)) {
+		return fmt.Errorf("load snapshot published_at is in the future")
+	}
+	if now.Sub(snapshot.PublishedAt) > loadSnapshotMaxAge {
+		return fmt.Errorf("stale load snapshot: published_at is older than %s", loadSnapshotMaxAge)
+	}
+
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	if !snapshot.PublishedAt.After(s.loadPublishedAt) {
+		return nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Providers left out of the snapshot have no unfinished jobs
+		if err := tx.Model(&Provider{}).
+			Where("current_load <> 0 OR queued_jobs <> 0").
+			UpdateColumns(map[string]interface{}{"current_load": 0, "queued_jobs": 0}).Error; err != nil {
+			return fmt.Errorf("failed to reset provider load: %w", err)
+		}
+
+		for _, load := range snapshot.Loads {
+			if err := tx.Model(&Provider{}).
+				Where("wallet_address = ?", common.HexToAddress(load.WalletAddress).Hex()).
+				UpdateColumns(map[string]interface{}{
+					"current_load": load.AssignedJobs + load.RunningJobs,
+					"queued_jobs":  load.QueuedJobs,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to update provider load: %w", err)
+			}
+		}
+
+		// Advertised limits take precedence over the dispatcher's default, as in the dispatcher
+		slotLimit := gorm.Expr("CASE WHEN max_concurrent_jobs > 0 THEN max_concurrent_jobs WHEN CAST(? AS INTEGER) > 0 THEN CAST(? AS INTEGER) ELSE NULL END",
+			snapshot.DefaultConcurrency, snapshot.DefaultConcurrency)
+		all := tx.Session(&gorm.Session{AllowGlobalUpdate: true})
+		if err := all.Model(&Provider{}).UpdateColumns(map[string]interface{}{
+			"slot_limit":      slotLimit,
+			"load_updated_at": snapshot.PublishedAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update provider slot limits: %w", err)
+		}
+		if err := all.Model(&Provider{}).UpdateColumn("free_slots", gorm.Expr("GREATEST(0, slot_limit - current_load - queued_jobs)")).Error; err != nil {
+			return fmt.Errorf("failed to update provider free slots: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.loadPublishedAt = snapshot.PublishedAt
+	return nil
+}
+
+// uptimeScoreWindow is the window of the uptime stored on each provider for matchmaking
+const uptimeScoreWindow = 7 * 24 * time.Hour
+
+// RefreshUptimeScores recomputes every provider's stored 7-day uptime from its intervals
+func (s *Service) RefreshUptimeScores() error {
+	now := time.Now()
+	var intervals []ProviderUptimeInterval
+	if err := s.db.Where("ended_at IS NULL OR ended_at > ?", now.Add(-uptimeScoreWindow)).Find(&intervals).Error; err != nil {
+		return fmt.Errorf("failed to get uptime intervals: %w", err)
+	}
+
+	byProvider := make(map[string][]ProviderUptimeInterval)
+	for _, interval := range intervals {
+		byProvider[interval.WalletAddress] = append(byProvider[interval.WalletAddress], interval)
+	}
+
+	for walletAddress, history := range byProvider {
+		window := ComputeUptime(history, now, "7d", uptimeScoreWindow)
+		if window.ObservedSeconds == 0 {
+			continue
+		}
+		if err := s.db.Model(&Provider{}).
+			Where("wallet_address = ?", walletAddress).
+			Update("uptime7d", window.UptimePercent).Error; err != nil {
+			return fmt.Errorf("failed to update uptime score: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PruneUptimeHistory deletes uptime intervals and heartbeat buckets older than the longest window
+func (s *Service) PruneUptimeHistory() error {
+	cutoff := time.Now().Add(-uptimeRetention)
+
+	if err := s.db.Where("ended_at IS NOT NULL AND ended_at < ?", cutoff).Delete(&ProviderUptimeInterval{}).Error; err != nil {
+		return fmt.Errorf("failed to prune uptime intervals: %w", err)
+	}
+	if err := s.db.Where("bucket_start < ?", cutoff).Delete(&ProviderHeartbeatBucket{}).Error; err != nil {
+		return fmt.Errorf("failed to prune heartbeat buckets: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,92 @@
+package node_registry
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"lamda_backend/internal/auth"
+)
+
+func TestApplyLoadSnapshot_RejectsUnverifiedSnapshots(t *testing.T) {
+	dispatcherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	dispatcher := crypto.PubkeyToAddress(dispatcherKey.PublicKey).Hex()
+
+	sign := func(snapshot ProviderLoadSnapshot, key *ecdsa.PrivateKey) auth.SignedRequest {
+		payload, err := json.Marshal(snapshot)
+		if err != nil {
+			t.Fatalf("failed to marshal snapshot: %v", err)
+		}
+		signature, err := auth.SignWalletMessage(payload, key)
+		if err != nil {
+			t.Fatalf("failed to sign snapshot: %v", err)
+		}
+		return auth.SignedRequest{Address: crypto.PubkeyToAddress(key.PublicKey).Hex(), Payload: string(payload), Signature: signature}
+	}
+	scope := auth.Scope{Action: ActionPublishLoad, Address: dispatcher}
+
+	s := &Service{dispatcherAddress: dispatcher}
+	rejected := map[string]auth.SignedRequest{
+		"other signer": sign(ProviderLoadSnapshot{Scope: auth.Scope{Action: ActionPublishLoad, Address: crypto.PubkeyToAddress(otherKey.PublicKey).Hex()}, PublishedAt: time.Now()}, otherKey),
+		"wrong action": sign(ProviderLoadSnapshot{Scope: auth.Scope{Action: ActionSetLocation, Address: dispatcher}, PublishedAt: time.Now()}, dispatcherKey),
+		"future":       sign(ProviderLoadSnapshot{Scope: scope, PublishedAt: time.Now().Add(time.Hour)}, dispatcherKey),
+		"stale":        sign(ProviderLoadSnapshot{Scope: scope, PublishedAt: time.Now().Add(-time.Hour)}, dispatcherKey),
+	}
+	for name, request := range rejected {
+		if err := s.ApplyLoadSnapshot(request); err == nil {
+			t.Errorf("expected %s snapshot to be rejected", name)
+		}
+	}
+
+	unconfigured := &Service{}
+	if err := unconfigured.ApplyLoadSnapshot(sign(ProviderLoadSnapshot{Scope: scope, PublishedAt: time.Now()}, dispatcherKey)); err == nil {
+		t.Error("expected snapshots to be rejected without a dispatcher address")
+	}
+}
+
+func TestProcessSignedHeartbeat_RequiresHeartbeatScope(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	provider := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	sign := func(payload interface{}) auth.SignedRequest {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("failed to marshal payload: %v", err)
+		}
+		signature, err := auth.SignWalletMessage(raw, key)
+		if err != nil {
+			t.Fatalf("failed to sign payload: %v", err)
+		}
+		return auth.SignedRequest{Address: provider, Payload: string(raw), Signature: signature}
+	}
+
+	// A provider's signed job status report carries the same timestamp and nonce fields
+	statusReport := map[string]interface{}{
+		"job_id":    "0xabc",
+		"attempt":   1,
+		"status":    "running",
+		"timestamp": time.Now().UnixMilli(),
+		"nonce":     "a1b2c3d4e5",
+	}
+	wrongAction := SignedHeartbeat{Scope: auth.Scope{Action: ActionSetLocation, Address: provider}, Timestamp: time.Now().UnixMilli(), Nonce: "a1b2c3d4e5"}
+
+	s := &Service{}
+	for name, request := range map[string]auth.SignedRequest{"status report": sign(statusReport), "wrong action": sign(wrongAction)} {
+		if err := s.ProcessSignedHeartbeat(request); err == nil {
+			t.Errorf("expected %s to be rejected as a heartbeat", name)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package node_registry
+
+import (
+	"math"
+	"time"
+)
+
+// Rolling windows uptime is reported over
+var uptimeWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+const (
+	// uptimeRetention is how long intervals and heartbeat buckets are kept
+	uptimeRetention = 30 * 24 * time.Hour
+	// heartbeatRegularityWindow is how far back heartbeat gaps are summarized
+	heartbeatRegularityWindow = 24 * time.Hour
+	// heartbeatBucketSize is the period heartbeat gaps are aggregated into
+	heartbeatBucketSize = time.Hour
+)
+
+// ComputeUptime summarizes a provider's liveness intervals over the window ending at now.
+// Only time covered by intervals counts, so recently registered providers are not
+// penalized for the period before they joined.
+func ComputeUptime(intervals []ProviderUptimeInterval, now time.Time, name string, window time.Duration) UptimeWindow {
+	from := now.Add(-window)
+	result := UptimeWindow{Window: name}
+
+	var online, observed time.Duration
+	for _, interval := range intervals {
+		start := interval.StartedAt
+		end := now
+		if interval.EndedAt != nil {
+			end = *interval.EndedAt
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(now) {
+			end = now
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		observed += end.Sub(start)
+		if interval.Online {
+			online += end.Sub(start)
+		} else if !interval.StartedAt.Before(from) {
+			// Each offline interval that began inside the window is one failure
+			result.Failures++
+		}
+	}
+
+	result.OnlineSeconds = online.Seconds()
+	result.ObservedSeconds = observed.Seconds()
+	if observed > 0 {
+		result.UptimePercent = 100 * online.Seconds() / observed.Seconds()
+	}
+	if result.Failures > 0 {
+		mtbf := online.Seconds() / float64(result.Failures)
+		result.MTBFSeconds = &mtbf
+	}
+
+	return result
+}
+
+// ComputeHeartbeatRegularity summarizes the gaps between a provider's heartbeats. Regularity
+// is 1 / (1 + coefficient of variation), so perfectly even heartbeats score 1.
+func ComputeHeartbeatRegularity(buckets []ProviderHeartbeatBucket) HeartbeatRegularity {
+	result := HeartbeatRegularity{}
+
+	var gaps int64
+	var sum, sumSquares float64
+	for _, bucket := range buckets {
+		result.Heartbeats += bucket.Heartbeats
+		gaps += bucket.GapCount
+		sum += bucket.GapSumSeconds
+		sumSquares += bucket.GapSquareSumSeconds
+		result.MaxIntervalSeconds = math.Max(result.MaxIntervalSeconds, bucket.MaxGapSeconds)
+	}
+
+	if gaps == 0 {
+		return result
+	}
+
+	mean := sum / float64(gaps)
+	variance := math.Max(0, sumSquares/float64(gaps)-mean*mean)
+	result.MeanIntervalSeconds = mean
+	result.StdDevIntervalSeconds = math.Sqrt(variance)
+	if mean > 0 {
+		result.Regularity = 1 / (1 + result.StdDevIntervalSeconds/mean)
+	}
+
+	return result
+}
+
+// HeartbeatBucketStart returns the start of the bucket a heartbeat at t is aggregated into
+func HeartbeatBucketStart(t time.Time) time.Time {
+	return t.UTC().Truncate(heartbeatBucketSize)
+}
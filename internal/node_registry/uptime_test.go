@@ -0,0 +1,76 @@
+package node_registry
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeUptime(t *testing.T) {
+	now := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	at := func(hoursAgo float64) *time.Time {
+		t := now.Add(-time.Duration(hoursAgo * float64(time.Hour)))
+		return &t
+	}
+
+	// Registered 30 hours ago; down for two hours starting 20 hours ago and one hour starting 5 hours ago
+	intervals := []ProviderUptimeInterval{
+		{Online: true, StartedAt: *at(30), EndedAt: at(20)},
+		{Online: false, StartedAt: *at(20), EndedAt: at(18)},
+		{Online: true, StartedAt: *at(18), EndedAt: at(5)},
+		{Online: false, StartedAt: *at(5), EndedAt: at(4)},
+		{Online: true, StartedAt: *at(4)},
+	}
+
+	day := ComputeUptime(intervals, now, "24h", 24*time.Hour)
+	if day.ObservedSeconds != 24*3600 || day.OnlineSeconds != 21*3600 {
+		t.Fatalf("expected 21h online of 24h observed, got %+v", day)
+	}
+	if day.Failures != 2 || day.MTBFSeconds == nil || *day.MTBFSeconds != 10.5*3600 {
+		t.Errorf("expected 2 failures and 10.5h MTBF, got %+v", day)
+	}
+	if math.Abs(day.UptimePercent-87.5) > 1e-9 {
+		t.Errorf("expected 87.5%% uptime, got %v", day.UptimePercent)
+	}
+
+	// The week only counts the 30 hours since registration
+	week := ComputeUptime(intervals, now, "7d", 7*24*time.Hour)
+	if week.ObservedSeconds != 30*3600 || week.OnlineSeconds != 27*3600 || week.Failures != 2 {
+		t.Errorf("expected 27h online of 30h observed with 2 failures, got %+v", week)
+	}
+
+	// An outage that began before the window is not a failure inside it
+	recent := ComputeUptime(intervals, now, "19h", 19*time.Hour)
+	if recent.Failures != 1 || recent.OnlineSeconds != 17*3600 {
+		t.Errorf("expected 1 failure and 17h online, got %+v", recent)
+	}
+
+	if empty := ComputeUptime(nil, now, "24h", 24*time.Hour); empty.UptimePercent != 0 || empty.MTBFSeconds != nil {
+		t.Errorf("expected no figures without history, got %+v", empty)
+	}
+}
+
+func TestComputeHeartbeatRegularity(t *testing.T) {
+	even := ComputeHeartbeatRegularity([]ProviderHeartbeatBucket{
+		{Heartbeats: 60, GapCount: 60, GapSumSeconds: 3600, GapSquareSumSeconds: 60 * 60 * 60, MaxGapSeconds: 60},
+	})
+	if even.Heartbeats != 60 || even.MeanIntervalSeconds != 60 || even.StdDevIntervalSeconds != 0 || even.Regularity != 1 {
+		t.Errorf("expected perfectly regular heartbeats, got %+v", even)
+	}
+
+	// Gaps of 30s and 90s: mean 60s, standard deviation 30s
+	uneven := ComputeHeartbeatRegularity([]ProviderHeartbeatBucket{
+		{Heartbeats: 2, GapCount: 1, GapSumSeconds: 30, GapSquareSumSeconds: 900, MaxGapSeconds: 30},
+		{Heartbeats: 1, GapCount: 1, GapSumSeconds: 90, GapSquareSumSeconds: 8100, MaxGapSeconds: 90},
+	})
+	if uneven.MeanIntervalSeconds != 60 || uneven.StdDevIntervalSeconds != 30 || uneven.MaxIntervalSeconds != 90 {
+		t.Fatalf("expected mean 60s, stddev 30s and max 90s, got %+v", uneven)
+	}
+	if math.Abs(uneven.Regularity-2.0/3) > 1e-9 {
+		t.Errorf("expected regularity 2/3, got %v", uneven.Regularity)
+	}
+
+	if none := ComputeHeartbeatRegularity(nil); none.Regularity != 0 || none.Heartbeats != 0 {
+		t.Errorf("expected empty figures without heartbeats, got %+v", none)
+	}
+}
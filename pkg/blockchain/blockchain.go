@@ -58,6 +58,59 @@ func (e *EVMClient) GetTransactionReceipt(ctx context.Context, txHash common.Has
 	return e.client.TransactionReceipt(ctx, txHash)
 }
 
+// RecoverTransactionPublicKey recovers the public key that signed a transaction
+func (e *EVMClient) RecoverTransactionPublicKey(ctx context.Context, txHash common.Hash) (*ecdsa.PublicKey, error) {
+	tx, _, err := e.client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	chainID, err := e.GetChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	return RecoverSenderPublicKey(tx, chainID)
+}
+
+// RecoverSenderPublicKey recovers the secp256k1 public key of a signed transaction's sender
+func RecoverSenderPublicKey(tx *types.Transaction, chainID *big.Int) (*ecdsa.PublicKey, error) {
+	v, r, s := tx.RawSignatureValues()
+	if v == nil || r == nil || s == nil {
+		return nil, fmt.Errorf("transaction is not signed")
+	}
+
+	var signer types.Signer = types.LatestSignerForChainID(chainID)
+	recoveryID := new(big.Int).Set(v)
+
+	// Legacy transactions encode the recovery ID in V, offset by 27 or by the
+	// EIP-155 chain ID; typed transactions carry it directly
+	if tx.Type() == types.LegacyTxType {
+		if tx.Protected() {
+			recoveryID.Sub(recoveryID, new(big.Int).Add(new(big.Int).Mul(chainID, big.NewInt(2)), big.NewInt(35)))
+		} else {
+			signer = types.HomesteadSigner{}
+			recoveryID.Sub(recoveryID, big.NewInt(27))
+		}
+	}
+
+	if !recoveryID.IsUint64() || recoveryID.Uint64() > 1 {
+		return nil, fmt.Errorf("invalid signature recovery ID")
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	r.FillBytes(sig[0:32])
+	s.FillBytes(sig[32:64])
+	sig[crypto.RecoveryIDOffset] = byte(recoveryID.Uint64())
+
+	pubKey, err := crypto.SigToPub(signer.Hash(tx).Bytes(), sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
 // GetBalance returns the balance of an account
 func (e *EVMClient) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
 	return e.client.BalanceAt(ctx, address, nil)
@@ -0,0 +1,59 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRecoverSenderPublicKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	chainID := big.NewInt(5611) // opBNB testnet
+	to := common.HexToAddress("0x108f2c400C9828d8044a5F6985f0C9589B90758D")
+
+	tests := []struct {
+		name   string
+		tx     *types.Transaction
+		signer types.Signer
+	}{
+		{
+			name:   "eip155 legacy",
+			tx:     types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Gas: 21000, GasPrice: big.NewInt(1)}),
+			signer: types.NewEIP155Signer(chainID),
+		},
+		{
+			name:   "unprotected legacy",
+			tx:     types.NewTx(&types.LegacyTx{Nonce: 2, To: &to, Gas: 21000, GasPrice: big.NewInt(1)}),
+			signer: types.HomesteadSigner{},
+		},
+		{
+			name:   "dynamic fee",
+			tx:     types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 3, To: &to, Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2)}),
+			signer: types.LatestSignerForChainID(chainID),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := types.SignTx(tt.tx, tt.signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+
+			pubKey, err := RecoverSenderPublicKey(signed, chainID)
+			if err != nil {
+				t.Fatalf("failed to recover public key: %v", err)
+			}
+
+			if crypto.PubkeyToAddress(*pubKey) != crypto.PubkeyToAddress(key.PublicKey) {
+				t.Error("recovered public key does not belong to the signer")
+			}
+		})
+	}
+}
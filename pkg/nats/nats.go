@@ -56,11 +56,21 @@ func (n *NATSClient) Publish(subject string, data interface{}) error {
 	return n.conn.Publish(subject, payload)
 }
 
+// Flush waits until the server has processed every message published so far
+func (n *NATSClient) Flush(timeout time.Duration) error {
+	return n.conn.FlushTimeout(timeout)
+}
+
 // PublishWithReply publishes a message and waits for a reply
 func (n *NATSClient) PublishWithReply(subject string, data interface{}, timeout time.Duration) ([]byte, error) {
-	payload, err := json.Marshal(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	// Pre-encoded payloads are sent as-is rather than re-marshaled into a base64 string
+	payload, ok := data.([]byte)
+	if !ok {
+		var err error
+		payload, err = json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
 	}
 
 	msg, err := n.conn.Request(subject, payload, timeout)
@@ -125,6 +135,38 @@ func (n *NATSClient) CreateStream(name string, subjects []string) error {
 	return err
 }
 
+// CreateBoundedStream creates or updates a JetStream stream that keeps at most
+// maxMsgsPerSubject messages per subject, each for no longer than maxAge
+func (n *NATSClient) CreateBoundedStream(name string, subjects []string, maxMsgsPerSubject int64, maxAge time.Duration) error {
+	config := &nats.StreamConfig{
+		Name:              name,
+		Subjects:          subjects,
+		MaxMsgsPerSubject: maxMsgsPerSubject,
+		MaxAge:            maxAge,
+		Discard:           nats.DiscardOld,
+	}
+
+	if _, err := n.js.StreamInfo(name); err == nil {
+		_, err = n.js.UpdateStream(config)
+		return err
+	}
+
+	_, err := n.js.AddStream(config)
+	return err
+}
+
+// ReplayJetStream delivers every retained message on a subject followed by new ones.
+// The handler also receives the number of retained messages still pending delivery.
+func (n *NATSClient) ReplayJetStream(subject string, handler func(data []byte, pending uint64)) (*nats.Subscription, error) {
+	return n.js.Subscribe(subject, func(msg *nats.Msg) {
+		var pending uint64
+		if meta, err := msg.Metadata(); err == nil {
+			pending = meta.NumPending
+		}
+		handler(msg.Data, pending)
+	}, nats.OrderedConsumer(), nats.DeliverAll())
+}
+
 // Close closes the NATS connection
 func (n *NATSClient) Close() {
 	if n.conn != nil {